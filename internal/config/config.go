@@ -3,24 +3,277 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Port        int         `yaml:"port"`
-	LogLevel    string      `yaml:"logLevel"`
-	Persistence Persistence `yaml:"persistence"`
-	YtDlp       YtDlp       `yaml:"ytDlp"`
+	Port          int           `yaml:"port"`
+	LogLevel      string        `yaml:"logLevel"`
+	Persistence   Persistence   `yaml:"persistence"`
+	YtDlp         YtDlp         `yaml:"ytDlp"`
+	Subscriptions Subscriptions `yaml:"subscriptions"`
+	Webhooks      Webhooks      `yaml:"webhooks"`
+	Filters       Filters       `yaml:"filters"`
+	Downloaders   Downloaders   `yaml:"downloaders"`
+}
+
+// Downloaders configures which downloaders handle submitted URLs. Downloaders are named "twitch", "youtube",
+// "podcastfeed", "direct" or after a yt-dlp site. A URL is handled by the first downloader in order that supports it.
+type Downloaders struct {
+	// Order lists downloaders by precedence. Downloaders that are not listed follow in their default order:
+	// the built-in downloaders in the order above, then the yt-dlp sites in configuration order.
+	Order []string `yaml:"order"`
+	// Disabled downloaders are never used, e.g. [twitch]
+	Disabled []string `yaml:"disabled"`
+}
+
+// BuiltInDownloaders are the names of the built-in downloaders in their default order. yt-dlp sites cannot use them.
+var BuiltInDownloaders = []string{"twitch", "youtube", "podcastfeed", "direct"}
+
+// Validate returns an error if a downloader is named twice, not at all, or matches neither a built-in downloader
+// nor one of the given yt-dlp sites.
+func (d Downloaders) Validate(sites []Site) error {
+	known := slices.Clone(BuiltInDownloaders)
+	for _, site := range sites {
+		known = append(known, site.Name)
+	}
+	for _, names := range []struct {
+		field string
+		names []string
+	}{{"order", d.Order}, {"disabled", d.Disabled}} {
+		seen := make(map[string]bool, len(names.names))
+		for _, name := range names.names {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("%s contains an empty downloader name", names.field)
+			}
+			if seen[name] {
+				return fmt.Errorf("%s contains downloader %s twice", names.field, name)
+			}
+			if !slices.Contains(known, name) {
+				return fmt.Errorf("%s contains unknown downloader %s, expected one of %v", names.field, name, known)
+			}
+			seen[name] = true
+		}
+	}
+	return nil
 }
 
 // YtDlp holds yt-dlp specific configuration
 type YtDlp struct {
 	Verbose bool `yaml:"verbose"`
+	// Sites are additional websites downloaded with the generic yt-dlp downloader, e.g. Vimeo or SoundCloud
+	Sites []Site `yaml:"sites"`
+	// SponsorBlock configures the handling of segments reported by SponsorBlock in YouTube downloads
+	SponsorBlock SponsorBlock `yaml:"sponsorBlock"`
+}
+
+// SponsorBlock modes
+const (
+	SponsorBlockRemove   = "remove"   // cut the segments out of the audio
+	SponsorBlockMark     = "mark"     // keep the audio and add the segments as chapters
+	SponsorBlockDisabled = "disabled" // do not ask SponsorBlock at all
+)
+
+// sponsorBlockCategories are the segment categories known to SponsorBlock, see https://wiki.sponsor.ajay.app/w/Types
+var sponsorBlockCategories = []string{
+	"sponsor", "selfpromo", "interaction", "intro", "outro", "preview", "music_offtopic", "filler", "hook",
+	"poi_highlight", "chapter", "exclusive_access", "all",
+}
+
+// DefaultSponsorBlockCategories are handled if no categories are configured.
+var DefaultSponsorBlockCategories = []string{
+	"sponsor", "selfpromo", "interaction", "intro", "outro", "preview", "music_offtopic", "filler", "hook",
+}
+
+// SponsorBlock holds the SponsorBlock settings of all feeds and the settings of single feeds
+type SponsorBlock struct {
+	Mode       string   `yaml:"mode"`       // remove, mark or disabled
+	Categories []string `yaml:"categories"` // segment categories, e.g. [sponsor, selfpromo]
+	// Feeds overrides the settings for feeds by name, i.e. the YouTube channel. Unset fields are taken from above.
+	Feeds map[string]SponsorBlockFeed `yaml:"feeds"`
+}
+
+// SponsorBlockFeed holds the SponsorBlock settings of a feed
+type SponsorBlockFeed struct {
+	Mode       string   `yaml:"mode"`
+	Categories []string `yaml:"categories"`
+}
+
+// ForFeed returns the settings of a feed. Feed names are compared case-insensitively.
+func (s SponsorBlock) ForFeed(feed string) SponsorBlockFeed {
+	settings := SponsorBlockFeed{Mode: s.Mode, Categories: s.Categories}
+	for name, override := range s.Feeds {
+		if !strings.EqualFold(name, feed) {
+			continue
+		}
+		if override.Mode != "" {
+			settings.Mode = override.Mode
+		}
+		if len(override.Categories) > 0 {
+			settings.Categories = override.Categories
+		}
+		break
+	}
+	return settings
+}
+
+// Validate returns an error if a mode or category is unknown.
+func (s SponsorBlock) Validate() error {
+	if err := (SponsorBlockFeed{Mode: s.Mode, Categories: s.Categories}).validate(); err != nil {
+		return err
+	}
+	for name, feed := range s.Feeds {
+		if err := feed.validate(); err != nil {
+			return fmt.Errorf("feed %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (f SponsorBlockFeed) validate() error {
+	switch f.Mode {
+	case "", SponsorBlockRemove, SponsorBlockMark, SponsorBlockDisabled:
+	default:
+		return fmt.Errorf("unknown mode %q, expected %s, %s or %s", f.Mode, SponsorBlockRemove, SponsorBlockMark, SponsorBlockDisabled)
+	}
+	for _, category := range f.Categories {
+		if !slices.Contains(sponsorBlockCategories, category) {
+			return fmt.Errorf("unknown category %q", category)
+		}
+	}
+	return nil
+}
+
+// Site is a website handled by the generic yt-dlp downloader
+type Site struct {
+	Name string `yaml:"name"`
+	// Hosts the site is served from. A host also matches its subdomains, e.g. "vimeo.com" matches "player.vimeo.com".
+	Hosts []string `yaml:"hosts"`
+	// URLPatterns are regular expressions matching URLs of the site, for sites that share their host with other content
+	URLPatterns []string `yaml:"urlPatterns"`
+	// Args are additional yt-dlp arguments for every call for this site, e.g. ["--referer", "https://example.com"]
+	Args []string `yaml:"args"`
+	// OutputTemplate is the yt-dlp output template of the audio file. Its first directory is the feed the file is added to.
+	OutputTemplate string `yaml:"outputTemplate"`
+	// Metadata maps audio tags to yt-dlp templates, e.g. artist: "%(uploader)s", to fill tags the site's extractor leaves empty
+	Metadata map[string]string `yaml:"metadata"`
+}
+
+// DefaultSiteOutputTemplate is used for sites without an output template, it groups audio files into a feed per uploader.
+const DefaultSiteOutputTemplate = "%(uploader)s/%(title)s_%(id)s.%(ext)s"
+
+// Validate returns an error if a site cannot be matched against URLs or its name is not unique,
+// including the names of the built-in downloaders.
+func (y YtDlp) Validate() error {
+	names := make(map[string]bool, len(y.Sites))
+	for i, site := range y.Sites {
+		if strings.TrimSpace(site.Name) == "" {
+			return fmt.Errorf("site %d has no name", i)
+		}
+		if names[site.Name] {
+			return fmt.Errorf("site name %s is used twice", site.Name)
+		}
+		if slices.Contains(BuiltInDownloaders, site.Name) {
+			return fmt.Errorf("site name %s is reserved for a built-in downloader", site.Name)
+		}
+		names[site.Name] = true
+		if len(site.Hosts) == 0 && len(site.URLPatterns) == 0 {
+			return fmt.Errorf("site %s has neither hosts nor url patterns", site.Name)
+		}
+		for _, host := range site.Hosts {
+			if strings.TrimSpace(host) == "" || strings.ContainsAny(host, "/:*") {
+				return fmt.Errorf("site %s has an invalid host %q, expected e.g. vimeo.com", site.Name, host)
+			}
+		}
+		for _, pattern := range site.URLPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("site %s has an invalid url pattern %q: %w", site.Name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Subscriptions holds the configuration of channel and playlist subscriptions
+type Subscriptions struct {
+	PollInterval time.Duration `yaml:"pollInterval"` // how often each subscription is checked for new videos, e.g. "1h"
+}
+
+// Webhooks holds the endpoints notified about download lifecycle events
+type Webhooks struct {
+	// BaseURL is the public URL of the service, used for feed and audio links in payloads. Links are omitted if empty.
+	BaseURL   string            `yaml:"baseURL"`
+	Timeout   time.Duration     `yaml:"timeout"` // timeout of a single delivery attempt, e.g. "10s"
+	Endpoints []WebhookEndpoint `yaml:"endpoints"`
+}
+
+// Filters select which videos of a listed URL are downloaded. Zero values disable the respective filter.
+type Filters struct {
+	MinDuration   time.Duration `yaml:"minDuration"`   // skip videos shorter than this, e.g. "5m"
+	MaxDuration   time.Duration `yaml:"maxDuration"`   // skip videos longer than this, e.g. "3h"
+	IncludeTitle  string        `yaml:"includeTitle"`  // regular expression the title has to match
+	ExcludeTitle  string        `yaml:"excludeTitle"`  // regular expression the title must not match
+	ExcludeShorts bool          `yaml:"excludeShorts"` // skip YouTube Shorts
+}
+
+// IsZero reports whether no filter is set.
+func (f Filters) IsZero() bool {
+	return f == Filters{}
+}
+
+// Validate returns an error if a filter cannot be applied.
+func (f Filters) Validate() error {
+	if f.MinDuration < 0 || f.MaxDuration < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if f.MaxDuration > 0 && f.MaxDuration < f.MinDuration {
+		return fmt.Errorf("maxDuration %s is shorter than minDuration %s", f.MaxDuration, f.MinDuration)
+	}
+	for _, pattern := range []string{f.IncludeTitle, f.ExcludeTitle} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid title pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// WebhookEndpoint is a URL that receives event payloads signed with HMAC-SHA256 using Secret
+type WebhookEndpoint struct {
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Events []string `yaml:"events"` // event types sent to the endpoint, all events if empty
+}
+
+// WebhookEvents are the event types webhook endpoints can subscribe to.
+var WebhookEvents = []string{"download.accepted", "download.completed", "download.unavailable", "download.failed"}
+
+// Validate returns an error if an endpoint has no http or https URL or no secret to sign payloads with,
+// or subscribes to an unknown event.
+func (w Webhooks) Validate() error {
+	for i, endpoint := range w.Endpoints {
+		parsed, err := url.Parse(endpoint.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("endpoint %d has an invalid url %q, expected e.g. https://example.com/hook", i, endpoint.URL)
+		}
+		if endpoint.Secret == "" {
+			return fmt.Errorf("endpoint %s has no secret", endpoint.URL)
+		}
+		for _, event := range endpoint.Events {
+			if !slices.Contains(WebhookEvents, event) {
+				return fmt.Errorf("endpoint %s subscribes to unknown event %q, expected one of %v", endpoint.URL, event, WebhookEvents)
+			}
+		}
+	}
+	return nil
 }
 
 // Persistence holds all persistence-related configuration
@@ -44,12 +297,95 @@ type Cookies struct {
 
 // Media holds media storage configuration
 type Media struct {
-	MediaPath             string `yaml:"mediaPath"`
-	TempPath              string `yaml:"tempPath"`
-	MaxParallelDownloads  int    `yaml:"maxParallelDownloads"`
-	AllowPartialDownloads bool   `yaml:"allowPartialDownloads"`
+	MediaPath            string `yaml:"mediaPath"`
+	TempPath             string `yaml:"tempPath"`
+	MaxParallelDownloads int    `yaml:"maxParallelDownloads"` // service-wide limit shared by all download requests
+	// MaxParallelAvailabilityChecks limits the yt-dlp availability checks running at the same time across all requests.
+	// Defaults to MaxParallelDownloads.
+	MaxParallelAvailabilityChecks int   `yaml:"maxParallelAvailabilityChecks"`
+	AllowPartialDownloads         bool  `yaml:"allowPartialDownloads"`
+	Retry                         Retry `yaml:"retry"`
+	// LivePollInterval is how often live streams and upcoming premieres are checked until their recording can be downloaded, e.g. "5m".
+	LivePollInterval time.Duration `yaml:"livePollInterval"`
+	// LiveMaxWait is how long a live stream or upcoming premiere is waited for before its job fails, e.g. "168h".
+	LiveMaxWait time.Duration `yaml:"liveMaxWait"`
+	// DirectMediaFeed is the feed audio from direct media file links is added to. Defaults to the host of the link.
+	DirectMediaFeed string `yaml:"directMediaFeed"`
+	// FeedMirror configures the import of episodes from external podcast feeds
+	FeedMirror FeedMirror `yaml:"feedMirror"`
+	// Audio configures the format audio files are stored in
+	Audio Audio `yaml:"audio"`
 }
 
+// Audio formats
+const (
+	AudioFormatMP3  = "mp3"
+	AudioFormatM4A  = "m4a" // AAC in an MPEG-4 container
+	AudioFormatOpus = "opus"
+)
+
+// opusSampleRates are the sample rates supported by the Opus encoder.
+var opusSampleRates = []int{8000, 12000, 16000, 24000, 48000}
+
+// bitratePattern matches bitrates in kbit/s as understood by ffmpeg and yt-dlp, e.g. "48k".
+var bitratePattern = regexp.MustCompile(`^[1-9][0-9]*[kK]$`)
+
+// Audio holds the format audio files are converted to. Audio that is already in the format is kept as it is.
+type Audio struct {
+	Format     string `yaml:"format"`     // mp3, m4a or opus
+	Bitrate    string `yaml:"bitrate"`    // target bitrate, e.g. "48k", the encoder default if empty
+	SampleRate int    `yaml:"sampleRate"` // target sample rate in Hz, e.g. 48000, the one of the source if 0
+}
+
+// Validate returns an error if the format is unknown or cannot be encoded with the bitrate or sample rate.
+func (a Audio) Validate() error {
+	switch a.Format {
+	case "", AudioFormatMP3, AudioFormatM4A, AudioFormatOpus:
+	default:
+		return fmt.Errorf("unknown format %q, expected %s, %s or %s", a.Format, AudioFormatMP3, AudioFormatM4A, AudioFormatOpus)
+	}
+	if a.Bitrate != "" && !bitratePattern.MatchString(a.Bitrate) {
+		return fmt.Errorf("invalid bitrate %q, expected kbit/s such as 48k", a.Bitrate)
+	}
+	if a.SampleRate < 0 {
+		return fmt.Errorf("sample rate must not be negative")
+	}
+	if a.Format == AudioFormatOpus && a.SampleRate > 0 && !slices.Contains(opusSampleRates, a.SampleRate) {
+		return fmt.Errorf("sample rate %d is not supported by opus, expected one of %v", a.SampleRate, opusSampleRates)
+	}
+	return nil
+}
+
+// FeedMirror holds the configuration of mirrored RSS and Atom podcast feeds
+type FeedMirror struct {
+	// Hosts serving podcast feeds whose URLs are not recognized by their path, e.g. "feeds.example.com".
+	// URLs ending with .rss, .xml, .atom, /rss, /feed or /atom and hosts starting with "feeds." are always recognized.
+	Hosts []string `yaml:"hosts"`
+	// ReprocessAudio re-encodes every episode with ffmpeg instead of keeping episodes already in the audio format
+	ReprocessAudio bool `yaml:"reprocessAudio"`
+}
+
+// Retry holds the retry policy for failed downloads.
+// The delay before retry n is initialBackoff * 2^(n-1), capped at maxBackoff and varied by +/- jitter.
+type Retry struct {
+	MaxAttempts    int           `yaml:"maxAttempts"`    // total number of download attempts per video
+	InitialBackoff time.Duration `yaml:"initialBackoff"` // e.g. "30s"
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`     // e.g. "10m"
+	Jitter         float64       `yaml:"jitter"`         // fraction of the delay in the range 0-1, e.g. 0.2 for +/- 20%
+}
+
+// DefaultLivePollInterval is used if no live poll interval is configured.
+const DefaultLivePollInterval = 5 * time.Minute
+
+// DefaultLiveMaxWait is used if no maximum wait for live streams is configured.
+const DefaultLiveMaxWait = 7 * 24 * time.Hour
+
+// DefaultWebhookTimeout is used if no webhook timeout is configured.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// DefaultSubscriptionPollInterval is used if no subscription poll interval is configured.
+const DefaultSubscriptionPollInterval = time.Hour
+
 var globalConfig *Config
 
 // LoadConfig loads configuration from the specified YAML file
@@ -71,6 +407,24 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := setDefaults(&config); err != nil {
 		return nil, fmt.Errorf("failed to set default values: %w", err)
 	}
+	if err := config.Filters.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	if err := config.YtDlp.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid yt-dlp sites: %w", err)
+	}
+	if err := config.Persistence.Media.Audio.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid audio: %w", err)
+	}
+	if err := config.YtDlp.SponsorBlock.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sponsorBlock: %w", err)
+	}
+	if err := config.Downloaders.Validate(config.YtDlp.Sites); err != nil {
+		return nil, fmt.Errorf("invalid downloaders: %w", err)
+	}
+	if err := config.Webhooks.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid webhooks: %w", err)
+	}
 
 	// Convert relative paths to absolute paths
 	if err := makePathsAbsolute(&config, filepath.Dir(configPath)); err != nil {
@@ -201,10 +555,71 @@ func setDefaults(config *Config) error {
 	if config.Persistence.Media.MaxParallelDownloads <= 0 {
 		config.Persistence.Media.MaxParallelDownloads = 1
 	}
+	if config.Persistence.Media.MaxParallelAvailabilityChecks <= 0 {
+		config.Persistence.Media.MaxParallelAvailabilityChecks = config.Persistence.Media.MaxParallelDownloads
+	}
+	setRetryDefaults(&config.Persistence.Media.Retry)
+	if config.Persistence.Media.Audio.Format == "" {
+		config.Persistence.Media.Audio.Format = AudioFormatMP3
+	}
+	if config.Persistence.Media.LivePollInterval <= 0 {
+		config.Persistence.Media.LivePollInterval = DefaultLivePollInterval
+	}
+	if config.Persistence.Media.LiveMaxWait <= 0 {
+		config.Persistence.Media.LiveMaxWait = DefaultLiveMaxWait
+	}
+	if config.Subscriptions.PollInterval <= 0 {
+		config.Subscriptions.PollInterval = DefaultSubscriptionPollInterval
+	}
+	if config.Webhooks.Timeout <= 0 {
+		config.Webhooks.Timeout = DefaultWebhookTimeout
+	}
+	for i := range config.YtDlp.Sites {
+		if config.YtDlp.Sites[i].OutputTemplate == "" {
+			config.YtDlp.Sites[i].OutputTemplate = DefaultSiteOutputTemplate
+		}
+	}
+	if config.YtDlp.SponsorBlock.Mode == "" {
+		config.YtDlp.SponsorBlock.Mode = SponsorBlockRemove
+	}
+	if len(config.YtDlp.SponsorBlock.Categories) == 0 {
+		config.YtDlp.SponsorBlock.Categories = DefaultSponsorBlockCategories
+	}
 
 	return nil
 }
 
+// setRetryDefaults sets the default retry policy for fields that weren't specified or are invalid
+func setRetryDefaults(retry *Retry) {
+	defaults := DefaultRetry()
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaults.MaxAttempts
+	}
+	if retry.InitialBackoff <= 0 {
+		retry.InitialBackoff = defaults.InitialBackoff
+	}
+	if retry.MaxBackoff <= 0 {
+		retry.MaxBackoff = defaults.MaxBackoff
+	}
+	if retry.MaxBackoff < retry.InitialBackoff {
+		retry.MaxBackoff = retry.InitialBackoff
+	}
+	// Note: a jitter of 0 is a valid value that disables jitter
+	if retry.Jitter < 0 || retry.Jitter > 1 {
+		retry.Jitter = defaults.Jitter
+	}
+}
+
+// DefaultRetry returns the retry policy used if none is configured.
+func DefaultRetry() Retry {
+	return Retry{
+		MaxAttempts:    4,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     10 * time.Minute,
+		Jitter:         0.2,
+	}
+}
+
 // logLoadedConfig logs the loaded configuration values
 func logLoadedConfig(config *Config) {
 	slog.Info("=== Configuration Loaded ===")
@@ -217,8 +632,30 @@ func logLoadedConfig(config *Config) {
 	slog.Info("Media Path", "value", config.Persistence.Media.MediaPath)
 	slog.Info("Temp Path", "value", config.Persistence.Media.TempPath)
 	slog.Info("Max Parallel Downloads", "value", config.Persistence.Media.MaxParallelDownloads)
+	slog.Info("Max Parallel Availability Checks", "value", config.Persistence.Media.MaxParallelAvailabilityChecks)
 	slog.Info("Allow Partial Downloads", "value", config.Persistence.Media.AllowPartialDownloads)
+	slog.Info("Retry Max Attempts", "value", config.Persistence.Media.Retry.MaxAttempts)
+	slog.Info("Retry Initial Backoff", "value", config.Persistence.Media.Retry.InitialBackoff)
+	slog.Info("Retry Max Backoff", "value", config.Persistence.Media.Retry.MaxBackoff)
+	slog.Info("Retry Jitter", "value", config.Persistence.Media.Retry.Jitter)
+	slog.Info("Live Poll Interval", "value", config.Persistence.Media.LivePollInterval)
+	slog.Info("Live Max Wait", "value", config.Persistence.Media.LiveMaxWait)
+	slog.Info("Direct Media Feed", "value", config.Persistence.Media.DirectMediaFeed)
+	slog.Info("Audio", "format", config.Persistence.Media.Audio.Format, "bitrate", config.Persistence.Media.Audio.Bitrate, "sampleRate", config.Persistence.Media.Audio.SampleRate)
+	slog.Info("Feed Mirror", "hosts", config.Persistence.Media.FeedMirror.Hosts, "reprocessAudio", config.Persistence.Media.FeedMirror.ReprocessAudio)
+	slog.Info("Subscription Poll Interval", "value", config.Subscriptions.PollInterval)
+	slog.Info("Webhook Endpoints", "value", len(config.Webhooks.Endpoints))
+	slog.Info("Filters", "minDuration", config.Filters.MinDuration, "maxDuration", config.Filters.MaxDuration,
+		"includeTitle", config.Filters.IncludeTitle, "excludeTitle", config.Filters.ExcludeTitle, "excludeShorts", config.Filters.ExcludeShorts)
 	slog.Info("yt-dlp Verbose", "value", config.YtDlp.Verbose)
+	for _, site := range config.YtDlp.Sites {
+		slog.Info("yt-dlp Site", "name", site.Name, "hosts", site.Hosts, "urlPatterns", site.URLPatterns, "args", site.Args, "outputTemplate", site.OutputTemplate)
+	}
+	slog.Info("SponsorBlock", "mode", config.YtDlp.SponsorBlock.Mode, "categories", config.YtDlp.SponsorBlock.Categories)
+	for name, feed := range config.YtDlp.SponsorBlock.Feeds {
+		slog.Info("SponsorBlock Feed", "name", name, "mode", feed.Mode, "categories", feed.Categories)
+	}
+	slog.Info("Downloaders", "order", config.Downloaders.Order, "disabled", config.Downloaders.Disabled)
 	slog.Info("============================")
 }
 
@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestMakeAbsolutePathFromRoot_AbsoluteUnchanged(t *testing.T) {
@@ -104,3 +108,199 @@ func TestCreateDirectoriesFromPaths(t *testing.T) {
 		}
 	}
 }
+
+func TestSetRetryDefaults_FillsUnsetValues(t *testing.T) {
+	retry := Retry{MaxAttempts: 2}
+	setRetryDefaults(&retry)
+
+	defaults := DefaultRetry()
+	if retry.MaxAttempts != 2 {
+		t.Fatalf("expected configured max attempts to be kept, got %d", retry.MaxAttempts)
+	}
+	if retry.InitialBackoff != defaults.InitialBackoff || retry.MaxBackoff != defaults.MaxBackoff {
+		t.Fatalf("expected default backoff, got %v/%v", retry.InitialBackoff, retry.MaxBackoff)
+	}
+}
+
+func TestRetry_ParsesDurations(t *testing.T) {
+	var media Media
+	data := []byte("retry:\n  maxAttempts: 3\n  initialBackoff: 5s\n  maxBackoff: 2m\n  jitter: 0.1\n")
+	if err := yaml.Unmarshal(data, &media); err != nil {
+		t.Fatalf("failed to parse retry config: %v", err)
+	}
+
+	want := Retry{MaxAttempts: 3, InitialBackoff: 5 * time.Second, MaxBackoff: 2 * time.Minute, Jitter: 0.1}
+	if media.Retry != want {
+		t.Fatalf("expected %+v, got %+v", want, media.Retry)
+	}
+}
+
+func TestFilters_Validate(t *testing.T) {
+	valid := []Filters{
+		{},
+		{MinDuration: 5 * time.Minute, MaxDuration: 3 * time.Hour},
+		{IncludeTitle: `(?i)episode \d+`, ExcludeTitle: "trailer|teaser", ExcludeShorts: true},
+	}
+	for _, filters := range valid {
+		if err := filters.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", filters, err)
+		}
+	}
+
+	invalid := []Filters{
+		{MinDuration: time.Hour, MaxDuration: time.Minute},
+		{MinDuration: -time.Minute},
+		{IncludeTitle: "(unclosed"},
+	}
+	for _, filters := range invalid {
+		if err := filters.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", filters)
+		}
+	}
+}
+
+func TestYtDlp_Validate(t *testing.T) {
+	validSites := []YtDlp{
+		{Sites: []Site{{Name: "vimeo", Hosts: []string{"vimeo.com"}}}},
+		{Sites: []Site{{Name: "talks", URLPatterns: []string{`^https://example\.com/talks/`}, Args: []string{"--no-playlist"}}}},
+	}
+	for _, valid := range validSites {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", valid, err)
+		}
+	}
+
+	invalid := []YtDlp{
+		{Sites: []Site{{Hosts: []string{"vimeo.com"}}}},
+		{Sites: []Site{{Name: "vimeo"}}},
+		{Sites: []Site{{Name: "vimeo", Hosts: []string{"https://vimeo.com"}}}},
+		{Sites: []Site{{Name: "bandcamp", Hosts: []string{"*.bandcamp.com"}}}},
+		{Sites: []Site{{Name: "talks", URLPatterns: []string{"talks/("}}}},
+		{Sites: []Site{{Name: "vimeo", Hosts: []string{"vimeo.com"}}, {Name: "vimeo", Hosts: []string{"player.vimeo.com"}}}},
+		{Sites: []Site{{Name: "youtube", Hosts: []string{"youtube-nocookie.com"}}}},
+	}
+	for _, ytDlp := range invalid {
+		if err := ytDlp.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", ytDlp)
+		}
+	}
+}
+
+func TestDownloaders_Validate(t *testing.T) {
+	sites := []Site{{Name: "vimeo", Hosts: []string{"vimeo.com"}}}
+	valid := Downloaders{Order: []string{"vimeo", "youtube"}, Disabled: []string{"twitch"}}
+	if err := valid.Validate(sites); err != nil {
+		t.Errorf("expected %+v to be valid, got %v", valid, err)
+	}
+
+	invalid := []Downloaders{
+		{Order: []string{"youtube", "youtube"}},
+		{Disabled: []string{""}},
+		{Disabled: []string{"twich"}},
+		{Order: []string{"soundcloud"}},
+	}
+	for _, downloaders := range invalid {
+		if err := downloaders.Validate(sites); err == nil {
+			t.Errorf("expected %+v to be invalid", downloaders)
+		}
+	}
+}
+
+func TestWebhooks_Validate(t *testing.T) {
+	valid := Webhooks{Endpoints: []WebhookEndpoint{
+		{URL: "https://example.com/hook", Secret: "secret", Events: []string{"download.completed", "download.failed"}},
+		{URL: "http://receiver:8080/hook", Secret: "secret"},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected %+v to be valid, got %v", valid, err)
+	}
+
+	invalid := []WebhookEndpoint{
+		{Secret: "secret"},
+		{URL: "example.com/hook", Secret: "secret"},
+		{URL: "ftp://example.com/hook", Secret: "secret"},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", Secret: "secret", Events: []string{"download.complete"}},
+	}
+	for _, endpoint := range invalid {
+		if err := (Webhooks{Endpoints: []WebhookEndpoint{endpoint}}).Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", endpoint)
+		}
+	}
+}
+
+func TestSponsorBlock_Validate(t *testing.T) {
+	valid := SponsorBlock{
+		Mode:       SponsorBlockRemove,
+		Categories: []string{"sponsor", "music_offtopic"},
+		Feeds:      map[string]SponsorBlockFeed{"Music Talks": {Mode: SponsorBlockMark}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected %+v to be valid, got %v", valid, err)
+	}
+
+	invalid := []SponsorBlock{
+		{Mode: "skip"},
+		{Categories: []string{"sponsors"}},
+		{Feeds: map[string]SponsorBlockFeed{"Music Talks": {Mode: "off"}}},
+	}
+	for _, sponsorBlock := range invalid {
+		if err := sponsorBlock.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", sponsorBlock)
+		}
+	}
+}
+
+func TestSponsorBlock_ForFeed(t *testing.T) {
+	sponsorBlock := SponsorBlock{
+		Mode:       SponsorBlockRemove,
+		Categories: []string{"sponsor", "music_offtopic"},
+		Feeds: map[string]SponsorBlockFeed{
+			"Music Talks": {Categories: []string{"sponsor"}},
+			"Live Sets":   {Mode: SponsorBlockDisabled},
+		},
+	}
+
+	tests := []struct {
+		feed string
+		want SponsorBlockFeed
+	}{
+		{feed: "Other", want: SponsorBlockFeed{Mode: SponsorBlockRemove, Categories: []string{"sponsor", "music_offtopic"}}},
+		{feed: "music talks", want: SponsorBlockFeed{Mode: SponsorBlockRemove, Categories: []string{"sponsor"}}},
+		{feed: "Live Sets", want: SponsorBlockFeed{Mode: SponsorBlockDisabled, Categories: []string{"sponsor", "music_offtopic"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.feed, func(t *testing.T) {
+			if got := sponsorBlock.ForFeed(tt.feed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForFeed() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudio_Validate(t *testing.T) {
+	valid := []Audio{
+		{},
+		{Format: AudioFormatMP3, Bitrate: "128k"},
+		{Format: AudioFormatM4A, SampleRate: 44100},
+		{Format: AudioFormatOpus, Bitrate: "48K", SampleRate: 48000},
+	}
+	for _, audio := range valid {
+		if err := audio.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", audio, err)
+		}
+	}
+
+	invalid := []Audio{
+		{Format: "aac"},
+		{Format: AudioFormatMP3, Bitrate: "128"},
+		{Format: AudioFormatMP3, Bitrate: "0k"},
+		{Format: AudioFormatM4A, SampleRate: -1},
+		{Format: AudioFormatOpus, SampleRate: 44100},
+	}
+	for _, audio := range invalid {
+		if err := audio.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", audio)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package chapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+)
+
+// Version is the version of the Podcasting 2.0 JSON chapters format written by this package,
+// see https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/examples/chapters/jsonChapters.md
+const Version = "1.2.0"
+
+// MIMEType is the type of a chapters document referenced by podcast:chapters.
+const MIMEType = "application/json+chapters"
+
+// probeTimeout limits reading the chapters embedded in an audio file.
+const probeTimeout = 30 * time.Second
+
+// Chapter is a chapter of an episode. Its JSON form is the one of the JSON chapters format.
+type Chapter struct {
+	StartSeconds float64 `json:"startTime"`
+	EndSeconds   float64 `json:"endTime,omitempty"`
+	Title        string  `json:"title"`
+}
+
+// Document is a Podcasting 2.0 JSON chapters document.
+type Document struct {
+	Version  string    `json:"version"`
+	Chapters []Chapter `json:"chapters"`
+}
+
+// NewDocument creates a chapters document of the given chapters.
+func NewDocument(chapters []Chapter) *Document {
+	return &Document{Version: Version, Chapters: chapters}
+}
+
+// ReadEmbedded returns the chapters embedded in an audio file, e.g. the chapters of the source video or the
+// SponsorBlock segments yt-dlp marked as chapters. It requires ffprobe.
+func ReadEmbedded(audioFilePath string) ([]Chapter, error) {
+	output, err := ffmpeg.ProbeWithTimeoutExec(audioFilePath, probeTimeout, ffmpeg.KwArgs{"show_chapters": "", "of": "json"})
+	if err != nil {
+		return nil, fmt.Errorf("could not read chapters of %s: %w", audioFilePath, err)
+	}
+	return parseFFprobeChapters([]byte(output))
+}
+
+// ffprobeChapters holds the fields of ffprobe -show_chapters output that chapters are built from.
+type ffprobeChapters struct {
+	Chapters []struct {
+		StartTime string            `json:"start_time"`
+		EndTime   string            `json:"end_time"`
+		Tags      map[string]string `json:"tags"`
+	} `json:"chapters"`
+}
+
+func parseFFprobeChapters(output []byte) ([]Chapter, error) {
+	var probed ffprobeChapters
+	if err := json.Unmarshal(output, &probed); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe chapters: %w", err)
+	}
+
+	result := make([]Chapter, 0, len(probed.Chapters))
+	for i, probedChapter := range probed.Chapters {
+		start, err := strconv.ParseFloat(probedChapter.StartTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q of chapter %d: %w", probedChapter.StartTime, i, err)
+		}
+		// the end is informational, chapters without it end where the next one starts
+		end, _ := strconv.ParseFloat(probedChapter.EndTime, 64)
+		title := strings.TrimSpace(probedChapter.Tags["title"])
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		result = append(result, Chapter{StartSeconds: start, EndSeconds: end, Title: title})
+	}
+	return result, nil
+}
+
+// descriptionChapterPattern matches a description line starting with a timestamp, e.g. "12:34 - Topic" or "1:02:03 Topic".
+var descriptionChapterPattern = regexp.MustCompile(`^\W*?(?:(\d{1,2}):)?(\d{1,2}):(\d{2})\s*[-–—:|.)]?\s*(.+)$`)
+
+// ParseDescription returns the chapters listed as timestamps in a video description, one per line.
+// Like YouTube, it only accepts lists of at least two ascending timestamps starting at 0:00,
+// so that timestamps mentioned in the text are not taken for chapters.
+// Lines may be separated by newlines or <br> as in the description tag of downloads.
+func ParseDescription(description string) []Chapter {
+	lines := strings.Split(strings.ReplaceAll(description, "<br>", "\n"), "\n")
+
+	result := make([]Chapter, 0)
+	for _, line := range lines {
+		match := descriptionChapterPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		hours, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		seconds, _ := strconv.Atoi(match[3])
+		if seconds >= 60 || (match[1] != "" && minutes >= 60) {
+			continue
+		}
+		start := float64(hours*3600 + minutes*60 + seconds)
+		if len(result) > 0 && start <= result[len(result)-1].StartSeconds {
+			return nil
+		}
+		result = append(result, Chapter{StartSeconds: start, Title: strings.TrimSpace(match[4])})
+	}
+
+	if len(result) < 2 || result[0].StartSeconds != 0 {
+		return nil
+	}
+	return result
+}
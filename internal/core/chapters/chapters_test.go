@@ -0,0 +1,87 @@
+package chapters
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        []Chapter
+	}{
+		{
+			name:        "chapter list",
+			description: "A talk about Go.<br><br>Chapters:<br>0:00 Intro<br>2:15 - Generics<br>1:02:03 | Q&A",
+			want: []Chapter{
+				{StartSeconds: 0, Title: "Intro"},
+				{StartSeconds: 135, Title: "Generics"},
+				{StartSeconds: 3723, Title: "Q&A"},
+			},
+		},
+		{
+			name:        "newlines and leading markers",
+			description: "▶ 00:00 Start\n- 10:00 End",
+			want: []Chapter{
+				{StartSeconds: 0, Title: "Start"},
+				{StartSeconds: 600, Title: "End"},
+			},
+		},
+		{name: "not starting at zero", description: "1:00 Intro<br>2:00 Talk"},
+		{name: "not ascending", description: "0:00 Intro<br>5:00 Talk<br>3:00 Outro"},
+		{name: "single timestamp", description: "0:00 Intro"},
+		{name: "timestamps in text", description: "We start at 0:00 and end at 10:00."},
+		{name: "no description", description: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDescription(tt.description)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDescription() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFFprobeChapters(t *testing.T) {
+	output := `{"chapters":[{"id":0,"time_base":"1/1000","start":0,"start_time":"0.000000","end":90500,"end_time":"90.500000","tags":{"title":"Intro"}},{"id":1,"time_base":"1/1000","start":90500,"start_time":"90.500000","end":300000,"end_time":"300.000000"}]}`
+
+	got, err := parseFFprobeChapters([]byte(output))
+	if err != nil {
+		t.Fatalf("parseFFprobeChapters() unexpected error: %v", err)
+	}
+
+	want := []Chapter{
+		{StartSeconds: 0, EndSeconds: 90.5, Title: "Intro"},
+		{StartSeconds: 90.5, EndSeconds: 300, Title: "Chapter 2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFFprobeChapters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFFprobeChapters_InvalidOutput_ReturnsError(t *testing.T) {
+	if _, err := parseFFprobeChapters([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid output")
+	}
+}
+
+func TestNewDocument_JSON(t *testing.T) {
+	document := NewDocument([]Chapter{{StartSeconds: 0, EndSeconds: 90.5, Title: "Intro"}, {StartSeconds: 90.5, Title: "Talk"}})
+
+	got, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+
+	want := `{"version":"1.2.0","chapters":[{"startTime":0,"endTime":90.5,"title":"Intro"},{"startTime":90.5,"title":"Talk"}]}`
+	if string(got) != want {
+		t.Errorf("document = %s, want %s", got, want)
+	}
+}
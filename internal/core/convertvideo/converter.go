@@ -1,9 +1,27 @@
 package convertvideo
 
 import (
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	ffmpeg "github.com/u2takey/ffmpeg-go"
 )
 
-func ConvertVideoToAudio(videoFilePath string, outputFilePath string) error {
-	return ffmpeg.Input(videoFilePath).Output(outputFilePath).Run()
+// ConvertVideoToAudio extracts the audio of a media file. The codec follows the extension of outputFilePath,
+// the bitrate and sample rate are the configured ones or the encoder defaults if audioConfig is nil.
+func ConvertVideoToAudio(videoFilePath string, outputFilePath string, audioConfig *config.Audio) error {
+	return ffmpeg.Input(videoFilePath).Output(outputFilePath, outputArgs(audioConfig)).Run()
+}
+
+func outputArgs(audioConfig *config.Audio) ffmpeg.KwArgs {
+	// drop video and cover art streams, not every audio container can hold them
+	kwArgs := ffmpeg.KwArgs{"vn": ""}
+	if audioConfig == nil {
+		return kwArgs
+	}
+	if audioConfig.Bitrate != "" {
+		kwArgs["b:a"] = audioConfig.Bitrate
+	}
+	if audioConfig.SampleRate > 0 {
+		kwArgs["ar"] = audioConfig.SampleRate
+	}
+	return kwArgs
 }
@@ -3,7 +3,11 @@ package convertvideo
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
 )
 
 func Test_convertVideoToAudio(t *testing.T) {
@@ -42,9 +46,34 @@ func Test_convertVideoToAudio(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := ConvertVideoToAudio(tt.args.inputFilePath, tt.args.outputFilePath); (err != nil) != tt.wantErr {
+			if err := ConvertVideoToAudio(tt.args.inputFilePath, tt.args.outputFilePath, nil); (err != nil) != tt.wantErr {
 				t.Errorf("ConvertVideoToAudio() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_outputArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		audioConfig *config.Audio
+		want        ffmpeg.KwArgs
+	}{
+		{
+			name: "encoder defaults",
+			want: ffmpeg.KwArgs{"vn": ""},
+		},
+		{
+			name:        "bitrate and sample rate",
+			audioConfig: &config.Audio{Format: config.AudioFormatOpus, Bitrate: "48k", SampleRate: 48000},
+			want:        ffmpeg.KwArgs{"vn": "", "b:a": "48k", "ar": 48000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outputArgs(tt.audioConfig); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outputArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -15,24 +16,68 @@ import (
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/webhook"
 )
 
+// FeedsPath is the API path under which feeds and their audio files are served.
+const FeedsPath = "v1/feeds"
+
+// ChaptersFileName is the name under which the chapters document of an item is served next to its feed,
+// e.g. v1/feeds/<feed title>/<item id>/chapters.json.
+const ChaptersFileName = "chapters.json"
+
+// ErrURLNotSupported is returned when no downloader supports a submitted URL or one of its entries.
+var ErrURLNotSupported = errors.New("url not supported")
+
+// ErrNoDownloadableVideos is returned when a submitted URL does not yield any video that can be downloaded.
+var ErrNoDownloadableVideos = errors.New("no downloadable videos")
+
 type CoreService struct {
 	databaseService      database.DatabaseService
 	audioSourceDirectory string
 	cookiesConfig        *config.Cookies
 	mediaConfig          *config.Media
 	ytDlpConfig          *config.YtDlp
+	webhooksConfig       *config.Webhooks
+	filtersConfig        *config.Filters
+	downloaders          *download.Registry
+	notifier             *webhook.Notifier
+	queueWakeup          chan struct{}
+	subscriptionWakeup   chan struct{}
+
+	// worker pools shared by all download requests so that the configured limits apply service-wide
+	downloadSlots          chan struct{}
+	availabilityCheckSlots chan struct{}
+
+	jobsMutex  sync.Mutex                         // serializes job state transitions that may race with a cancellation
+	jobCancels map[string]context.CancelCauseFunc // cancel functions of jobs that are currently being checked or downloaded
 }
 
-func NewCoreService(databaseService database.DatabaseService, audioSourceDirectory string, cookiesConfig *config.Cookies, mediaConfig *config.Media, ytDlpConfig *config.YtDlp) *CoreService {
-	return &CoreService{
+func NewCoreService(databaseService database.DatabaseService, audioSourceDirectory string, cookiesConfig *config.Cookies, mediaConfig *config.Media, ytDlpConfig *config.YtDlp, webhooksConfig *config.Webhooks, filtersConfig *config.Filters, downloadersConfig *config.Downloaders) *CoreService {
+	cs := &CoreService{
 		databaseService:      databaseService,
 		audioSourceDirectory: audioSourceDirectory,
 		cookiesConfig:        cookiesConfig,
 		mediaConfig:          mediaConfig,
 		ytDlpConfig:          ytDlpConfig,
+		webhooksConfig:       webhooksConfig,
+		filtersConfig:        filtersConfig,
+		downloaders:          download.NewRegistry(nil, cookiesConfig, mediaConfig, ytDlpConfig, downloadersConfig),
+		notifier:             webhook.NewNotifier(webhooksConfig),
+		queueWakeup:          make(chan struct{}, 1),
+		subscriptionWakeup:   make(chan struct{}, 1),
+		jobCancels:           make(map[string]context.CancelCauseFunc),
 	}
+	cs.downloadSlots = make(chan struct{}, cs.maxParallelDownloads())
+	cs.availabilityCheckSlots = make(chan struct{}, cs.maxParallelAvailabilityChecks())
+	slog.Info("downloaders by precedence", "names", cs.downloaders.Names())
+	return cs
+}
+
+// Close stops pending webhook deliveries. Downloads and subscription polls stop with the contexts they were started with.
+func (cs *CoreService) Close() {
+	cs.notifier.Close()
 }
 
 func (cs *CoreService) GetDatabaseService() database.DatabaseService {
@@ -62,6 +107,10 @@ func (cs *CoreService) DeletePodcastItem(id string) error {
 		} else if err == nil {
 			slog.Info("deleted audio file", "path", item.AudioFilePath)
 		}
+		infoFilePath := filemanagement.InfoFilePath(item.AudioFilePath)
+		if err := os.Remove(infoFilePath); err != nil && !os.IsNotExist(err) {
+			slog.Warn("failed to delete source metadata file", "path", infoFilePath, "err", err)
+		}
 	}
 
 	// Delete the database entry
@@ -91,19 +140,38 @@ func (cs *CoreService) DeletePodcastItem(id string) error {
 }
 
 func (cs *CoreService) GetLinkToFeed(baseURL *url.URL, apiPath string, audioFilePath string) string {
-	pathWithoutRoot := cs.getPathWithoutRoot(audioFilePath)
-	parts := strings.Split(pathWithoutRoot, string(os.PathSeparator))
-	if len(parts) == 0 {
-		slog.Error("audio file path does not contain a valid feed title", "audioFilePath", audioFilePath)
+	feedTitle, ok := cs.getFeedTitle(audioFilePath)
+	if !ok {
 		return ""
 	}
-	feedTitle := parts[0]
 
 	result := *baseURL
 	result.Path = fmt.Sprintf("/%s/%s/rss.xml", apiPath, url.PathEscape(feedTitle))
 	return result.String()
 }
 
+// GetLinkToChapters returns the link to the chapters document of a podcast item, see ChaptersFileName.
+func (cs *CoreService) GetLinkToChapters(baseURL *url.URL, apiPath string, podcastItem *database.PodcastItem) string {
+	feedTitle, ok := cs.getFeedTitle(podcastItem.AudioFilePath)
+	if !ok {
+		return ""
+	}
+
+	result := *baseURL
+	result.Path = fmt.Sprintf("/%s/%s/%s/%s", apiPath, url.PathEscape(feedTitle), url.PathEscape(podcastItem.ID), ChaptersFileName)
+	return result.String()
+}
+
+func (cs *CoreService) getFeedTitle(audioFilePath string) (string, bool) {
+	pathWithoutRoot := cs.getPathWithoutRoot(audioFilePath)
+	parts := strings.Split(pathWithoutRoot, string(os.PathSeparator))
+	if len(parts) == 0 {
+		slog.Error("audio file path does not contain a valid feed title", "audioFilePath", audioFilePath)
+		return "", false
+	}
+	return parts[0], true
+}
+
 func (cs *CoreService) GetLinkToAudioFile(baseURL *url.URL, apiPath string, audioFilePath string) string {
 	pathWithoutRoot := cs.getPathWithoutRoot(audioFilePath)
 	parts := strings.Split(pathWithoutRoot, string(os.PathSeparator))
@@ -123,86 +191,198 @@ func (cs *CoreService) getPathWithoutRoot(audioFilePath string) string {
 	return pathWithoutRoot
 }
 
-func (cs *CoreService) DownloadItemsHandler(url string) (err error) {
-	downloaderInstance, err := download.GetVideoDownloader(url, cs.cookiesConfig, cs.mediaConfig, cs.ytDlpConfig)
+// DownloadOptions controls how DownloadItemsHandler schedules the videos of a URL.
+type DownloadOptions struct {
+	// Force downloads videos again even if they are already in the library.
+	Force bool
+	// Entries restricts the download to the given videos of the URL, e.g. as selected from a preview.
+	// The URL is not listed again if entries are given.
+	Entries []string
+	// Selection restricts the listed videos of a playlist or channel URL. It is ignored if entries are given.
+	Selection downloader.Selection
+	// Filters replace the configured content filters for this download if set. They are not applied to entries.
+	Filters *config.Filters
+	// AcceptPartial queues the available videos even if partial downloads are not allowed.
+	AcceptPartial bool
+}
+
+// DownloadItemsHandler expands the given URL into individual videos, records a download job for each of them
+// and checks their availability. Available videos are queued for download in the background.
+// Live streams and upcoming premieres are parked as waiting jobs and queued once their recording is available.
+// Videos that are already in the library are skipped and reported as already present unless options.Force is set.
+// Videos that do not pass the content filters are skipped and reported as filtered.
+// It returns the jobs created for the URL; they share a download ID that can be used to cancel them.
+// Jobs are also returned alongside errors that occur after they were created.
+func (cs *CoreService) DownloadItemsHandler(ctx context.Context, url string, options DownloadOptions) (jobs []*database.DownloadJob, err error) {
+	downloaderInstance, err := cs.downloaders.GetVideoDownloader(url)
 	if err != nil {
-		return fmt.Errorf("url %s not supported", url)
+		return nil, fmt.Errorf("%w: %s", ErrURLNotSupported, url)
+	}
+	if err := options.Selection.Validate(); err != nil {
+		return nil, err
+	}
+	if options.Filters != nil {
+		if err := options.Filters.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid filters: %w", err)
+		}
 	}
 
 	// Get individual urls (playlist expands to multiple URLs; single video returns itself)
-	urls, err := downloaderInstance.ListIndividualVideoURLs(url)
-	if err != nil {
-		slog.Error("failed to list video urls", "url", url, "err", err)
-		return fmt.Errorf("failed to list urls for %s", url)
+	urls := options.Entries
+	for _, entryURL := range urls {
+		if !downloaderInstance.IsVideoSupported(entryURL) {
+			return nil, fmt.Errorf("%w: entry %s of %s", ErrURLNotSupported, entryURL, url)
+		}
+	}
+	if len(urls) == 0 {
+		urls, err = downloaderInstance.ListIndividualVideoURLs(ctx, url, options.Selection)
+		if err != nil {
+			slog.Error("failed to list video urls", "url", url, "err", err)
+			return nil, fmt.Errorf("%w: failed to list urls for %s", ErrNoDownloadableVideos, url)
+		}
 	}
 	if len(urls) == 0 {
-		return fmt.Errorf("no downloadable urls for %s", url)
+		return nil, fmt.Errorf("%w: no urls listed for %s", ErrNoDownloadableVideos, url)
 	}
+	return cs.scheduleDownloads(ctx, downloaderInstance, url, urls, options)
+}
 
-	slog.Info("starting downloads", "requestedUrl", url, "entryCount", len(urls))
+// scheduleDownloads records a download job for each of the given video URLs, which were listed for url,
+// and checks their availability. See DownloadItemsHandler for how the jobs are scheduled.
+func (cs *CoreService) scheduleDownloads(ctx context.Context, downloaderInstance downloader.AudioDownloader, url string, urls []string, options DownloadOptions) (jobs []*database.DownloadJob, err error) {
+	skipReasons, err := cs.filterVideos(ctx, downloaderInstance, urls, options)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadID := database.NewDownloadID()
+	slog.Info("starting downloads", "requestedUrl", url, "downloadID", downloadID, "entryCount", len(urls))
 
-	// Throttle parallel downloads using a semaphore based on configured max parallel downloads (default 1)
-	maxParallel := cs.mediaConfig.MaxParallelDownloads
-	if maxParallel <= 0 {
-		maxParallel = 1
+	// Persist a job per entry so that every accepted URL can be tracked
+	jobs = make([]*database.DownloadJob, 0, len(urls))
+	pendingJobs := make([]*database.DownloadJob, 0, len(urls))
+	for _, entryURL := range urls {
+		job := database.NewDownloadJob(downloaderInstance.NormalizeVideoURL(entryURL))
+		job.DownloadID = downloadID
+		job.State = database.JobStateCheckingAvailability
+		if !options.Force && cs.isInLibrary(job.URL) {
+			slog.Info("video is already present, skipping download", "url", job.URL)
+			job.State = database.JobStateAlreadyPresent
+			job.Progress = 100
+		} else if reason, filtered := skipReasons[entryURL]; filtered {
+			slog.Info("video was filtered, skipping download", "url", job.URL, "reason", reason)
+			job.State = database.JobStateFiltered
+			job.LastError = reason
+		}
+		if err := cs.databaseService.InsertDownloadJob(job); err != nil {
+			return nil, fmt.Errorf("failed to persist download job for %s: %w", entryURL, err)
+		}
+		jobs = append(jobs, job)
+		if job.State == database.JobStateCheckingAvailability {
+			pendingJobs = append(pendingJobs, job)
+		}
+	}
+	if len(pendingJobs) == 0 {
+		slog.Info("all videos are already present or filtered", "requestedUrl", url, "entryCount", len(jobs))
+		return jobs, nil
 	}
-	downloadSem := make(chan struct{}, maxParallel)
 
-	// Run availability checks concurrently, bounded by maxParallel
-	availableUrls := make([]string, 0, len(urls))
+	// Run availability checks concurrently, bounded by the service-wide availability check limit
+	availableJobs := make([]*database.DownloadJob, 0, len(pendingJobs))
+	waitingJobs := make([]*database.DownloadJob, 0)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
-	availSem := make(chan struct{}, maxParallel)
 
-	var liveErr error
-	for _, entryURL := range urls {
+	cancelled := false
+	for _, job := range pendingJobs {
+		jobCtx := cs.registerJob(ctx, job.ID)
 		wg.Add(1)
-		go func(u string) {
+		go func(job *database.DownloadJob) {
 			defer wg.Done()
-			availSem <- struct{}{}
-			defer func() { <-availSem }()
-			if err := downloaderInstance.CheckVideoAvailability(u); err != nil {
-				if errors.Is(err, downloader.ErrVideoLive) {
+			defer cs.unregisterJob(job.ID)
+			err := cs.runWithSlot(jobCtx, cs.availabilityCheckSlots, func() error {
+				return downloaderInstance.CheckVideoAvailability(jobCtx, job.URL)
+			})
+			if err != nil {
+				if jobCtx.Err() != nil {
 					mu.Lock()
-					liveErr = fmt.Errorf("%w: %s", downloader.ErrVideoLive, u)
+					cancelled = true
 					mu.Unlock()
+					return
+				}
+				if isLiveOrUpcoming(err) {
+					slog.Info("video is live or upcoming, waiting for its recording", "url", job.URL, "err", err)
+					mu.Lock()
+					waitingJobs = append(waitingJobs, job)
+					mu.Unlock()
+					return
+				}
+				slog.Error("video is not available, skipping download for", "url", job.URL, "err", err)
+				if cs.failJob(job, err) {
+					cs.notifyJob(webhook.EventDownloadUnavailable, job, err)
 				}
-				slog.Error("video is not available, skipping download for", "url", u, "err", err)
 				return
 			}
 			mu.Lock()
-			availableUrls = append(availableUrls, u)
+			availableJobs = append(availableJobs, job)
 			mu.Unlock()
-		}(entryURL)
+		}(job)
 	}
 	wg.Wait()
 
-	// Enforce partial download policy
-	if len(availableUrls) == 0 {
-		if liveErr != nil {
-			return liveErr
+	if cancelled {
+		// the download was cancelled or the request was aborted while checking availability
+		for _, job := range pendingJobs {
+			cs.transitionJob(job, database.JobStateCheckingAvailability, database.JobStateCancelled)
 		}
-		return fmt.Errorf("no available videos for %s", url)
+		return jobs, fmt.Errorf("%w: %s", ErrDownloadCancelled, url)
 	}
-	if len(availableUrls) != len(urls) {
-		slog.Warn("some videos are not available and will be skipped", "requestedUrl", url, "availableCount", len(availableUrls), "requestedCount", len(urls))
-		if !cs.mediaConfig.AllowPartialDownloads {
-			return fmt.Errorf("partial downloads not allowed: %d of %d available for %s", len(availableUrls), len(urls), url)
+
+	// Enforce partial download policy; waiting videos count as available since they are downloaded later
+	acceptedCount := len(availableJobs) + len(waitingJobs)
+	if acceptedCount == 0 {
+		return jobs, fmt.Errorf("%w: no available videos for %s", ErrNoDownloadableVideos, url)
+	}
+	if acceptedCount != len(pendingJobs) {
+		slog.Warn("some videos are not available and will be skipped", "requestedUrl", url, "availableCount", acceptedCount, "requestedCount", len(pendingJobs))
+		if !cs.mediaConfig.AllowPartialDownloads && !options.AcceptPartial {
+			err := fmt.Errorf("%w: partial downloads not allowed, %d of %d available for %s", ErrNoDownloadableVideos, acceptedCount, len(pendingJobs), url)
+			for _, job := range append(availableJobs, waitingJobs...) {
+				cs.failJob(job, err)
+			}
+			return jobs, err
 		}
 	}
 
-	// Schedule downloads in background to avoid blocking the API response
-	go func(availableUrls []string) {
-		for _, entryURL := range availableUrls {
-			downloadSem <- struct{}{}
-			go func(u string) {
-				defer func() { <-downloadSem }()
-				cs.handleDownload(u, downloaderInstance)
-			}(entryURL)
+	for _, job := range waitingJobs {
+		job.NextCheckAt = time.Now().UTC().Add(cs.livePollInterval())
+		if cs.transitionJob(job, database.JobStateCheckingAvailability, database.JobStateWaiting) {
+			cs.notifyJob(webhook.EventDownloadAccepted, job, nil)
 		}
-	}(availableUrls)
+	}
 
-	return nil
+	// The download queue picks up queued jobs in the background and resumes them after a restart.
+	// Jobs cancelled in the meantime are not queued.
+	for _, job := range availableJobs {
+		if cs.transitionJob(job, database.JobStateCheckingAvailability, database.JobStateQueued) {
+			cs.notifyJob(webhook.EventDownloadAccepted, job, nil)
+		}
+	}
+	cs.wakeDownloadQueue()
+
+	return jobs, nil
+}
+
+// IsURLSupported reports whether a downloader supports the given URL.
+func (cs *CoreService) IsURLSupported(url string) bool {
+	_, err := cs.downloaders.GetVideoDownloader(url)
+	return err == nil
+}
+
+// isInLibrary reports whether a podcast item for the given normalized video URL already exists.
+func (cs *CoreService) isInLibrary(videoURL string) bool {
+	item, err := cs.databaseService.GetPodcastItemByID(database.PodcastItemIDForVideoURL(videoURL))
+	return err == nil && item != nil
 }
 
 func (cs *CoreService) GetFeedDirectory(audioFilePath string) (string, error) {
@@ -218,33 +398,57 @@ func (cs *CoreService) GetFeedDirectory(audioFilePath string) (string, error) {
 	return parentFolder, nil
 }
 
-// handleDownload performs the download and podcast item creation with improved error handling and less nesting
-func (cs *CoreService) handleDownload(url string, downloader downloader.AudioDownloader) {
-	const maxDownloadAttempts = 4
-	const downloadBackoff = 30 * time.Second
+// handleDownload performs the download and podcast item creation with improved error handling and less nesting.
+// Attempts, progress and errors are recorded on the job. It returns the stored podcast item, or an error if the item
+// could not be downloaded or stored.
+// Failed downloads are retried with exponential backoff according to the configured retry policy, unless the failure
+// is permanent (e.g. a private or removed video). Retries stop as soon as ctx is cancelled.
+func (cs *CoreService) handleDownload(ctx context.Context, job *database.DownloadJob, audioDownloader downloader.AudioDownloader) (*database.PodcastItem, error) {
+	retry := cs.retryPolicy()
 
+	url := job.URL
 	var filePath string
 	var err error
-	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
-		filePath, err = downloader.Download(url, cs.audioSourceDirectory)
+	for attempt := 1; attempt <= retry.MaxAttempts; attempt++ {
+		job.Attempts++
+		job.Progress = 0
+		if !cs.transitionJob(job, job.State, database.JobStateDownloading) {
+			return nil, fmt.Errorf("download of %s stopped: job is not running anymore", url)
+		}
+
+		filePath, err = audioDownloader.Download(ctx, url, cs.audioSourceDirectory, cs.jobProgressReporter(ctx, job))
 		if err == nil {
 			break
 		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("download of %s stopped: %w", url, context.Cause(ctx))
+		}
 		slog.Error("failed to download", "url", url, "attempt", attempt, "err", err)
-		if attempt < maxDownloadAttempts {
-			slog.Info("retrying download", "url", url, "backoff", downloadBackoff, "nextAttempt", attempt+1)
-			time.Sleep(downloadBackoff)
+		job.LastError = err.Error()
+		if errors.Is(err, downloader.ErrPermanentFailure) {
+			slog.Warn("not retrying download after permanent failure", "url", url, "attempt", attempt)
+			return nil, fmt.Errorf("download failed permanently: %w", err)
+		}
+		if attempt < retry.MaxAttempts {
+			backoff := retryBackoff(retry, attempt)
+			slog.Info("retrying download", "url", url, "backoff", backoff, "nextAttempt", attempt+1)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("download of %s stopped: %w", url, context.Cause(ctx))
+			case <-time.After(backoff):
+			}
 		}
 	}
 	if err != nil {
-		slog.Warn("giving up on download after max attempts", "url", url, "attempts", maxDownloadAttempts)
-		return
+		slog.Warn("giving up on download after max attempts", "url", url, "attempts", retry.MaxAttempts)
+		return nil, fmt.Errorf("download failed after %d attempts: %w", retry.MaxAttempts, err)
 	}
 
 	const maxErrorCount = 4
 	retries := 0
+	var podcastItem *database.PodcastItem
 	for retries < maxErrorCount {
-		podcastItem, err := database.NewPodcastItem(filePath)
+		podcastItem, err = database.NewPodcastItem(filePath)
 		if err != nil {
 			slog.Error("failed to create podcast item", "filePath", filePath, "err", err)
 			retries++
@@ -263,5 +467,7 @@ func (cs *CoreService) handleDownload(url string, downloader downloader.AudioDow
 	}
 	if retries == maxErrorCount {
 		slog.Warn("giving up on file after max attempts", "filePath", filePath, "attempts", maxErrorCount)
+		return nil, fmt.Errorf("failed to store podcast item for %s", filePath)
 	}
+	return podcastItem, nil
 }
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+)
+
+func TestDownloadItemsHandler_VideoInLibrary_IsReportedAsAlreadyPresent(t *testing.T) {
+	db := database.NewMockDatabase()
+	existingURL := "https://www.youtube.com/watch?v=jNQXAC9IVRw"
+	db.Items[database.PodcastItemIDForVideoURL(existingURL)] = &database.PodcastItem{VideoURL: existingURL}
+	cs := NewCoreService(db, "", nil, &config.Media{}, nil, nil, nil, nil)
+
+	jobs, err := cs.DownloadItemsHandler(context.Background(), "https://youtu.be/jNQXAC9IVRw?feature=shared", DownloadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	if jobs[0].State != database.JobStateAlreadyPresent {
+		t.Errorf("expected state %q, got %q", database.JobStateAlreadyPresent, jobs[0].State)
+	}
+	if jobs[0].URL != existingURL {
+		t.Errorf("expected normalized url %q, got %q", existingURL, jobs[0].URL)
+	}
+}
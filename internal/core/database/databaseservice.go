@@ -11,4 +11,18 @@ type DatabaseService interface {
 	GetAllPodcastItems() ([]*PodcastItem, error)
 	DeletePodcastItem(id string) error
 	GetPodcastItemByID(id string) (*PodcastItem, error)
+
+	InsertDownloadJob(job *DownloadJob) error
+	UpdateDownloadJob(job *DownloadJob) error
+	GetDownloadJobByID(id string) (*DownloadJob, error)
+	GetAllDownloadJobs() ([]*DownloadJob, error)
+	GetDownloadJobsByState(states ...JobState) ([]*DownloadJob, error) // GetDownloadJobsByState returns the jobs in any of the given states, oldest first.
+	GetDownloadJobsByDownloadID(downloadID string) ([]*DownloadJob, error)
+	GetDownloadJobsByURL(urls ...string) ([]*DownloadJob, error) // GetDownloadJobsByURL returns the jobs for any of the given video URLs, oldest first.
+
+	InsertSubscription(subscription *Subscription) error
+	UpdateSubscription(subscription *Subscription) error
+	GetSubscriptionByID(id string) (*Subscription, error)
+	GetAllSubscriptions() ([]*Subscription, error) // GetAllSubscriptions returns all subscriptions, oldest first.
+	DeleteSubscription(id string) error
 }
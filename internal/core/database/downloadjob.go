@@ -0,0 +1,79 @@
+package database
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// JobState describes where a download job currently is in its lifecycle.
+type JobState string
+
+const (
+	JobStateCheckingAvailability JobState = "checking_availability"
+	JobStateWaiting              JobState = "waiting" // live stream or premiere whose recording is not available yet
+	JobStateQueued               JobState = "queued"
+	JobStateDownloading          JobState = "downloading"
+	JobStateTagging              JobState = "tagging"
+	JobStateMoving               JobState = "moving"
+	JobStateDone                 JobState = "done"
+	JobStateFailed               JobState = "failed"
+	JobStateCancelled            JobState = "cancelled"
+	JobStateAlreadyPresent       JobState = "already_present" // the video is already in the library and was not downloaded again
+	JobStateFiltered             JobState = "filtered"        // the video was skipped by a content filter, the reason is in LastError
+)
+
+// ActiveJobStates are the states of a job that a worker is currently processing.
+var ActiveJobStates = []JobState{JobStateDownloading, JobStateTagging, JobStateMoving}
+
+// UnfinishedJobStates are the states of a job that has not reached a final state yet.
+var UnfinishedJobStates = append([]JobState{JobStateCheckingAvailability, JobStateWaiting, JobStateQueued}, ActiveJobStates...)
+
+// IsFinal reports whether a job in this state will not be processed any further.
+func (s JobState) IsFinal() bool {
+	return s == JobStateDone || s == JobStateFailed || s == JobStateCancelled || s == JobStateAlreadyPresent || s == JobStateFiltered
+}
+
+// DownloadJob is the persisted record of a single video URL that was accepted for download.
+// All jobs created for one submitted URL (e.g. the entries of a playlist) share the same DownloadID.
+type DownloadJob struct {
+	ID         string   `json:"id"`
+	DownloadID string   `json:"download_id"`
+	URL        string   `json:"url"`
+	State      JobState `json:"state"`
+	Attempts   int      `json:"attempts"`
+	LastError  string   `json:"last_error,omitempty"`
+	// PermanentFailure is set on failed jobs whose error retrying cannot fix, e.g. a private or removed video.
+	PermanentFailure bool    `json:"permanent_failure,omitempty"`
+	Progress         float64 `json:"progress"` // Download progress in percent (0-100)
+	// NextCheckAt is when the availability of a waiting job is checked again.
+	NextCheckAt time.Time `json:"next_check_at,omitzero"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewDownloadJob creates a queued job with a random identifier for the given video URL.
+// The job forms its own download until DownloadID is set to group it with other jobs.
+func NewDownloadJob(videoURL string) *DownloadJob {
+	now := time.Now().UTC()
+	id := newRandomID()
+	return &DownloadJob{
+		ID:         id,
+		DownloadID: id,
+		URL:        videoURL,
+		State:      JobStateQueued,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// NewDownloadID returns a random identifier that groups the jobs of one submitted URL.
+func NewDownloadID() string {
+	return newRandomID()
+}
+
+func newRandomID() string {
+	randomBytes := make([]byte, 16)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(randomBytes)
+	return formatUUIDv4(randomBytes)
+}
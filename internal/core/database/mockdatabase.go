@@ -1,9 +1,16 @@
 package database
 
-import "database/sql"
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+)
 
 type MockDatabase struct {
 	Items                       map[string]*PodcastItem
+	Jobs                        map[string]*DownloadJob
+	Subscriptions               map[string]*Subscription
 	CreatePodcastItemFunc       func(item *PodcastItem) error
 	GetPodcastItemByIDFunc      func(id string) (*PodcastItem, error)
 	GetAllPodcastItemsFunc      func() ([]*PodcastItem, error)
@@ -13,7 +20,11 @@ type MockDatabase struct {
 }
 
 func NewMockDatabase() *MockDatabase {
-	return &MockDatabase{Items: make(map[string]*PodcastItem)}
+	return &MockDatabase{
+		Items:         make(map[string]*PodcastItem),
+		Jobs:          make(map[string]*DownloadJob),
+		Subscriptions: make(map[string]*Subscription),
+	}
 }
 
 func (m *MockDatabase) InsertReplacePodcastItem(item *PodcastItem) error {
@@ -86,3 +97,113 @@ func (m *MockDatabase) CreateDatabase() (*sql.DB, error) {
 func (m *MockDatabase) DoesDatabaseExist() bool {
 	return true
 }
+
+func (m *MockDatabase) InsertDownloadJob(job *DownloadJob) error {
+	m.Jobs[job.ID] = job
+	return nil
+}
+
+func (m *MockDatabase) UpdateDownloadJob(job *DownloadJob) error {
+	if _, ok := m.Jobs[job.ID]; !ok {
+		return fmt.Errorf("download job with id %s not found", job.ID)
+	}
+	m.Jobs[job.ID] = job
+	return nil
+}
+
+func (m *MockDatabase) GetDownloadJobByID(id string) (*DownloadJob, error) {
+	job, ok := m.Jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("download job with id %s not found", id)
+	}
+	return job, nil
+}
+
+func (m *MockDatabase) GetAllDownloadJobs() ([]*DownloadJob, error) {
+	jobs := make([]*DownloadJob, 0, len(m.Jobs))
+	for _, job := range m.Jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+func (m *MockDatabase) GetDownloadJobsByState(states ...JobState) ([]*DownloadJob, error) {
+	allJobs, _ := m.GetAllDownloadJobs()
+	jobs := make([]*DownloadJob, 0)
+	for _, job := range allJobs {
+		if slices.Contains(states, job.State) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *MockDatabase) GetDownloadJobsByDownloadID(downloadID string) ([]*DownloadJob, error) {
+	allJobs, _ := m.GetAllDownloadJobs()
+	jobs := make([]*DownloadJob, 0)
+	for _, job := range allJobs {
+		if job.DownloadID == downloadID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *MockDatabase) GetDownloadJobsByURL(urls ...string) ([]*DownloadJob, error) {
+	allJobs, _ := m.GetAllDownloadJobs()
+	jobs := make([]*DownloadJob, 0)
+	for _, job := range allJobs {
+		if slices.Contains(urls, job.URL) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *MockDatabase) InsertSubscription(subscription *Subscription) error {
+	for _, existing := range m.Subscriptions {
+		if existing.URL == subscription.URL {
+			return fmt.Errorf("subscription for url %s already exists", subscription.URL)
+		}
+	}
+	m.Subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *MockDatabase) UpdateSubscription(subscription *Subscription) error {
+	if _, ok := m.Subscriptions[subscription.ID]; !ok {
+		return fmt.Errorf("subscription with id %s not found", subscription.ID)
+	}
+	m.Subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *MockDatabase) GetSubscriptionByID(id string) (*Subscription, error) {
+	subscription, ok := m.Subscriptions[id]
+	if !ok {
+		return nil, fmt.Errorf("subscription with id %s not found", id)
+	}
+	return subscription, nil
+}
+
+func (m *MockDatabase) GetAllSubscriptions() ([]*Subscription, error) {
+	subscriptions := make([]*Subscription, 0, len(m.Subscriptions))
+	for _, subscription := range m.Subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+func (m *MockDatabase) DeleteSubscription(id string) error {
+	if _, ok := m.Subscriptions[id]; !ok {
+		return fmt.Errorf("subscription with id %s not found", id)
+	}
+	delete(m.Subscriptions, id)
+	return nil
+}
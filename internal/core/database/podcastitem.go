@@ -2,6 +2,8 @@ package database
 
 import (
 	"crypto/md5"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,11 +11,15 @@ import (
 	"strings"
 	"time"
 
-	mp3joiner "github.com/jo-hoe/mp3-joiner"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/common"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
 )
 
+// ErrPodcastItemNotFound is returned when no podcast item with the requested ID exists.
+var ErrPodcastItemNotFound = errors.New("podcast item not found")
+
 type PodcastItem struct {
 	ID                     string    `json:"id"` // Unique identifier for the video item
 	Title                  string    `json:"title"`
@@ -25,15 +31,20 @@ type PodcastItem struct {
 	AudioFilePath          string    `json:"audio_file_path"` // Path to the downloaded audio file
 	CreatedAt              time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
+	// SourceMetadata is the metadata reported by the source of the episode, e.g. the yt-dlp info JSON of the video.
+	// It is empty for episodes without a source metadata file.
+	SourceMetadata json.RawMessage `json:"source_metadata,omitempty"`
+	// Chapters are the chapters embedded in the audio file or, without those, listed in the description
+	Chapters []chapters.Chapter `json:"chapters,omitempty"`
 }
 
 func NewPodcastItem(audioFilePath string) (podcastItem *PodcastItem, err error) {
-	audioMetadata, err := mp3joiner.GetFFmpegMetadataTag(audioFilePath)
+	audioMetadata, err := downloader.GetTags(audioFilePath)
 	if err != nil {
 		return nil, err
 	}
 
-	lengthInSeconds, err := mp3joiner.GetLengthInSeconds(audioFilePath)
+	lengthInSeconds, err := downloader.GetLengthInSeconds(audioFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -61,19 +72,59 @@ func NewPodcastItem(audioFilePath string) (podcastItem *PodcastItem, err error)
 		AudioFilePath:          audioFilePath,
 		CreatedAt:              uploadTime.UTC(),
 		UpdatedAt:              time.Now().UTC(),
+		SourceMetadata:         readSourceMetadata(audioFilePath),
+		Chapters:               readChapters(audioFilePath, description),
 	}
 
 	return podcastItem, err
 }
 
+// readSourceMetadata reads the source metadata file of an audio file, see filemanagement.InfoFilePath.
+// Missing or invalid files result in no source metadata.
+func readSourceMetadata(audioFilePath string) json.RawMessage {
+	content, err := os.ReadFile(filemanagement.InfoFilePath(audioFilePath))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("could not read source metadata", "audioFilePath", audioFilePath, "err", err)
+		}
+		return nil
+	}
+	if !json.Valid(content) {
+		slog.Warn("ignoring invalid source metadata", "audioFilePath", audioFilePath)
+		return nil
+	}
+	return content
+}
+
+// readChapters returns the chapters embedded in an audio file, falling back to timestamps listed in its description.
+func readChapters(audioFilePath string, description string) []chapters.Chapter {
+	embedded, err := chapters.ReadEmbedded(audioFilePath)
+	if err != nil {
+		slog.Warn("could not read embedded chapters", "audioFilePath", audioFilePath, "err", err)
+	}
+	if len(embedded) > 0 {
+		return embedded
+	}
+	return chapters.ParseDescription(description)
+}
+
+// PodcastItemIDForVideoURL returns the ID a podcast item gets for the given video URL.
+// The URL has to be normalized the same way as the one stored in the audio metadata.
+func PodcastItemIDForVideoURL(videoURL string) string {
+	return stringToHash(videoURL)
+}
+
 func stringToHash(input string) string {
 	// take an audio file path and hash it to a UUIDv4
 	data := []byte(input)
 	hash := md5.Sum(data)
-	hashBytes := hash[:]
+	return formatUUIDv4(hash[:])
+}
 
+// formatUUIDv4 formats 16 bytes as a UUIDv4 string.
+func formatUUIDv4(input []byte) string {
 	uuid := make([]byte, 16)
-	copy(uuid, hashBytes)
+	copy(uuid, input)
 
 	// Set version (4) and variant bits according to RFC 4122
 	uuid[6] = (uuid[6] & 0x0f) | 0x40 // Version 4
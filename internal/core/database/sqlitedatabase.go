@@ -2,9 +2,11 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -12,6 +14,8 @@ const (
 	defaultDatabaseName     = "podcast_items"
 	defaultDatabaseExt      = ".db"
 	defaultDatabaseFileName = defaultDatabaseName + defaultDatabaseExt
+
+	podcastItemColumns = "id, title, description, author, thumbnail, duration_in_milliseconds, video_url, audio_file_path, created_at, updated_at, source_metadata, chapters"
 )
 
 // SQLiteDatabase implements the Database interface using SQLite and prepared statements.
@@ -36,6 +40,20 @@ func (s *SQLiteDatabase) InitializeDatabase() (*sql.DB, error) {
 		_ = db.Close()
 		return nil, err
 	}
+	// SQLite allows a single writer only; serialize access from the download workers
+	db.SetMaxOpenConns(1)
+	if err := migratePodcastItemsTable(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := createDownloadJobsTable(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := createSubscriptionsTable(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 	s.db = db
 	return db, nil
 }
@@ -59,17 +77,88 @@ func (s *SQLiteDatabase) CreateDatabase() (*sql.DB, error) {
 		video_url TEXT,
 		audio_file_path TEXT,
 		created_at DATETIME,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		source_metadata TEXT NOT NULL DEFAULT '',
+		chapters TEXT NOT NULL DEFAULT ''
 	)`, defaultDatabaseName)
 	_, err = db.Exec(createTableStmt)
 	if err != nil {
 		_ = db.Close()
 		return nil, err
 	}
+	// SQLite allows a single writer only; serialize access from the download workers
+	db.SetMaxOpenConns(1)
+	if err := migratePodcastItemsTable(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := createDownloadJobsTable(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := createSubscriptionsTable(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 	s.db = db
 	return db, nil
 }
 
+// migratePodcastItemsTable adds the columns introduced after the podcast items table was first created.
+// Databases without the table are left untouched.
+func migratePodcastItemsTable(db *sql.DB) error {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type='table' AND name=?)`, defaultDatabaseName).Scan(&exists)
+	if err != nil || !exists {
+		return err
+	}
+	migrations := []struct{ column, definition string }{
+		{"source_metadata", "TEXT NOT NULL DEFAULT ''"},
+		{"chapters", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, migration := range migrations {
+		if err := addColumnIfMissing(db, defaultDatabaseName, migration.column, migration.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column to an existing table. It is used to migrate
+// databases that were created by an older version of the service.
+func addColumnIfMissing(db *sql.DB, tableName string, columnName string, columnDefinition string) error {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, tableName))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return err
+		}
+		if name == columnName {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// release the connection before altering the table
+	_ = rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, tableName, columnName, columnDefinition))
+	return err
+}
+
 func (s *SQLiteDatabase) DoesDatabaseExist() bool {
 	// Check if the database file exists
 	if s.db == nil {
@@ -85,9 +174,11 @@ func (s *SQLiteDatabase) DoesDatabaseExist() bool {
 }
 
 func (s *SQLiteDatabase) InsertReplacePodcastItem(item *PodcastItem) error {
-	stmt, err := s.db.Prepare(fmt.Sprintf(`INSERT OR REPLACE INTO %s (
-		id, title, description, author, thumbnail, duration_in_milliseconds, video_url, audio_file_path, created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, defaultDatabaseName))
+	storedChapters, err := marshalChapters(item.Chapters)
+	if err != nil {
+		return err
+	}
+	stmt, err := s.db.Prepare(fmt.Sprintf(`INSERT OR REPLACE INTO %s (%s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, defaultDatabaseName, podcastItemColumns))
 	if err != nil {
 		return err
 	}
@@ -95,25 +186,23 @@ func (s *SQLiteDatabase) InsertReplacePodcastItem(item *PodcastItem) error {
 	_, err = stmt.Exec(
 		item.ID, item.Title, item.Description, item.Author, item.Thumbnail,
 		item.DurationInMilliseconds, item.VideoURL, item.AudioFilePath, item.CreatedAt.UTC(), item.UpdatedAt.UTC(),
+		string(item.SourceMetadata), storedChapters,
 	)
 	return err
 }
 
 func (s *SQLiteDatabase) GetAllPodcastItems() ([]*PodcastItem, error) {
-	rows, err := s.db.Query(fmt.Sprintf(`SELECT id, title, description, author, thumbnail, duration_in_milliseconds, video_url, audio_file_path, created_at, updated_at FROM %s`, defaultDatabaseName))
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT %s FROM %s`, podcastItemColumns, defaultDatabaseName))
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 	var items []*PodcastItem
 	for rows.Next() {
-		item := &PodcastItem{}
-		err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Author, &item.Thumbnail, &item.DurationInMilliseconds, &item.VideoURL, &item.AudioFilePath, &item.CreatedAt, &item.UpdatedAt)
+		item, err := scanPodcastItem(rows)
 		if err != nil {
 			return nil, err
 		}
-		item.CreatedAt = item.CreatedAt.UTC()
-		item.UpdatedAt = item.UpdatedAt.UTC()
 		items = append(items, item)
 	}
 	return items, nil
@@ -133,24 +222,54 @@ func (m *SQLiteDatabase) DeletePodcastItem(id string) error {
 }
 
 func (s *SQLiteDatabase) GetPodcastItemByID(id string) (*PodcastItem, error) {
-	stmt, err := s.db.Prepare(fmt.Sprintf(`SELECT id, title, description, author, thumbnail, duration_in_milliseconds, video_url, audio_file_path, created_at, updated_at FROM %s WHERE id = ?`, defaultDatabaseName))
+	stmt, err := s.db.Prepare(fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?`, podcastItemColumns, defaultDatabaseName))
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = stmt.Close() }()
-	item := &PodcastItem{}
-	err = stmt.QueryRow(id).Scan(&item.ID, &item.Title, &item.Description, &item.Author, &item.Thumbnail, &item.DurationInMilliseconds, &item.VideoURL, &item.AudioFilePath, &item.CreatedAt, &item.UpdatedAt)
+	item, err := scanPodcastItem(stmt.QueryRow(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("podcast item with id %s not found", id)
+			return nil, fmt.Errorf("podcast item with id %s: %w", id, ErrPodcastItemNotFound)
 		}
 		return nil, err
 	}
+	return item, nil
+}
+
+// scanPodcastItem reads a row selected with podcastItemColumns.
+func scanPodcastItem(row rowScanner) (*PodcastItem, error) {
+	item := &PodcastItem{}
+	var sourceMetadata, storedChapters string
+	err := row.Scan(&item.ID, &item.Title, &item.Description, &item.Author, &item.Thumbnail, &item.DurationInMilliseconds, &item.VideoURL, &item.AudioFilePath, &item.CreatedAt, &item.UpdatedAt, &sourceMetadata, &storedChapters)
+	if err != nil {
+		return nil, err
+	}
 	item.CreatedAt = item.CreatedAt.UTC()
 	item.UpdatedAt = item.UpdatedAt.UTC()
+	if sourceMetadata != "" {
+		item.SourceMetadata = json.RawMessage(sourceMetadata)
+	}
+	if storedChapters != "" {
+		if err := json.Unmarshal([]byte(storedChapters), &item.Chapters); err != nil {
+			return nil, fmt.Errorf("invalid chapters of podcast item %s: %w", item.ID, err)
+		}
+	}
 	return item, nil
 }
 
+// marshalChapters returns the chapters as stored in the chapters column, an empty string if there are none.
+func marshalChapters(itemChapters []chapters.Chapter) (string, error) {
+	if len(itemChapters) == 0 {
+		return "", nil
+	}
+	result, err := json.Marshal(itemChapters)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal chapters: %w", err)
+	}
+	return string(result), nil
+}
+
 // Close closes the database connection.
 func (s *SQLiteDatabase) CloseConnection() error {
 	return s.db.Close()
@@ -1,9 +1,14 @@
 package database
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
 )
 
 const (
@@ -38,6 +43,95 @@ func TestInsertReplacePodcastItem(t *testing.T) {
 	}
 }
 
+func TestInsertReplacePodcastItem_SourceMetadataRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+	withMetadata := getDemoPodcastItem()
+	withMetadata.SourceMetadata = json.RawMessage(`{"channel_id":"UC1","tags":["go"]}`)
+	withoutMetadata := getDemoPodcastItem()
+	withoutMetadata.ID = "other-id"
+
+	for _, item := range []*PodcastItem{withMetadata, withoutMetadata} {
+		if err := db.InsertReplacePodcastItem(item); err != nil {
+			t.Fatalf("failed to create podcast item: %v", err)
+		}
+	}
+
+	fetched, err := db.GetPodcastItemByID(withMetadata.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch podcast item: %v", err)
+	}
+	if string(fetched.SourceMetadata) != string(withMetadata.SourceMetadata) {
+		t.Errorf("expected source metadata %s, got %s", withMetadata.SourceMetadata, fetched.SourceMetadata)
+	}
+	fetched, err = db.GetPodcastItemByID(withoutMetadata.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch podcast item: %v", err)
+	}
+	if fetched.SourceMetadata != nil {
+		t.Errorf("expected no source metadata, got %s", fetched.SourceMetadata)
+	}
+}
+
+func TestInsertReplacePodcastItem_ChaptersRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+	item := getDemoPodcastItem()
+	item.Chapters = []chapters.Chapter{{StartSeconds: 0, EndSeconds: 90.5, Title: "Intro"}, {StartSeconds: 90.5, Title: "Talk"}}
+
+	if err := db.InsertReplacePodcastItem(item); err != nil {
+		t.Fatalf("failed to create podcast item: %v", err)
+	}
+
+	fetched, err := db.GetPodcastItemByID(item.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch podcast item: %v", err)
+	}
+	if !reflect.DeepEqual(fetched.Chapters, item.Chapters) {
+		t.Errorf("expected chapters %+v, got %+v", item.Chapters, fetched.Chapters)
+	}
+}
+
+func TestGetPodcastItemByID_UnknownID_ReturnsNotFound(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	if _, err := db.GetPodcastItemByID("unknown"); !errors.Is(err, ErrPodcastItemNotFound) {
+		t.Errorf("expected ErrPodcastItemNotFound, got %v", err)
+	}
+}
+
+func TestMigratePodcastItemsTable_AddsNewColumns(t *testing.T) {
+	db := NewSQLiteDatabase(testDBFile)
+	sqlDB, err := db.InitializeDatabase()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer cleanupTestDB(t, db)
+
+	// create the table with the schema it had before source metadata was stored
+	createdAt := time.Now().UTC()
+	if _, err := sqlDB.Exec(fmt.Sprintf(`CREATE TABLE %s (id TEXT PRIMARY KEY, title TEXT, description TEXT, author TEXT, thumbnail TEXT, duration_in_milliseconds INTEGER, video_url TEXT, audio_file_path TEXT, created_at DATETIME, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP)`, defaultDatabaseName)); err != nil {
+		t.Fatalf("failed to prepare old schema: %v", err)
+	}
+	if _, err := sqlDB.Exec(fmt.Sprintf(`INSERT INTO %s (id, title, description, author, thumbnail, duration_in_milliseconds, video_url, audio_file_path, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, defaultDatabaseName),
+		testItemID, testTitle, testDesc, testAuthor, testThumb, duration, testVideoURL, testAudio, createdAt, createdAt); err != nil {
+		t.Fatalf("failed to insert old item: %v", err)
+	}
+
+	if err := migratePodcastItemsTable(sqlDB); err != nil {
+		t.Fatalf("failed to migrate table: %v", err)
+	}
+
+	item, err := db.GetPodcastItemByID(testItemID)
+	if err != nil {
+		t.Fatalf("failed to fetch migrated item: %v", err)
+	}
+	if item.Title != testTitle || item.SourceMetadata != nil || item.Chapters != nil {
+		t.Errorf("unexpected migrated item: %+v", item)
+	}
+}
+
 func TestCreatePodcastItemTwice(t *testing.T) {
 	db := setupTestDB(t)
 	defer cleanupTestDB(t, db)
@@ -125,3 +219,217 @@ func cleanupTestDB(t *testing.T, db *SQLiteDatabase) {
 		t.Fatalf("failed to drop database: %v", err)
 	}
 }
+
+func TestInsertAndGetDownloadJob(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	job := NewDownloadJob(testVideoURL)
+	if err := db.InsertDownloadJob(job); err != nil {
+		t.Fatalf("failed to insert download job: %v", err)
+	}
+
+	fetched, err := db.GetDownloadJobByID(job.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch download job: %v", err)
+	}
+	if fetched.URL != testVideoURL {
+		t.Errorf("expected url %q, got %q", testVideoURL, fetched.URL)
+	}
+	if fetched.State != JobStateQueued {
+		t.Errorf("expected state %q, got %q", JobStateQueued, fetched.State)
+	}
+}
+
+func TestUpdateDownloadJob(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	job := NewDownloadJob(testVideoURL)
+	if err := db.InsertDownloadJob(job); err != nil {
+		t.Fatalf("failed to insert download job: %v", err)
+	}
+
+	job.State = JobStateFailed
+	job.PermanentFailure = true
+	if err := db.UpdateDownloadJob(job); err != nil {
+		t.Fatalf("failed to update download job: %v", err)
+	}
+
+	fetched, err := db.GetDownloadJobByID(job.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch download job: %v", err)
+	}
+	if fetched.State != JobStateFailed {
+		t.Errorf("expected state %q, got %q", JobStateFailed, fetched.State)
+	}
+	if !fetched.PermanentFailure {
+		t.Error("expected the permanent failure flag to be stored")
+	}
+}
+
+func TestUpdateDownloadJob_UnknownID_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	if err := db.UpdateDownloadJob(NewDownloadJob(testVideoURL)); err == nil {
+		t.Fatal("expected error when updating a job that does not exist")
+	}
+}
+
+func TestGetDownloadJobsByState(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	queued := NewDownloadJob("http://example.com/queued")
+	downloading := NewDownloadJob("http://example.com/downloading")
+	downloading.State = JobStateDownloading
+	done := NewDownloadJob("http://example.com/done")
+	done.State = JobStateDone
+	for _, job := range []*DownloadJob{queued, downloading, done} {
+		if err := db.InsertDownloadJob(job); err != nil {
+			t.Fatalf("failed to insert download job: %v", err)
+		}
+	}
+
+	jobs, err := db.GetDownloadJobsByState(JobStateQueued, JobStateDownloading)
+	if err != nil {
+		t.Fatalf("failed to query download jobs: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	for _, job := range jobs {
+		if job.ID == done.ID {
+			t.Errorf("did not expect finished job %s in result", done.ID)
+		}
+	}
+}
+
+func TestGetDownloadJobsByDownloadID(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	downloadID := NewDownloadID()
+	first := NewDownloadJob("http://example.com/first")
+	first.DownloadID = downloadID
+	second := NewDownloadJob("http://example.com/second")
+	second.DownloadID = downloadID
+	other := NewDownloadJob("http://example.com/other")
+	for _, job := range []*DownloadJob{first, second, other} {
+		if err := db.InsertDownloadJob(job); err != nil {
+			t.Fatalf("failed to insert download job: %v", err)
+		}
+	}
+
+	jobs, err := db.GetDownloadJobsByDownloadID(downloadID)
+	if err != nil {
+		t.Fatalf("failed to query download jobs: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	for _, job := range jobs {
+		if job.DownloadID != downloadID {
+			t.Errorf("expected download id %q, got %q", downloadID, job.DownloadID)
+		}
+	}
+}
+
+func TestGetDownloadJobsByURL(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	first := NewDownloadJob("http://example.com/first")
+	retried := NewDownloadJob("http://example.com/first")
+	second := NewDownloadJob("http://example.com/second")
+	other := NewDownloadJob("http://example.com/other")
+	for _, job := range []*DownloadJob{first, retried, second, other} {
+		if err := db.InsertDownloadJob(job); err != nil {
+			t.Fatalf("failed to insert download job: %v", err)
+		}
+	}
+
+	jobs, err := db.GetDownloadJobsByURL(first.URL, second.URL)
+	if err != nil {
+		t.Fatalf("failed to query download jobs: %v", err)
+	}
+	if len(jobs) != 3 {
+		t.Fatalf("expected 3 jobs, got %d", len(jobs))
+	}
+	for _, job := range jobs {
+		if job.ID == other.ID {
+			t.Errorf("did not expect job %s of another url in result", other.ID)
+		}
+	}
+}
+
+func TestDownloadJob_NextCheckAtRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	job := NewDownloadJob(testVideoURL)
+	if err := db.InsertDownloadJob(job); err != nil {
+		t.Fatalf("failed to insert download job: %v", err)
+	}
+	fetched, err := db.GetDownloadJobByID(job.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch download job: %v", err)
+	}
+	if !fetched.NextCheckAt.IsZero() {
+		t.Errorf("expected zero next check time, got %v", fetched.NextCheckAt)
+	}
+
+	job.State = JobStateWaiting
+	job.NextCheckAt = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := db.UpdateDownloadJob(job); err != nil {
+		t.Fatalf("failed to update download job: %v", err)
+	}
+	fetched, err = db.GetDownloadJobByID(job.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch download job: %v", err)
+	}
+	if !fetched.NextCheckAt.Equal(job.NextCheckAt) {
+		t.Errorf("expected next check time %v, got %v", job.NextCheckAt, fetched.NextCheckAt)
+	}
+}
+
+func TestSubscriptions_InsertUpdateDelete(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	subscription := NewSubscription("https://www.youtube.com/playlist?list=abc", 5)
+	if err := db.InsertSubscription(subscription); err != nil {
+		t.Fatalf("failed to insert subscription: %v", err)
+	}
+	if err := db.InsertSubscription(NewSubscription(subscription.URL, 0)); err == nil {
+		t.Error("expected error when subscribing to the same url twice")
+	}
+
+	subscription.LastCheckedAt = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	subscription.LastError = "failed to list videos"
+	if err := db.UpdateSubscription(subscription); err != nil {
+		t.Fatalf("failed to update subscription: %v", err)
+	}
+	fetched, err := db.GetSubscriptionByID(subscription.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch subscription: %v", err)
+	}
+	if !fetched.LastCheckedAt.Equal(subscription.LastCheckedAt) || fetched.LastError != subscription.LastError || fetched.Latest != 5 {
+		t.Errorf("unexpected subscription after update: %+v", fetched)
+	}
+
+	if err := db.DeleteSubscription(subscription.ID); err != nil {
+		t.Fatalf("failed to delete subscription: %v", err)
+	}
+	subscriptions, err := db.GetAllSubscriptions()
+	if err != nil {
+		t.Fatalf("failed to list subscriptions: %v", err)
+	}
+	if len(subscriptions) != 0 {
+		t.Errorf("expected no subscriptions after delete, got %d", len(subscriptions))
+	}
+	if err := db.DeleteSubscription(subscription.ID); err == nil {
+		t.Error("expected error when deleting an unknown subscription")
+	}
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const downloadJobsTableName = "download_jobs"
+
+const downloadJobColumns = `id, download_id, url, state, attempts, last_error, permanent_failure, progress, next_check_at, created_at, updated_at`
+
+func createDownloadJobsTable(db *sql.DB) error {
+	createTableStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		download_id TEXT NOT NULL DEFAULT '',
+		url TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		permanent_failure INTEGER NOT NULL DEFAULT 0,
+		progress REAL NOT NULL DEFAULT 0,
+		next_check_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00',
+		created_at DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, downloadJobsTableName)
+	_, err := db.Exec(createTableStmt)
+	return err
+}
+
+func (s *SQLiteDatabase) InsertDownloadJob(job *DownloadJob) error {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, downloadJobsTableName, downloadJobColumns))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	_, err = stmt.Exec(job.ID, job.DownloadID, job.URL, string(job.State), job.Attempts, job.LastError, job.PermanentFailure, job.Progress, job.NextCheckAt.UTC(), job.CreatedAt.UTC(), job.UpdatedAt.UTC())
+	return err
+}
+
+func (s *SQLiteDatabase) UpdateDownloadJob(job *DownloadJob) error {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`UPDATE %s SET download_id = ?, url = ?, state = ?, attempts = ?, last_error = ?, permanent_failure = ?, progress = ?, next_check_at = ?, updated_at = ? WHERE id = ?`, downloadJobsTableName))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	result, err := stmt.Exec(job.DownloadID, job.URL, string(job.State), job.Attempts, job.LastError, job.PermanentFailure, job.Progress, job.NextCheckAt.UTC(), job.UpdatedAt.UTC(), job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update download job with id %s: %w", job.ID, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("download job with id %s not found", job.ID)
+	}
+	return nil
+}
+
+func (s *SQLiteDatabase) GetDownloadJobByID(id string) (*DownloadJob, error) {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?`, downloadJobColumns, downloadJobsTableName))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	job, err := scanDownloadJob(stmt.QueryRow(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("download job with id %s not found", id)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *SQLiteDatabase) GetAllDownloadJobs() ([]*DownloadJob, error) {
+	return s.queryDownloadJobs(fmt.Sprintf(`SELECT %s FROM %s ORDER BY created_at`, downloadJobColumns, downloadJobsTableName))
+}
+
+// GetDownloadJobsByState returns all jobs in any of the given states, oldest first.
+func (s *SQLiteDatabase) GetDownloadJobsByState(states ...JobState) ([]*DownloadJob, error) {
+	if len(states) == 0 {
+		return []*DownloadJob{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(states)), ", ")
+	args := make([]any, 0, len(states))
+	for _, state := range states {
+		args = append(args, string(state))
+	}
+	return s.queryDownloadJobs(fmt.Sprintf(`SELECT %s FROM %s WHERE state IN (%s) ORDER BY created_at`, downloadJobColumns, downloadJobsTableName, placeholders), args...)
+}
+
+// GetDownloadJobsByDownloadID returns all jobs that were created for the same submitted URL, oldest first.
+func (s *SQLiteDatabase) GetDownloadJobsByDownloadID(downloadID string) ([]*DownloadJob, error) {
+	return s.queryDownloadJobs(fmt.Sprintf(`SELECT %s FROM %s WHERE download_id = ? ORDER BY created_at`, downloadJobColumns, downloadJobsTableName), downloadID)
+}
+
+// GetDownloadJobsByURL returns all jobs for any of the given video URLs, oldest first.
+func (s *SQLiteDatabase) GetDownloadJobsByURL(urls ...string) ([]*DownloadJob, error) {
+	if len(urls) == 0 {
+		return []*DownloadJob{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(urls)), ", ")
+	args := make([]any, 0, len(urls))
+	for _, url := range urls {
+		args = append(args, url)
+	}
+	return s.queryDownloadJobs(fmt.Sprintf(`SELECT %s FROM %s WHERE url IN (%s) ORDER BY created_at`, downloadJobColumns, downloadJobsTableName, placeholders), args...)
+}
+
+func (s *SQLiteDatabase) queryDownloadJobs(query string, args ...any) ([]*DownloadJob, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	jobs := make([]*DownloadJob, 0)
+	for rows.Next() {
+		job, err := scanDownloadJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDownloadJob(row rowScanner) (*DownloadJob, error) {
+	job := &DownloadJob{}
+	var state string
+	if err := row.Scan(&job.ID, &job.DownloadID, &job.URL, &state, &job.Attempts, &job.LastError, &job.PermanentFailure, &job.Progress, &job.NextCheckAt, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return nil, err
+	}
+	job.State = JobState(state)
+	job.NextCheckAt = job.NextCheckAt.UTC()
+	job.CreatedAt = job.CreatedAt.UTC()
+	job.UpdatedAt = job.UpdatedAt.UTC()
+	return job, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const subscriptionsTableName = "subscriptions"
+
+const subscriptionColumns = `id, url, latest, last_checked_at, last_error, created_at, updated_at`
+
+func createSubscriptionsTable(db *sql.DB) error {
+	createTableStmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL UNIQUE,
+		latest INTEGER NOT NULL DEFAULT 0,
+		last_checked_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00',
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, subscriptionsTableName)
+	_, err := db.Exec(createTableStmt)
+	return err
+}
+
+func (s *SQLiteDatabase) InsertSubscription(subscription *Subscription) error {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?, ?, ?)`, subscriptionsTableName, subscriptionColumns))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	_, err = stmt.Exec(subscription.ID, subscription.URL, subscription.Latest, subscription.LastCheckedAt.UTC(), subscription.LastError, subscription.CreatedAt.UTC(), subscription.UpdatedAt.UTC())
+	return err
+}
+
+func (s *SQLiteDatabase) UpdateSubscription(subscription *Subscription) error {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`UPDATE %s SET url = ?, latest = ?, last_checked_at = ?, last_error = ?, updated_at = ? WHERE id = ?`, subscriptionsTableName))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	result, err := stmt.Exec(subscription.URL, subscription.Latest, subscription.LastCheckedAt.UTC(), subscription.LastError, subscription.UpdatedAt.UTC(), subscription.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription with id %s: %w", subscription.ID, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("subscription with id %s not found", subscription.ID)
+	}
+	return nil
+}
+
+func (s *SQLiteDatabase) GetSubscriptionByID(id string) (*Subscription, error) {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?`, subscriptionColumns, subscriptionsTableName))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	subscription, err := scanSubscription(stmt.QueryRow(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription with id %s not found", id)
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetAllSubscriptions returns all subscriptions, oldest first.
+func (s *SQLiteDatabase) GetAllSubscriptions() ([]*Subscription, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT %s FROM %s ORDER BY created_at`, subscriptionColumns, subscriptionsTableName))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	subscriptions := make([]*Subscription, 0)
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (s *SQLiteDatabase) DeleteSubscription(id string) error {
+	stmt, err := s.db.Prepare(fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, subscriptionsTableName))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	result, err := stmt.Exec(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription with id %s: %w", id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("subscription with id %s not found", id)
+	}
+	return nil
+}
+
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	subscription := &Subscription{}
+	if err := row.Scan(&subscription.ID, &subscription.URL, &subscription.Latest, &subscription.LastCheckedAt, &subscription.LastError, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+		return nil, err
+	}
+	subscription.LastCheckedAt = subscription.LastCheckedAt.UTC()
+	subscription.CreatedAt = subscription.CreatedAt.UTC()
+	subscription.UpdatedAt = subscription.UpdatedAt.UTC()
+	return subscription, nil
+}
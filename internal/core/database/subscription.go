@@ -0,0 +1,26 @@
+package database
+
+import "time"
+
+// Subscription is a channel or playlist URL that is polled periodically for new videos.
+type Subscription struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Latest        int       `json:"latest,omitempty"`         // number of newest videos checked on every poll, all videos if 0
+	LastCheckedAt time.Time `json:"last_checked_at,omitzero"` // when the subscription was last polled
+	LastError     string    `json:"last_error,omitempty"`     // error of the last poll, empty if it succeeded
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewSubscription creates a subscription with a random identifier for the given channel or playlist URL.
+func NewSubscription(url string, latest int) *Subscription {
+	now := time.Now().UTC()
+	return &Subscription{
+		ID:        newRandomID(),
+		URL:       url,
+		Latest:    latest,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
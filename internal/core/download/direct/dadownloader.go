@@ -0,0 +1,333 @@
+package direct
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/convertvideo"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+)
+
+// mediaExtensions are the file extensions of supported media links; all but the configured audio format are converted to it.
+var mediaExtensions = map[string]bool{
+	".mp4":  true,
+	".webm": true,
+	".mkv":  true,
+	".mp3":  true,
+	".m4a":  true,
+}
+
+// DirectAudioDownloader downloads plain HTTP(S) links to media files and converts video files to audio with ffmpeg.
+type DirectAudioDownloader struct {
+	mediaConfig *config.Media
+	client      *http.Client
+}
+
+func NewDirectAudioDownloader(mediaConfig *config.Media) *DirectAudioDownloader {
+	return &DirectAudioDownloader{
+		mediaConfig: mediaConfig,
+		client:      http.DefaultClient,
+	}
+}
+
+// IsVideoSupported reports whether the URL links to a media file with a supported extension.
+func (d *DirectAudioDownloader) IsVideoSupported(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return false
+	}
+	return IsMediaFilePath(parsed.Path)
+}
+
+// IsMediaFilePath reports whether the path of a URL names a media file supported by the direct downloader.
+func IsMediaFilePath(urlPath string) bool {
+	return mediaExtensions[strings.ToLower(path.Ext(urlPath))]
+}
+
+// NormalizeVideoURL returns the URL without fragment, which is never sent to the server.
+func (d *DirectAudioDownloader) NormalizeVideoURL(rawURL string) string {
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	return rawURL
+}
+
+// CheckVideoAvailability requests the headers of the file. Missing files are permanent failures.
+func (d *DirectAudioDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	slog.Info("checking media file availability", "url", url)
+	_, err := d.head(ctx, url)
+	return err
+}
+
+// IsVideoList returns false, a media file is always a single video.
+func (d *DirectAudioDownloader) IsVideoList(url string) bool {
+	return false
+}
+
+// ListIndividualVideoURLs returns a slice containing the original URL, a media file is always a single video.
+func (d *DirectAudioDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	return []string{url}, nil
+}
+
+// GetVideoInfo returns the metadata of the file derived from its URL and headers. The duration is unknown before downloading.
+func (d *DirectAudioDownloader) GetVideoInfo(ctx context.Context, url string) (*downloader.VideoInfo, error) {
+	header, err := d.head(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	info := &downloader.VideoInfo{
+		URL:     url,
+		ID:      fileID(url),
+		Title:   fileTitle(url, header),
+		Channel: d.feedName(url),
+	}
+	if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		info.UploadedAt = lastModified.UTC()
+	}
+	return info, nil
+}
+
+func (d *DirectAudioDownloader) Download(ctx context.Context, url string, targetPath string, progress downloader.ProgressFunc) (string, error) {
+	tempPath, err := os.MkdirTemp(d.mediaConfig.TempPath, "direct-download-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempPath); err != nil {
+			slog.Warn("error removing temp directory", "err", err)
+		}
+	}()
+
+	slog.Info("downloading", "url", url, "tempPath", tempPath)
+	mediaFilePath, header, err := d.Fetch(ctx, tempPath, url, progress)
+	if err != nil {
+		return "", err
+	}
+
+	// the directory of the audio file is the feed it is added to
+	feedPath := filepath.Join(tempPath, d.feedName(url))
+	if err := os.MkdirAll(feedPath, os.ModePerm); err != nil {
+		return "", err
+	}
+	audioFormat := filemanagement.AudioFormatByName(d.mediaConfig.Audio.Format)
+	filePath := filepath.Join(feedPath, sanitizeFileName(fileTitle(url, header))+"_"+fileID(url)+audioFormat.Extension)
+	if strings.EqualFold(filepath.Ext(mediaFilePath), audioFormat.Extension) {
+		err = os.Rename(mediaFilePath, filePath)
+	} else {
+		slog.Info("converting media file to audio", "mediaFilePath", mediaFilePath, "format", audioFormat.Name)
+		err = convertvideo.ConvertVideoToAudio(mediaFilePath, filePath, &d.mediaConfig.Audio)
+	}
+	if err != nil {
+		return "", err
+	}
+	slog.Info("done downloading file", "filePath", filePath)
+
+	progress.Report(downloader.StageTagging, 100)
+	slog.Info("setting metadata", "filePath", filePath)
+	if err = d.setMetadata(filePath, url, header); err != nil {
+		return "", err
+	}
+	slog.Info("set metadata", "filePath", filePath)
+
+	progress.Report(downloader.StageMoving, 100)
+	slog.Info("moving file to target folder")
+	result, err := filemanagement.MoveToTarget(filePath, targetPath)
+	if err != nil {
+		return "", err
+	}
+	slog.Info("completed moving file", "targetPath", result)
+
+	return result, nil
+}
+
+// Fetch downloads the file into targetDirectory and returns its path and the response headers.
+// The file is named media with the extension of the Content-Disposition file name or the URL.
+func (d *DirectAudioDownloader) Fetch(ctx context.Context, targetDirectory string, url string, progress downloader.ProgressFunc) (string, http.Header, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	response, err := d.client.Do(request)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			slog.Warn("error closing response body", "err", err)
+		}
+	}()
+	if err := statusError(url, response.StatusCode); err != nil {
+		return "", nil, err
+	}
+
+	filePath := filepath.Join(targetDirectory, "media"+mediaExtension(url, response.Header))
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("error closing media file", "err", err)
+		}
+	}()
+
+	writer := &progressWriter{total: response.ContentLength, progress: progress}
+	if _, err := io.Copy(io.MultiWriter(file, writer), response.Body); err != nil {
+		return "", nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	progress.Report(downloader.StageDownloading, 100)
+	return filePath, response.Header, nil
+}
+
+// head returns the headers of the file. Servers that do not support HEAD requests are asked for the first byte instead.
+func (d *DirectAudioDownloader) head(ctx context.Context, url string) (http.Header, error) {
+	response, err := d.request(ctx, http.MethodHead, url)
+	if err == nil && (response.StatusCode == http.StatusMethodNotAllowed || response.StatusCode == http.StatusNotImplemented) {
+		response, err = d.request(ctx, http.MethodGet, url)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", url, err)
+	}
+	if err := statusError(url, response.StatusCode); err != nil {
+		return nil, err
+	}
+	return response.Header, nil
+}
+
+func (d *DirectAudioDownloader) request(ctx context.Context, method string, url string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		request.Header.Set("Range", "bytes=0-0")
+	}
+	response, err := d.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := response.Body.Close(); err != nil {
+		slog.Warn("error closing response body", "err", err)
+	}
+	return response, nil
+}
+
+// statusError maps unsuccessful HTTP status codes to errors. Missing files fail permanently, other errors are retried.
+func statusError(url string, statusCode int) error {
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return nil
+	case statusCode == http.StatusNotFound || statusCode == http.StatusGone:
+		return fmt.Errorf("%w: media file %s returned HTTP %d", downloader.ErrPermanentFailure, url, statusCode)
+	default:
+		return fmt.Errorf("media file %s returned HTTP %d", url, statusCode)
+	}
+}
+
+func (d *DirectAudioDownloader) setMetadata(fullFilePath string, sourceURL string, header http.Header) error {
+	metadata, err := downloader.GetTags(fullFilePath)
+	if err != nil {
+		return err
+	}
+
+	// container tags take precedence over values derived from the URL and headers
+	if metadata[downloader.Title] == "" {
+		metadata[downloader.Title] = fileTitle(sourceURL, header)
+	}
+	if metadata[downloader.Artist] == "" {
+		metadata[downloader.Artist] = d.feedName(sourceURL)
+	}
+	description := metadata["description"]
+	if description == "" {
+		description = metadata["comment"]
+	}
+	metadata[downloader.PodcastDescriptionTag] = strings.ReplaceAll(description, "\n", "<br>")
+	if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil && metadata["date"] == "" {
+		metadata["date"] = lastModified.UTC().Format("2006-01-02T15:04:05")
+	}
+	metadata[downloader.DateTag] = metadata["date"]
+	metadata[downloader.VideoDownloadLink] = sourceURL
+
+	return downloader.SetTags(fullFilePath, metadata)
+}
+
+// feedName returns the configured feed, or the host of the URL if none is configured.
+func (d *DirectAudioDownloader) feedName(rawURL string) string {
+	if d.mediaConfig != nil && d.mediaConfig.DirectMediaFeed != "" {
+		return sanitizeFileName(d.mediaConfig.DirectMediaFeed)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return "direct"
+	}
+	return sanitizeFileName(parsed.Hostname())
+}
+
+// fileTitle returns the file name of the Content-Disposition header or the URL without extension.
+func fileTitle(rawURL string, header http.Header) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		if parsed, err := url.Parse(rawURL); err == nil {
+			name = path.Base(parsed.Path)
+		}
+	}
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// mediaExtension returns the extension of the Content-Disposition file name or the URL, which decides whether the file is converted.
+func mediaExtension(rawURL string, header http.Header) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		if extension := strings.ToLower(path.Ext(params["filename"])); mediaExtensions[extension] {
+			return extension
+		}
+	}
+	if parsed, err := url.Parse(rawURL); err == nil {
+		return strings.ToLower(path.Ext(parsed.Path))
+	}
+	return ""
+}
+
+// fileID identifies the file by its URL, so that files with the same name from different links do not overwrite each other.
+func fileID(rawURL string) string {
+	hash := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(hash[:6])
+}
+
+func sanitizeFileName(name string) string {
+	return filemanagement.SanitizeFileName(name, "media")
+}
+
+// progressWriter reports the download progress of a response with a known length in whole percent steps.
+type progressWriter struct {
+	total       int64
+	written     int64
+	progress    downloader.ProgressFunc
+	lastPercent float64
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if w.total <= 0 {
+		return len(p), nil
+	}
+	if percent := float64(w.written) * 100 / float64(w.total); percent-w.lastPercent >= 1 {
+		w.lastPercent = percent
+		w.progress.Report(downloader.StageDownloading, percent)
+	}
+	return len(p), nil
+}
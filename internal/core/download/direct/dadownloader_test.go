@@ -0,0 +1,124 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+func TestDirectAudioDownloader_IsVideoSupported(t *testing.T) {
+	d := NewDirectAudioDownloader(nil)
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "video file", url: "https://example.com/talks/keynote.mp4", want: true},
+		{name: "audio file with query", url: "http://example.com/episode.MP3?token=abc", want: true},
+		{name: "matroska file", url: "https://example.com/recording.mkv", want: true},
+		{name: "web page", url: "https://example.com/talks/keynote.html", want: false},
+		{name: "extension only in query", url: "https://example.com/download?file=keynote.mp4", want: false},
+		{name: "unsupported scheme", url: "ftp://example.com/keynote.mp4", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.IsVideoSupported(tt.url); got != tt.want {
+				t.Errorf("DirectAudioDownloader.IsVideoSupported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectAudioDownloader_CheckVideoAvailability(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/available.mp4", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/no-head.mp4", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/busy.mp4", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	d := NewDirectAudioDownloader(nil)
+
+	if err := d.CheckVideoAvailability(context.Background(), server.URL+"/available.mp4"); err != nil {
+		t.Errorf("expected available file, got %v", err)
+	}
+	if err := d.CheckVideoAvailability(context.Background(), server.URL+"/no-head.mp4"); err != nil {
+		t.Errorf("expected a GET request as fallback for servers without HEAD support, got %v", err)
+	}
+	if err := d.CheckVideoAvailability(context.Background(), server.URL+"/missing.mp4"); !errors.Is(err, downloader.ErrPermanentFailure) {
+		t.Errorf("expected a permanent failure for a missing file, got %v", err)
+	}
+	err := d.CheckVideoAvailability(context.Background(), server.URL+"/busy.mp4")
+	if err == nil || errors.Is(err, downloader.ErrPermanentFailure) {
+		t.Errorf("expected a transient failure for an unavailable server, got %v", err)
+	}
+}
+
+func TestDirectAudioDownloader_GetVideoInfo(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="Keynote 2024.mp4"`)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	d := NewDirectAudioDownloader(&config.Media{DirectMediaFeed: "Conference Talks"})
+	info, err := d.GetVideoInfo(context.Background(), server.URL+"/download/123.mp4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Title != "Keynote 2024" || info.Channel != "Conference Talks" || !info.UploadedAt.Equal(lastModified) {
+		t.Errorf("unexpected video info %+v", info)
+	}
+}
+
+func TestDirectAudioDownloader_Fetch(t *testing.T) {
+	content := []byte("not really a video, but the download does not care")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	d := NewDirectAudioDownloader(nil)
+	var lastPercent float64
+	progress := func(stage downloader.Stage, percent float64) { lastPercent = percent }
+	filePath, _, err := d.Fetch(context.Background(), t.TempDir(), server.URL+"/talk.webm", progress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filepath.Ext(filePath) != ".webm" {
+		t.Errorf("expected the media file to keep its extension, got %s", filePath)
+	}
+	got, err := os.ReadFile(filePath)
+	if err != nil || string(got) != string(content) {
+		t.Errorf("expected downloaded content %q, got %q (%v)", content, got, err)
+	}
+	if lastPercent != 100 {
+		t.Errorf("expected progress to reach 100, got %v", lastPercent)
+	}
+}
+
+func TestDirectAudioDownloader_FeedName(t *testing.T) {
+	if got := NewDirectAudioDownloader(&config.Media{}).feedName("https://media.example.org/a.mp4"); got != "media.example.org" {
+		t.Errorf("expected the host as feed, got %s", got)
+	}
+	if got := NewDirectAudioDownloader(&config.Media{DirectMediaFeed: "../talks"}).feedName("https://media.example.org/a.mp4"); got != "_talks" {
+		t.Errorf("expected a sanitized configured feed, got %s", got)
+	}
+}
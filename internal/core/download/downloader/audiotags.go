@@ -0,0 +1,145 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	mp3joiner "github.com/jo-hoe/mp3-joiner"
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+)
+
+// tagProbeTimeout limits reading the tags of an audio file that is not an MP3 file.
+const tagProbeTimeout = 30 * time.Second
+
+// frameTagKeys are the tags named after ID3 frames. Containers other than MP3 report tag keys in their own case,
+// e.g. Vorbis comments in upper case, so keys are normalized to these and to lower case otherwise.
+var frameTagKeys = []string{ThumbnailUrlTag, PodcastDescriptionTag, DateTag}
+
+// GetTags returns the tags of an audio file in any of the supported audio formats.
+func GetTags(audioFilePath string) (map[string]string, error) {
+	if isMP3(audioFilePath) {
+		return mp3joiner.GetFFmpegMetadataTag(audioFilePath)
+	}
+	probed, err := probeAudioFile(audioFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	// Ogg files carry their comments on the stream, the container tags take precedence
+	for _, stream := range probed.Streams {
+		addNormalizedTags(tags, stream.Tags)
+	}
+	addNormalizedTags(tags, probed.Format.Tags)
+	return tags, nil
+}
+
+// SetTags replaces the tags of an audio file in any of the supported audio formats. Embedded chapters are kept.
+func SetTags(audioFilePath string, tags map[string]string) error {
+	if isMP3(audioFilePath) {
+		chapters, err := mp3joiner.GetChapterMetadata(audioFilePath)
+		if err != nil {
+			return err
+		}
+		return mp3joiner.SetFFmpegMetadataTag(audioFilePath, tags, chapters)
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	metadata := make([]string, 0, len(keys))
+	for _, key := range keys {
+		metadata = append(metadata, key+"="+tags[key])
+	}
+	kwArgs := ffmpeg.KwArgs{"map": "0:a", "c": "copy", "metadata": metadata}
+	switch format, _ := filemanagement.AudioFormatOfFile(audioFilePath); format.Name {
+	case config.AudioFormatM4A:
+		// MP4 only stores a fixed set of tags unless told otherwise
+		kwArgs["movflags"] = "use_metadata_tags"
+	case config.AudioFormatOpus:
+		// Ogg keeps the comments on the stream, replace the copied ones as well
+		kwArgs["metadata:s:a:0"] = metadata
+	}
+
+	// ffmpeg cannot edit in place, write a copy next to the file and replace it
+	extension := filepath.Ext(audioFilePath)
+	taggedFilePath := strings.TrimSuffix(audioFilePath, extension) + ".tagged" + extension
+	if err := ffmpeg.Input(audioFilePath).Output(taggedFilePath, kwArgs).OverWriteOutput().Silent(true).Run(); err != nil {
+		_ = os.Remove(taggedFilePath)
+		return fmt.Errorf("could not write tags of %s: %w", audioFilePath, err)
+	}
+	return os.Rename(taggedFilePath, audioFilePath)
+}
+
+// GetLengthInSeconds returns the duration of an audio file in any of the supported audio formats.
+func GetLengthInSeconds(audioFilePath string) (float64, error) {
+	if isMP3(audioFilePath) {
+		return mp3joiner.GetLengthInSeconds(audioFilePath)
+	}
+	probed, err := probeAudioFile(audioFilePath)
+	if err != nil {
+		return 0, err
+	}
+	length, err := strconv.ParseFloat(probed.Format.Duration, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q of %s: %w", probed.Format.Duration, audioFilePath, err)
+	}
+	return length, nil
+}
+
+// ffprobeAudioFile holds the fields of ffprobe -show_format -show_streams output that tags are read from.
+type ffprobeAudioFile struct {
+	Format struct {
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []struct {
+		Tags map[string]string `json:"tags"`
+	} `json:"streams"`
+}
+
+func probeAudioFile(audioFilePath string) (*ffprobeAudioFile, error) {
+	output, err := ffmpeg.ProbeWithTimeoutExec(audioFilePath, tagProbeTimeout, ffmpeg.KwArgs{"show_format": "", "show_streams": "", "of": "json"})
+	if err != nil {
+		return nil, fmt.Errorf("could not read tags of %s: %w", audioFilePath, err)
+	}
+	return parseFFprobeAudioFile([]byte(output))
+}
+
+func parseFFprobeAudioFile(output []byte) (*ffprobeAudioFile, error) {
+	var probed ffprobeAudioFile
+	if err := json.Unmarshal(output, &probed); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	return &probed, nil
+}
+
+func addNormalizedTags(tags map[string]string, probedTags map[string]string) {
+	for key, value := range probedTags {
+		tags[normalizeTagKey(key)] = value
+	}
+}
+
+func normalizeTagKey(key string) string {
+	for _, frameKey := range frameTagKeys {
+		if strings.EqualFold(key, frameKey) {
+			return frameKey
+		}
+	}
+	return strings.ToLower(key)
+}
+
+func isMP3(audioFilePath string) bool {
+	format, ok := filemanagement.AudioFormatOfFile(audioFilePath)
+	return !ok || format.Name == config.AudioFormatMP3
+}
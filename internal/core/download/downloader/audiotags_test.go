@@ -0,0 +1,42 @@
+package downloader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFFprobeAudioFile_NormalizesTags(t *testing.T) {
+	output := []byte(`{
+		"streams": [{"tags": {"TITLE": "stream title", "PURL": "https://example.com/watch", "tdes": "a description"}}],
+		"format": {"duration": "12.5", "tags": {"title": "container title", "WXXX": "https://example.com/thumb.jpg"}}
+	}`)
+
+	probed, err := parseFFprobeAudioFile(output)
+	if err != nil {
+		t.Fatalf("parseFFprobeAudioFile() unexpected error: %v", err)
+	}
+	if probed.Format.Duration != "12.5" {
+		t.Errorf("duration = %q, want %q", probed.Format.Duration, "12.5")
+	}
+
+	tags := make(map[string]string)
+	for _, stream := range probed.Streams {
+		addNormalizedTags(tags, stream.Tags)
+	}
+	addNormalizedTags(tags, probed.Format.Tags)
+	want := map[string]string{
+		Title:                 "container title",
+		VideoURLID3Key:        "https://example.com/watch",
+		PodcastDescriptionTag: "a description",
+		ThumbnailUrlTag:       "https://example.com/thumb.jpg",
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+}
+
+func TestParseFFprobeAudioFile_InvalidOutput(t *testing.T) {
+	if _, err := parseFFprobeAudioFile([]byte("not json")); err == nil {
+		t.Errorf("expected an error for invalid output")
+	}
+}
@@ -0,0 +1,126 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPermanentFailure marks download errors that will not go away by retrying,
+// e.g. because the video is private or was removed.
+var ErrPermanentFailure = errors.New("permanent download failure")
+
+// FailureKind tells whether a failed yt-dlp run is worth retrying.
+type FailureKind string
+
+const (
+	FailureUnknown   FailureKind = "unknown"
+	FailureTransient FailureKind = "transient"
+	FailurePermanent FailureKind = "permanent"
+)
+
+// permanentFailureMarkers are lower-case fragments of yt-dlp error messages for videos that can never be downloaded.
+// They quote specific messages, since generic ones like "Video unavailable" are also shown while being throttled.
+var permanentFailureMarkers = []string{
+	"private video",
+	"video is private",
+	"this video has been removed",
+	"this video is no longer available",
+	"account associated with this video has been terminated",
+	"blocked it on copyright grounds",
+	"members-only",
+	"join this channel to get access",
+	"sign in to confirm your age",
+	"unsupported url",
+	"this video does not exist",
+}
+
+// retryHintMarkers are lower-case fragments of yt-dlp error messages that ask for a retry.
+// They keep a failure transient even if the message also matches a permanent marker.
+var retryHintMarkers = []string{
+	"try again later",
+}
+
+// transientFailureMarkers are lower-case fragments of yt-dlp error messages for failures that usually pass on retry.
+var transientFailureMarkers = []string{
+	"http error 403",
+	"http error 429",
+	"too many requests",
+	"http error 5",
+	"fragment",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"temporary failure in name resolution",
+	"network is unreachable",
+	"remote end closed connection",
+	"incomplete read",
+}
+
+// ClassifyYtDlpOutput inspects the stderr output of a failed yt-dlp run.
+// Permanent markers win over transient ones, since a removed video may also produce follow-up HTTP errors,
+// unless the error line asks for a retry.
+func ClassifyYtDlpOutput(stderr string) FailureKind {
+	errorLines := make([]string, 0)
+	for _, line := range strings.Split(strings.ToLower(stderr), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "error:") {
+			errorLines = append(errorLines, line)
+		}
+	}
+	// fall back to the whole output if yt-dlp did not print a dedicated error line
+	if len(errorLines) == 0 {
+		errorLines = append(errorLines, strings.ToLower(stderr))
+	}
+
+	kind := FailureUnknown
+	for _, line := range errorLines {
+		if containsAny(line, retryHintMarkers) {
+			kind = FailureTransient
+			continue
+		}
+		if containsAny(line, permanentFailureMarkers) {
+			return FailurePermanent
+		}
+		if containsAny(line, transientFailureMarkers) {
+			kind = FailureTransient
+		}
+	}
+	return kind
+}
+
+// NewYtDlpError wraps the error of a failed yt-dlp run. Permanent failures wrap ErrPermanentFailure
+// and carry the yt-dlp error message so callers can report why the download failed.
+func NewYtDlpError(err error, stderr string) error {
+	message := lastErrorLine(stderr)
+	switch ClassifyYtDlpOutput(stderr) {
+	case FailurePermanent:
+		return fmt.Errorf("%w: %s: %w", ErrPermanentFailure, message, err)
+	case FailureTransient:
+		return fmt.Errorf("yt-dlp command failed (transient): %s: %w", message, err)
+	default:
+		if message == "" {
+			return fmt.Errorf("yt-dlp command failed: %w", err)
+		}
+		return fmt.Errorf("yt-dlp command failed: %s: %w", message, err)
+	}
+}
+
+func lastErrorLine(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(strings.ToLower(line), "error:") {
+			return line
+		}
+	}
+	return ""
+}
+
+func containsAny(line string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(line, marker) {
+			return true
+		}
+	}
+	return false
+}
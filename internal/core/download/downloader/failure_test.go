@@ -0,0 +1,97 @@
+package downloader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClassifyYtDlpOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   FailureKind
+	}{
+		{
+			name:   "private video",
+			stderr: "ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video",
+			want:   FailurePermanent,
+		},
+		{
+			name:   "removed video",
+			stderr: "ERROR: [youtube] abc: This video has been removed by the uploader",
+			want:   FailurePermanent,
+		},
+		{
+			name:   "copyright claim",
+			stderr: "ERROR: [youtube] abc: Video unavailable. This video contains content from SME, who has blocked it on copyright grounds",
+			want:   FailurePermanent,
+		},
+		{
+			name:   "throttled",
+			stderr: "ERROR: [youtube] abc: Video unavailable. This content isn't available, try again later.",
+			want:   FailureTransient,
+		},
+		{
+			name:   "generic unavailable message",
+			stderr: "ERROR: [youtube] abc: Video unavailable",
+			want:   FailureUnknown,
+		},
+		{
+			name:   "forbidden fragment",
+			stderr: "WARNING: something\nERROR: unable to download video data: HTTP Error 403: Forbidden",
+			want:   FailureTransient,
+		},
+		{
+			name:   "rate limited",
+			stderr: "ERROR: [youtube] abc: HTTP Error 429: Too Many Requests",
+			want:   FailureTransient,
+		},
+		{
+			name:   "network error",
+			stderr: "ERROR: [youtube] abc: Unable to download webpage: <urlopen error [Errno 110] Connection timed out>",
+			want:   FailureTransient,
+		},
+		{
+			name:   "warnings do not classify the error",
+			stderr: "WARNING: video is private on another mirror\nERROR: something unexpected happened",
+			want:   FailureUnknown,
+		},
+		{
+			name:   "empty output",
+			stderr: "",
+			want:   FailureUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyYtDlpOutput(tt.stderr); got != tt.want {
+				t.Errorf("ClassifyYtDlpOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewYtDlpError_PermanentWrapsErrPermanentFailure(t *testing.T) {
+	cause := errors.New("exit status 1")
+	err := NewYtDlpError(cause, "ERROR: [youtube] abc: Private video")
+
+	if !errors.Is(err, ErrPermanentFailure) {
+		t.Errorf("expected ErrPermanentFailure, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected original error to be wrapped, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Private video") {
+		t.Errorf("expected yt-dlp message in error, got %q", err.Error())
+	}
+}
+
+func TestNewYtDlpError_TransientIsNotPermanent(t *testing.T) {
+	err := NewYtDlpError(errors.New("exit status 1"), "ERROR: HTTP Error 429: Too Many Requests")
+
+	if errors.Is(err, ErrPermanentFailure) {
+		t.Errorf("did not expect ErrPermanentFailure for transient error, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// MockExecutor is a fake yt-dlp for tests. ExecuteFunc plays yt-dlp by writing its output and, for downloads,
+// the files it would create. Without ExecuteFunc every call succeeds without output.
+type MockExecutor struct {
+	ExecuteFunc func(args []string, stdout io.Writer, stderr io.Writer) error
+
+	mutex sync.Mutex
+	calls [][]string
+}
+
+func (m *MockExecutor) Execute(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	m.mutex.Lock()
+	m.calls = append(m.calls, append([]string(nil), args...))
+	m.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.ExecuteFunc != nil {
+		return m.ExecuteFunc(args, stdout, stderr)
+	}
+	return nil
+}
+
+// Calls returns the arguments of all runs so far.
+func (m *MockExecutor) Calls() [][]string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([][]string(nil), m.calls...)
+}
@@ -0,0 +1,69 @@
+package downloader
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// progressLinePattern matches yt-dlp progress lines such as
+// "[download]  42.3% of   12.34MiB at    1.23MiB/s ETA 00:07".
+var progressLinePattern = regexp.MustCompile(`^\[download\]\s+(\d+(?:\.\d+)?)%`)
+
+// ParseProgressPercent extracts the percentage from a yt-dlp progress line.
+// The second return value is false if the line does not report progress.
+func ParseProgressPercent(line string) (float64, bool) {
+	match := progressLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return percent, true
+}
+
+// ProgressWriter is an io.Writer for yt-dlp output run with --newline.
+// Progress lines are passed to the progress function, all other lines are forwarded to the underlying writer.
+type ProgressWriter struct {
+	out      io.Writer
+	progress ProgressFunc
+	buffer   []byte
+}
+
+func NewProgressWriter(out io.Writer, progress ProgressFunc) *ProgressWriter {
+	return &ProgressWriter{
+		out:      out,
+		progress: progress,
+	}
+}
+
+func (w *ProgressWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for {
+		index := bytes.IndexAny(w.buffer, "\r\n")
+		if index < 0 {
+			break
+		}
+		line := w.buffer[:index+1]
+		w.buffer = w.buffer[index+1:]
+		if err := w.handleLine(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *ProgressWriter) handleLine(line []byte) error {
+	if percent, ok := ParseProgressPercent(string(bytes.TrimSpace(line))); ok {
+		w.progress.Report(StageDownloading, percent)
+		return nil
+	}
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(line)
+	return err
+}
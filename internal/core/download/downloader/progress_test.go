@@ -0,0 +1,87 @@
+package downloader
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseProgressPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   float64
+		wantOk bool
+	}{
+		{
+			name:   "progress line",
+			line:   "[download]  42.3% of   12.34MiB at    1.23MiB/s ETA 00:07",
+			want:   42.3,
+			wantOk: true,
+		},
+		{
+			name:   "completed line",
+			line:   "[download] 100% of   12.34MiB in 00:00:10 at 1.23MiB/s",
+			want:   100,
+			wantOk: true,
+		},
+		{
+			name:   "destination line",
+			line:   "[download] Destination: /tmp/file.webm",
+			wantOk: false,
+		},
+		{
+			name:   "other prefix",
+			line:   "[ExtractAudio]  42.3%",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseProgressPercent(tt.line)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseProgressPercent() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if got != tt.want {
+				t.Errorf("ParseProgressPercent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressWriter_SplitsProgressFromOutput(t *testing.T) {
+	var out bytes.Buffer
+	var reported []float64
+	writer := NewProgressWriter(&out, func(stage Stage, percent float64) {
+		if stage != StageDownloading {
+			t.Errorf("expected stage %q, got %q", StageDownloading, stage)
+		}
+		reported = append(reported, percent)
+	})
+
+	chunks := []string{
+		"[youtube] abc: Downloading webpage\n[download]  10.0% of 1MiB",
+		"\n[download]  55.5% of 1MiB\n",
+		"[download] 100% of 1MiB\n[ExtractAudio] Destination: a.mp3\n",
+	}
+	for _, chunk := range chunks {
+		if _, err := writer.Write([]byte(chunk)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := []float64{10, 55.5, 100}
+	if len(reported) != len(want) {
+		t.Fatalf("expected %d progress updates, got %v", len(want), reported)
+	}
+	for i := range want {
+		if reported[i] != want[i] {
+			t.Errorf("progress[%d] = %v, want %v", i, reported[i], want[i])
+		}
+	}
+
+	wantOut := "[youtube] abc: Downloading webpage\n[ExtractAudio] Destination: a.mp3\n"
+	if out.String() != wantOut {
+		t.Errorf("forwarded output = %q, want %q", out.String(), wantOut)
+	}
+}
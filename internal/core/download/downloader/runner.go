@@ -0,0 +1,366 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+)
+
+// ytDlpBinary is the yt-dlp executable looked up in PATH.
+const ytDlpBinary = "yt-dlp"
+
+// probeCacheTTL is how long the probed metadata of a video is reused. It covers the time between the
+// probe of the content filters, the availability check and the tagging of a download, while live status changes
+// are picked up by CheckAvailability.
+const probeCacheTTL = 15 * time.Minute
+
+// Executor runs yt-dlp. It is replaced by a fake yt-dlp in tests, see MockExecutor.
+type Executor interface {
+	// Execute runs yt-dlp with args and writes its output to stdout and stderr.
+	// Cancelling ctx stops the process.
+	Execute(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error
+}
+
+// CommandExecutor runs the yt-dlp binary found in PATH.
+type CommandExecutor struct{}
+
+func (CommandExecutor) Execute(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, ytDlpBinary, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// yt-dlp may leave ffmpeg child processes holding the output pipes after it was killed on cancellation
+	cmd.WaitDelay = ProcessWaitDelay
+	return cmd.Run()
+}
+
+// YtDlpRunner builds the yt-dlp calls shared by the yt-dlp based downloaders and runs them with an Executor.
+// The output of every call is captured and logged with the call instead of being written to the process output.
+// The metadata of a video is probed once with --dump-json and reused for availability checks, filters and tagging.
+type YtDlpRunner struct {
+	executor      Executor
+	cookiesConfig *config.Cookies
+	ytDlpConfig   *config.YtDlp
+	audioConfig   *config.Audio
+	args          []string // appended to the base arguments of every call
+	probes        *probeCache
+}
+
+// probeCache holds the probed metadata of videos by URL.
+type probeCache struct {
+	mutex   sync.Mutex
+	entries map[string]probeCacheEntry
+	now     func() time.Time
+}
+
+type probeCacheEntry struct {
+	info      *VideoInfo
+	source    []byte // see SourceMetadata
+	fetchedAt time.Time
+}
+
+func newProbeCache() *probeCache {
+	return &probeCache{entries: make(map[string]probeCacheEntry), now: time.Now}
+}
+
+func (c *probeCache) get(url string) (probeCacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, found := c.entries[url]
+	if !found || c.now().Sub(entry.fetchedAt) >= probeCacheTTL {
+		return probeCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// put stores the metadata of url and drops expired entries.
+func (c *probeCache) put(url string, entry probeCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now()
+	for cachedURL, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= probeCacheTTL {
+			delete(c.entries, cachedURL)
+		}
+	}
+	entry.fetchedAt = now
+	c.entries[url] = entry
+}
+
+// NewYtDlpRunner creates a runner that runs yt-dlp with executor, the yt-dlp binary if executor is nil.
+func NewYtDlpRunner(executor Executor, cookiesConfig *config.Cookies, ytDlpConfig *config.YtDlp, audioConfig *config.Audio) *YtDlpRunner {
+	if executor == nil {
+		executor = CommandExecutor{}
+	}
+	return &YtDlpRunner{
+		executor:      executor,
+		cookiesConfig: cookiesConfig,
+		ytDlpConfig:   ytDlpConfig,
+		audioConfig:   audioConfig,
+		probes:        newProbeCache(),
+	}
+}
+
+// WithArgs returns a runner that appends args to the base arguments of every call, e.g. site specific arguments.
+// Since the arguments may change what yt-dlp reports, the runner probes videos on its own.
+func (r *YtDlpRunner) WithArgs(args ...string) *YtDlpRunner {
+	runner := *r
+	runner.args = append(append(make([]string, 0, len(r.args)+len(args)), r.args...), args...)
+	runner.probes = newProbeCache()
+	return &runner
+}
+
+// BaseArgs creates base arguments for yt-dlp command.
+// When simulate is true, adds --simulate and --quiet flags for dry-run operations.
+func (r *YtDlpRunner) BaseArgs(simulate bool) []string {
+	args := AppendCookieArgs(make([]string, 0), r.cookiesConfig)
+
+	if simulate {
+		args = append(args, "--simulate", "--quiet")
+	} else if r.verbose() {
+		args = append(args, "--verbose")
+	}
+	args = append(args, r.args...)
+
+	return args
+}
+
+// Output runs a simulated yt-dlp call with args appended to the base arguments and returns what yt-dlp printed.
+// Failed calls return an error classified by NewYtDlpError and the error output of yt-dlp.
+func (r *YtDlpRunner) Output(ctx context.Context, args ...string) ([]byte, string, error) {
+	var stdout, stderr bytes.Buffer
+	if err := r.executor.Execute(ctx, append(r.BaseArgs(true), args...), &stdout, &stderr); err != nil {
+		return nil, stderr.String(), NewYtDlpError(err, stderr.String())
+	}
+	return stdout.Bytes(), stderr.String(), nil
+}
+
+// CheckAvailability returns nil if the video is available for download, ErrVideoLive if it is currently live,
+// ErrVideoUpcoming if it has not started yet, or another error if unavailable.
+// A probe within probeCacheTTL that found the video available is reused, e.g. the one of the content filters.
+// Videos that were live or upcoming are probed again since their live status may have changed.
+func (r *YtDlpRunner) CheckAvailability(ctx context.Context, url string) error {
+	slog.Info("checking video availability", "url", url)
+	if entry, found := r.probes.get(url); found && entry.info.LiveStatusError() == nil {
+		return nil
+	}
+
+	entry, stderr, err := r.probe(ctx, url)
+	if err != nil {
+		if IsUpcomingFromError([]byte(stderr)) {
+			slog.Info("video is an upcoming live stream or premiere", "url", url)
+			return ErrVideoUpcoming
+		}
+		return fmt.Errorf("yt-dlp availability check failed: %w", err)
+	}
+
+	if err := entry.info.LiveStatusError(); err != nil {
+		slog.Warn("video is live or not started yet; treating as unavailable", "url", url, "err", err)
+		return err
+	}
+	return nil
+}
+
+// ListEntries lists the entries of a playlist without resolving them, restricted to the item ranges of the selection.
+// args are passed to yt-dlp before the URL.
+func (r *YtDlpRunner) ListEntries(ctx context.Context, url string, selection Selection, args ...string) ([]PlaylistEntry, error) {
+	args = append(args, "--flat-playlist", "--print", PlaylistEntryTemplate)
+	if selection.Items != "" {
+		args = append(args, "--playlist-items", selection.Items)
+	}
+	args = append(args, url)
+
+	output, _, err := r.Output(ctx, args...)
+	if err != nil {
+		slog.Error("error listing playlist entries", "url", url, "err", err)
+		return nil, err
+	}
+	return ParsePlaylistEntries(output), nil
+}
+
+// VideoInfo returns the metadata of a single video reported by yt-dlp --dump-json.
+// Metadata probed within probeCacheTTL is reused.
+func (r *YtDlpRunner) VideoInfo(ctx context.Context, url string) (*VideoInfo, error) {
+	entry, err := r.cachedProbe(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return entry.info.clone(), nil
+}
+
+// WriteInfoFile writes the source metadata of url, see SourceMetadata, to path.
+// Metadata probed within probeCacheTTL is reused.
+func (r *YtDlpRunner) WriteInfoFile(ctx context.Context, url string, path string) error {
+	entry, err := r.cachedProbe(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, entry.source, 0644); err != nil {
+		return fmt.Errorf("failed to write source metadata of %s: %w", url, err)
+	}
+	return nil
+}
+
+// TagAndMove finishes the download of an audio file from url: it writes the podcast tags, see SetMetadata,
+// stores the source metadata next to the file and moves both into targetPath.
+// It returns the full path of the moved audio file.
+func (r *YtDlpRunner) TagAndMove(ctx context.Context, url string, filePath string, targetPath string, progress ProgressFunc) (string, error) {
+	progress.Report(StageTagging, 100)
+	slog.Info("setting metadata", "filePath", filePath)
+	if err := r.SetMetadata(ctx, filePath, url); err != nil {
+		return "", err
+	}
+	slog.Info("set metadata", "filePath", filePath)
+	// the source metadata is kept for later use only, an episode without it is still fine
+	if err := r.WriteInfoFile(ctx, url, filemanagement.InfoFilePath(filePath)); err != nil {
+		slog.Warn("could not write source metadata", "url", url, "err", err)
+	}
+
+	progress.Report(StageMoving, 100)
+	slog.Info("moving file to target folder")
+	result, err := filemanagement.MoveToTarget(filePath, targetPath)
+	if err != nil {
+		return "", err
+	}
+	slog.Info("completed moving file", "targetPath", result)
+	return result, nil
+}
+
+// cachedProbe returns the probed metadata of url, probing it if it is not cached.
+func (r *YtDlpRunner) cachedProbe(ctx context.Context, url string) (probeCacheEntry, error) {
+	if entry, found := r.probes.get(url); found {
+		return entry, nil
+	}
+	entry, _, err := r.probe(ctx, url)
+	if err != nil {
+		return probeCacheEntry{}, fmt.Errorf("yt-dlp video info fetch failed: %w", err)
+	}
+	return entry, nil
+}
+
+// probe runs yt-dlp --dump-json for a single video and caches the result. It returns the error output of failed runs.
+func (r *YtDlpRunner) probe(ctx context.Context, url string) (probeCacheEntry, string, error) {
+	output, stderr, err := r.Output(ctx, "--dump-json", "--no-playlist", url)
+	if err != nil {
+		return probeCacheEntry{}, stderr, err
+	}
+
+	info, err := ParseVideoInfo(output)
+	if err != nil {
+		return probeCacheEntry{}, stderr, err
+	}
+	if info.URL == "" {
+		info.URL = url
+	}
+	source, err := SourceMetadata(output)
+	if err != nil {
+		return probeCacheEntry{}, stderr, err
+	}
+	entry := probeCacheEntry{info: info, source: source}
+	r.probes.put(url, entry)
+	return entry, stderr, nil
+}
+
+// DownloadAudio downloads the audio of url in the configured audio format with embedded metadata to outputTemplate.
+// args are passed to yt-dlp before the output template, progress updates are passed to progress, which may be nil.
+func (r *YtDlpRunner) DownloadAudio(ctx context.Context, url string, outputTemplate string, progress ProgressFunc, args ...string) error {
+	downloadArgs := r.BaseArgs(false)
+	downloadArgs = append(downloadArgs,
+		"--extract-audio",
+	)
+	downloadArgs = append(downloadArgs, r.audioArgs()...)
+	downloadArgs = append(downloadArgs,
+		"--embed-metadata",
+		// print progress as separate lines so it can be parsed
+		"--newline",
+	)
+	downloadArgs = append(downloadArgs, args...)
+	downloadArgs = append(downloadArgs, "--output", outputTemplate, url)
+	slog.Info("constructed yt-dlp command", "args", downloadArgs)
+
+	// output of this download only, logged with its URL
+	var output bytes.Buffer
+	// keep stderr to classify failures as permanent or transient
+	var stderr bytes.Buffer
+	err := r.executor.Execute(ctx, downloadArgs, NewProgressWriter(&output, progress), io.MultiWriter(&output, &stderr))
+	if err != nil {
+		slog.Warn("yt-dlp download failed", "url", url, "output", output.String())
+		return NewYtDlpError(err, stderr.String())
+	}
+	if r.verbose() {
+		slog.Info("yt-dlp download output", "url", url, "output", output.String())
+	} else {
+		slog.Debug("yt-dlp download output", "url", url, "output", output.String())
+	}
+	return nil
+}
+
+// SetMetadata writes the podcast tags of an audio file downloaded by yt-dlp from sourceURL: title, description,
+// date, download link, thumbnail and the exact upload time. They are taken from the probed metadata of the video
+// and fall back to the tags yt-dlp embedded.
+func (r *YtDlpRunner) SetMetadata(ctx context.Context, fullFilePath string, sourceURL string) error {
+	metadata, err := GetTags(fullFilePath)
+	if err != nil {
+		return err
+	}
+
+	info, err := r.VideoInfo(ctx, sourceURL)
+	if err != nil {
+		return err
+	}
+
+	if info.Title != "" {
+		metadata[Title] = info.Title
+	}
+	description := info.Description
+	if description == "" {
+		description = metadata["synopsis"]
+	}
+	if description == "" {
+		description = metadata["description"]
+	}
+	metadata[PodcastDescriptionTag] = strings.ReplaceAll(description, "\n", "<br>")
+	metadata[DateTag] = metadata["date"]
+	// not every extractor reports the page URL, fall back to the submitted URL so the item can be recognized again
+	metadata[VideoDownloadLink] = metadata[VideoURLID3Key]
+	if metadata[VideoDownloadLink] == "" {
+		metadata[VideoDownloadLink] = sourceURL
+	}
+	metadata[ThumbnailUrlTag] = info.Thumbnail
+	if !info.UploadedAt.IsZero() {
+		metadata["date"] = info.UploadedAt.Format("2006-01-02T15:04:05")
+	} else {
+		slog.Warn("could not get upload time, will fall back to date tag", "url", sourceURL)
+	}
+
+	return SetTags(fullFilePath, metadata)
+}
+
+// audioArgs returns the arguments converting the extracted audio to the configured format, bitrate and sample rate.
+// yt-dlp keeps audio that already is in the format as it is.
+func (r *YtDlpRunner) audioArgs() []string {
+	if r.audioConfig == nil {
+		return []string{"--audio-format", config.AudioFormatMP3}
+	}
+	args := []string{"--audio-format", filemanagement.AudioFormatByName(r.audioConfig.Format).Name}
+	if r.audioConfig.Bitrate != "" {
+		args = append(args, "--audio-quality", strings.ToUpper(r.audioConfig.Bitrate))
+	}
+	if r.audioConfig.SampleRate > 0 {
+		args = append(args, "--postprocessor-args", fmt.Sprintf("ExtractAudio:-ar %d", r.audioConfig.SampleRate))
+	}
+	return args
+}
+
+func (r *YtDlpRunner) verbose() bool {
+	return r.ytDlpConfig != nil && r.ytDlpConfig.Verbose
+}
@@ -0,0 +1,285 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+)
+
+func TestYtDlpRunner_BaseArgs(t *testing.T) {
+	runner := NewYtDlpRunner(&MockExecutor{}, nil, &config.YtDlp{Verbose: true}, nil)
+	siteRunner := runner.WithArgs("--referer", "https://example.com")
+
+	tests := []struct {
+		name     string
+		runner   *YtDlpRunner
+		simulate bool
+		want     []string
+	}{
+		{name: "simulate", runner: runner, simulate: true, want: []string{"--simulate", "--quiet"}},
+		{name: "verbose download", runner: runner, simulate: false, want: []string{"--verbose"}},
+		{name: "with args", runner: siteRunner, simulate: true, want: []string{"--simulate", "--quiet", "--referer", "https://example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.runner.BaseArgs(tt.simulate); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BaseArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYtDlpRunner_CheckAvailability(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdout  string
+		stderr  string
+		fail    bool
+		wantErr error
+	}{
+		{name: "available", stdout: `{"id":"abc","live_status":"not_live"}`},
+		{name: "live", stdout: `{"id":"abc","live_status":"is_live"}`, wantErr: ErrVideoLive},
+		{name: "upcoming from error", stderr: "ERROR: [youtube] abc: This live event will begin in 3 hours.", fail: true, wantErr: ErrVideoUpcoming},
+		{name: "removed", stderr: "ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader", fail: true, wantErr: ErrPermanentFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+				_, _ = io.WriteString(stdout, tt.stdout)
+				_, _ = io.WriteString(stderr, tt.stderr)
+				if tt.fail {
+					return errors.New("exit status 1")
+				}
+				return nil
+			}}
+
+			err := NewYtDlpRunner(executor, nil, nil, nil).CheckAvailability(context.Background(), "https://example.com/v/abc")
+
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("CheckAvailability() unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckAvailability() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestYtDlpRunner_ListEntries(t *testing.T) {
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = io.WriteString(stdout, "1700000000 https://example.com/v/1\nNA https://example.com/v/2\n")
+		return nil
+	}}
+
+	entries, err := NewYtDlpRunner(executor, nil, nil, nil).ListEntries(context.Background(), "https://example.com/list", Selection{Items: "1:2"}, "--extractor-args", "tab:approximate_date")
+	if err != nil {
+		t.Fatalf("ListEntries() unexpected error: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].URL != "https://example.com/v/1" || entries[1].URL != "https://example.com/v/2" {
+		t.Errorf("ListEntries() = %v, want the two listed entries", entries)
+	}
+	want := []string{"--simulate", "--quiet", "--extractor-args", "tab:approximate_date", "--flat-playlist", "--print", PlaylistEntryTemplate, "--playlist-items", "1:2", "https://example.com/list"}
+	if calls := executor.Calls(); len(calls) != 1 || !reflect.DeepEqual(calls[0], want) {
+		t.Errorf("yt-dlp called with %v, want %v", calls, want)
+	}
+}
+
+func TestYtDlpRunner_DownloadAudio(t *testing.T) {
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		for _, percent := range []float64{10, 100} {
+			_, _ = fmt.Fprintf(stdout, "[download] %5.1f%% of 1MiB\n", percent)
+		}
+		_, _ = io.WriteString(stdout, "[ExtractAudio] Destination: a.mp3\n")
+		return nil
+	}}
+	var reported []float64
+
+	err := NewYtDlpRunner(executor, nil, nil, nil).DownloadAudio(context.Background(), "https://example.com/v/1", "/tmp/%(id)s.%(ext)s",
+		func(stage Stage, percent float64) { reported = append(reported, percent) }, "--no-playlist")
+	if err != nil {
+		t.Fatalf("DownloadAudio() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(reported, []float64{10, 100}) {
+		t.Errorf("reported progress %v, want [10 100]", reported)
+	}
+	args := executor.Calls()[0]
+	if slices.Contains(args, "--simulate") || !slices.Contains(args, "--extract-audio") || !slices.Contains(args, "--no-playlist") {
+		t.Errorf("unexpected download arguments %v", args)
+	}
+	if got := args[len(args)-3:]; !reflect.DeepEqual(got, []string{"--output", "/tmp/%(id)s.%(ext)s", "https://example.com/v/1"}) {
+		t.Errorf("download arguments end with %v, want output template and url", got)
+	}
+}
+
+func TestYtDlpRunner_DownloadAudio_ClassifiesFailure(t *testing.T) {
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = io.WriteString(stderr, "ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video\n")
+		return errors.New("exit status 1")
+	}}
+
+	err := NewYtDlpRunner(executor, nil, nil, nil).DownloadAudio(context.Background(), "https://example.com/v/1", "/tmp/%(id)s.%(ext)s", nil)
+
+	if !errors.Is(err, ErrPermanentFailure) {
+		t.Errorf("DownloadAudio() error = %v, want %v", err, ErrPermanentFailure)
+	}
+}
+
+func TestYtDlpRunner_VideoInfo(t *testing.T) {
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = io.WriteString(stdout, `{"id":"abc","title":"Talk","uploader":"Speaker","duration":90}`)
+		return nil
+	}}
+
+	info, err := NewYtDlpRunner(executor, nil, nil, nil).VideoInfo(context.Background(), "https://example.com/v/abc")
+	if err != nil {
+		t.Fatalf("VideoInfo() unexpected error: %v", err)
+	}
+
+	if info.Title != "Talk" || info.Channel != "Speaker" || info.URL != "https://example.com/v/abc" {
+		t.Errorf("VideoInfo() = %+v", info)
+	}
+}
+
+func TestYtDlpRunner_ReusesProbe(t *testing.T) {
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = io.WriteString(stdout, `{"id":"abc","title":"Talk","live_status":"not_live"}`)
+		return nil
+	}}
+	runner := NewYtDlpRunner(executor, nil, nil, nil)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	runner.probes.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := runner.CheckAvailability(ctx, "https://example.com/v/abc"); err != nil {
+		t.Fatalf("CheckAvailability() unexpected error: %v", err)
+	}
+	for range 2 {
+		info, err := runner.VideoInfo(ctx, "https://example.com/v/abc")
+		if err != nil {
+			t.Fatalf("VideoInfo() unexpected error: %v", err)
+		}
+		info.Title = "changed by caller"
+	}
+	if calls := len(executor.Calls()); calls != 1 {
+		t.Errorf("expected the availability probe to be reused, got %d yt-dlp calls", calls)
+	}
+	if info, _ := runner.VideoInfo(ctx, "https://example.com/v/abc"); info.Title != "Talk" {
+		t.Errorf("cached info was changed through a returned copy, title %q", info.Title)
+	}
+
+	if err := runner.CheckAvailability(ctx, "https://example.com/v/abc"); err != nil {
+		t.Fatalf("CheckAvailability() unexpected error: %v", err)
+	}
+	if calls := len(executor.Calls()); calls != 1 {
+		t.Errorf("expected the availability check to reuse the probe, got %d yt-dlp calls", calls)
+	}
+
+	now = now.Add(probeCacheTTL)
+	if _, err := runner.VideoInfo(ctx, "https://example.com/v/abc"); err != nil {
+		t.Fatalf("VideoInfo() unexpected error: %v", err)
+	}
+	if calls := len(executor.Calls()); calls != 2 {
+		t.Errorf("expected an expired probe to be fetched again, got %d yt-dlp calls", calls)
+	}
+}
+
+func TestYtDlpRunner_CheckAvailability_ProbesLiveVideosAgain(t *testing.T) {
+	liveStatus := "is_live"
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = fmt.Fprintf(stdout, `{"id":"abc","live_status":%q}`, liveStatus)
+		return nil
+	}}
+	runner := NewYtDlpRunner(executor, nil, nil, nil)
+	ctx := context.Background()
+
+	if err := runner.CheckAvailability(ctx, "https://example.com/v/abc"); !errors.Is(err, ErrVideoLive) {
+		t.Fatalf("CheckAvailability() error = %v, want %v", err, ErrVideoLive)
+	}
+	liveStatus = "was_live"
+	if err := runner.CheckAvailability(ctx, "https://example.com/v/abc"); err != nil {
+		t.Fatalf("CheckAvailability() unexpected error: %v", err)
+	}
+	if calls := len(executor.Calls()); calls != 2 {
+		t.Errorf("expected the live video to be probed again, got %d yt-dlp calls", calls)
+	}
+}
+
+func TestYtDlpRunner_WriteInfoFile(t *testing.T) {
+	executor := &MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = io.WriteString(stdout, `{"id":"abc","title":"Talk","channel_id":"UC1","formats":[{"format_id":"251"}]}`)
+		return nil
+	}}
+	runner := NewYtDlpRunner(executor, nil, nil, nil)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "abc.info.json")
+
+	if _, err := runner.VideoInfo(ctx, "https://example.com/v/abc"); err != nil {
+		t.Fatalf("VideoInfo() unexpected error: %v", err)
+	}
+	if err := runner.WriteInfoFile(ctx, "https://example.com/v/abc", path); err != nil {
+		t.Fatalf("WriteInfoFile() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read info file: %v", err)
+	}
+	if want := `{"channel_id":"UC1","id":"abc","title":"Talk"}`; string(content) != want {
+		t.Errorf("info file content = %s, want %s", content, want)
+	}
+	if calls := len(executor.Calls()); calls != 1 {
+		t.Errorf("expected the probe to be reused, got %d yt-dlp calls", calls)
+	}
+}
+
+func TestYtDlpRunner_DownloadAudio_AudioFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		audioConfig *config.Audio
+		want        []string
+	}{
+		{
+			name: "mp3 without configuration",
+			want: []string{"--audio-format", "mp3"},
+		},
+		{
+			name:        "format only",
+			audioConfig: &config.Audio{Format: config.AudioFormatM4A},
+			want:        []string{"--audio-format", "m4a"},
+		},
+		{
+			name:        "bitrate and sample rate",
+			audioConfig: &config.Audio{Format: config.AudioFormatOpus, Bitrate: "48k", SampleRate: 24000},
+			want:        []string{"--audio-format", "opus", "--audio-quality", "48K", "--postprocessor-args", "ExtractAudio:-ar 24000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &MockExecutor{}
+
+			err := NewYtDlpRunner(executor, nil, nil, tt.audioConfig).DownloadAudio(context.Background(), "https://example.com/v/1", "/tmp/%(id)s.%(ext)s", nil)
+			if err != nil {
+				t.Fatalf("DownloadAudio() unexpected error: %v", err)
+			}
+
+			args := executor.Calls()[0]
+			start := slices.Index(args, "--audio-format")
+			if start < 0 || start+len(tt.want) > len(args) || !reflect.DeepEqual(args[start:start+len(tt.want)], tt.want) {
+				t.Errorf("download arguments %v, want them to contain %v", args, tt.want)
+			}
+		})
+	}
+}
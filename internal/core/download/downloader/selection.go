@@ -0,0 +1,111 @@
+package downloader
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// playlistItemsPattern matches the index ranges accepted by yt-dlp --playlist-items, e.g. "1-10,15" or "-5:".
+var playlistItemsPattern = regexp.MustCompile(`^[0-9:,\- ]+$`)
+
+// Selection restricts which entries of a playlist or channel are listed. The zero value selects all entries.
+type Selection struct {
+	Items         string    // 1-based index ranges in yt-dlp --playlist-items syntax, e.g. "1-10,15"
+	Latest        int       // only the newest N entries
+	UploadedAfter time.Time // only entries uploaded after this time
+}
+
+// IsZero reports whether the selection selects all entries.
+func (s Selection) IsZero() bool {
+	return s.Items == "" && s.Latest == 0 && s.UploadedAfter.IsZero()
+}
+
+// Validate returns an error if the selection cannot be applied.
+func (s Selection) Validate() error {
+	if s.Items != "" && !playlistItemsPattern.MatchString(s.Items) {
+		return fmt.Errorf("invalid item ranges %q", s.Items)
+	}
+	if s.Latest < 0 {
+		return fmt.Errorf("latest must not be negative, got %d", s.Latest)
+	}
+	return nil
+}
+
+// PlaylistEntry is a single entry of a playlist or channel listing.
+type PlaylistEntry struct {
+	URL        string
+	UploadedAt time.Time // zero if yt-dlp did not report the upload time
+}
+
+// PlaylistEntryTemplate is the yt-dlp --print template parsed by ParsePlaylistEntries.
+const PlaylistEntryTemplate = "%(timestamp)s %(url)s"
+
+// ParsePlaylistEntries parses yt-dlp --flat-playlist output printed with PlaylistEntryTemplate.
+// Lines that do not contain an http(s) URL are ignored.
+func ParsePlaylistEntries(output []byte) []PlaylistEntry {
+	entries := make([]PlaylistEntry, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		timestamp, url, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			url = timestamp
+			timestamp = ""
+		}
+		url = strings.TrimSpace(url)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			continue
+		}
+		entry := PlaylistEntry{URL: url}
+		if seconds, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+			entry.UploadedAt = time.Unix(seconds, 0).UTC()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// SelectEntries applies the upload time filters of the selection to entries and returns their URLs.
+// Item ranges are expected to be applied by yt-dlp while listing.
+// Entries without an upload time are dropped if UploadedAfter is set. Latest picks the newest entries
+// by upload time if all entries have one, and the first entries in listing order otherwise,
+// since channels are listed newest first.
+func SelectEntries(entries []PlaylistEntry, selection Selection) []string {
+	selected := make([]PlaylistEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !selection.UploadedAfter.IsZero() && !entry.UploadedAt.After(selection.UploadedAfter) {
+			continue
+		}
+		selected = append(selected, entry)
+	}
+	if dropped := len(entries) - len(selected); dropped > 0 {
+		slog.Info("skipped entries uploaded before the selected date or without upload date", "skippedCount", dropped, "uploadedAfter", selection.UploadedAfter)
+	}
+
+	if selection.Latest > 0 && len(selected) > selection.Latest {
+		if allHaveUploadTime(selected) {
+			sort.SliceStable(selected, func(i, j int) bool {
+				return selected[i].UploadedAt.After(selected[j].UploadedAt)
+			})
+		}
+		selected = selected[:selection.Latest]
+	}
+
+	urls := make([]string, 0, len(selected))
+	for _, entry := range selected {
+		urls = append(urls, entry.URL)
+	}
+	return urls
+}
+
+func allHaveUploadTime(entries []PlaylistEntry) bool {
+	for _, entry := range entries {
+		if entry.UploadedAt.IsZero() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package downloader
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePlaylistEntries(t *testing.T) {
+	output := []byte("1700000000 https://www.youtube.com/watch?v=a\nNA https://www.youtube.com/watch?v=b\nWARNING: something\nhttps://www.youtube.com/watch?v=c\n")
+
+	want := []PlaylistEntry{
+		{URL: "https://www.youtube.com/watch?v=a", UploadedAt: time.Unix(1700000000, 0).UTC()},
+		{URL: "https://www.youtube.com/watch?v=b"},
+		{URL: "https://www.youtube.com/watch?v=c"},
+	}
+	if got := ParsePlaylistEntries(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePlaylistEntries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSelectEntries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	playlist := []PlaylistEntry{
+		{URL: "first", UploadedAt: day(1)},
+		{URL: "third", UploadedAt: day(3)},
+		{URL: "second", UploadedAt: day(2)},
+	}
+	undated := []PlaylistEntry{{URL: "newest"}, {URL: "older"}, {URL: "oldest"}}
+
+	tests := []struct {
+		name      string
+		entries   []PlaylistEntry
+		selection Selection
+		want      []string
+	}{
+		{name: "no selection", entries: playlist, selection: Selection{}, want: []string{"first", "third", "second"}},
+		{name: "latest by upload time", entries: playlist, selection: Selection{Latest: 2}, want: []string{"third", "second"}},
+		{name: "latest in listing order without upload times", entries: undated, selection: Selection{Latest: 2}, want: []string{"newest", "older"}},
+		{name: "uploaded after", entries: playlist, selection: Selection{UploadedAfter: day(1)}, want: []string{"third", "second"}},
+		{name: "uploaded after drops undated entries", entries: undated, selection: Selection{UploadedAfter: day(1)}, want: []string{}},
+		{name: "uploaded after and latest", entries: playlist, selection: Selection{UploadedAfter: day(1), Latest: 1}, want: []string{"third"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SelectEntries(tt.entries, tt.selection); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelection_Validate(t *testing.T) {
+	valid := []Selection{{}, {Items: "1-10,15"}, {Items: "-5:"}, {Latest: 3}}
+	for _, selection := range valid {
+		if err := selection.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", selection, err)
+		}
+	}
+	invalid := []Selection{{Items: "1;rm"}, {Items: "--exec"}, {Latest: -1}}
+	for _, selection := range invalid {
+		if err := selection.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", selection)
+		}
+	}
+}
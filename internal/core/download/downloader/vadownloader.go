@@ -1,24 +1,65 @@
 package downloader
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // ErrVideoLive is returned by CheckVideoAvailability when the content is
 // currently streaming live. Callers should not retry immediately.
 var ErrVideoLive = errors.New("video is currently live")
 
+// ErrVideoUpcoming is returned by CheckVideoAvailability when the content is
+// a scheduled live stream or premiere that has not started yet.
+var ErrVideoUpcoming = errors.New("video is an upcoming live stream or premiere")
+
+// Stage names the step a download is currently in.
+type Stage string
+
+const (
+	StageDownloading Stage = "downloading"
+	StageTagging     Stage = "tagging"
+	StageMoving      Stage = "moving"
+)
+
+// ProgressFunc receives progress updates while a download is running.
+// Percent is the download progress in the range 0-100; it is 100 once the
+// download finished and the file is post-processed.
+type ProgressFunc func(stage Stage, percent float64)
+
+// Report calls the progress function if it is set.
+func (f ProgressFunc) Report(stage Stage, percent float64) {
+	if f != nil {
+		f(stage, percent)
+	}
+}
+
+// AudioDownloader downloads audio from the videos of a single platform.
+// Cancelling the context passed to a method stops any process it spawned.
 type AudioDownloader interface {
 	// Download downloads the audio from a single video URL and saves it to the specified path.
 	// It returns the full file path to the downloaded audio file.
 	// The downloader decides if subpaths are created or not.
-	Download(url string, path string) (string, error)
+	// Progress updates are passed to progress, which may be nil.
+	Download(ctx context.Context, url string, path string, progress ProgressFunc) (string, error)
 	IsVideoSupported(url string) bool
+	// NormalizeVideoURL returns the canonical URL of a single video as it is stored in the audio metadata,
+	// e.g. without tracking parameters. URLs that cannot be normalized are returned unchanged.
+	NormalizeVideoURL(url string) string
 	// CheckVideoAvailability returns nil if the video is available for download,
-	// ErrVideoLive if it is currently live, or another error if unavailable.
-	CheckVideoAvailability(url string) error
+	// ErrVideoLive if it is currently live, ErrVideoUpcoming if it has not started yet,
+	// or another error if unavailable.
+	CheckVideoAvailability(ctx context.Context, url string) error
 	// ListIndividualVideoURLs returns individual video URLs for a given input URL.
 	// For playlist URLs, it returns all video URLs in the playlist.
 	// For single video URLs, it returns a slice containing the original URL.
-	ListIndividualVideoURLs(url string) ([]string, error)
+	// The selection restricts the playlist entries and is ignored for single videos.
+	ListIndividualVideoURLs(ctx context.Context, url string, selection Selection) ([]string, error)
+	// IsVideoList reports whether the URL lists videos, e.g. a channel, a playlist or a feed,
+	// rather than pointing to a single video.
+	IsVideoList(url string) bool
+	// GetVideoInfo returns the metadata of a single video without downloading it.
+	GetVideoInfo(ctx context.Context, url string) (*VideoInfo, error)
 }
 
 const (
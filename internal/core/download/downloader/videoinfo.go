@@ -0,0 +1,125 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VideoInfo is the metadata of a single video as reported by yt-dlp, obtained without downloading the video.
+type VideoInfo struct {
+	URL             string    `json:"url"`
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	Channel         string    `json:"channel"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Thumbnail       string    `json:"thumbnail,omitempty"`
+	LiveStatus      string    `json:"live_status,omitempty"` // yt-dlp live_status, e.g. not_live, is_live or is_upcoming
+	UploadedAt      time.Time `json:"uploaded_at,omitzero"`
+	Short           bool      `json:"short,omitempty"` // YouTube Short or a similar short vertical clip
+	// Description is used to tag downloads and is not part of previews
+	Description string `json:"-"`
+}
+
+// maxShortDuration is the maximum length of a YouTube Short.
+const maxShortDuration = 3 * time.Minute
+
+// IsShortURL reports whether the URL links to a YouTube Short.
+func IsShortURL(url string) bool {
+	return strings.Contains(url, "youtube.com/shorts/")
+}
+
+// ytDlpVideoInfo holds the fields of yt-dlp --dump-json output that VideoInfo is built from.
+type ytDlpVideoInfo struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Channel     string  `json:"channel"`
+	Uploader    string  `json:"uploader"`
+	Duration    float64 `json:"duration"`
+	Thumbnail   string  `json:"thumbnail"`
+	LiveStatus  string  `json:"live_status"`
+	Timestamp   int64   `json:"timestamp"`
+	UploadDate  string  `json:"upload_date"` // YYYYMMDD
+	WebpageURL  string  `json:"webpage_url"`
+	OriginalURL string  `json:"original_url"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+}
+
+// ParseVideoInfo parses the yt-dlp --dump-json output of a single video.
+// The channel falls back to the uploader for platforms without channels and the upload time falls back to the upload date.
+// yt-dlp does not flag Shorts, so videos requested by their Shorts URL and vertical videos no longer than a Short are reported as short.
+func ParseVideoInfo(output []byte) (*VideoInfo, error) {
+	var raw ytDlpVideoInfo
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp video info: %w", err)
+	}
+
+	info := &VideoInfo{
+		URL:             raw.WebpageURL,
+		ID:              raw.ID,
+		Title:           raw.Title,
+		Description:     raw.Description,
+		Channel:         raw.Channel,
+		DurationSeconds: raw.Duration,
+		Thumbnail:       raw.Thumbnail,
+		LiveStatus:      raw.LiveStatus,
+	}
+	if info.URL == "" {
+		info.URL = raw.OriginalURL
+	}
+	if info.Channel == "" {
+		info.Channel = raw.Uploader
+	}
+	isVertical := raw.Width > 0 && raw.Height > raw.Width
+	isShortLength := raw.Duration > 0 && raw.Duration <= maxShortDuration.Seconds()
+	info.Short = IsShortURL(raw.OriginalURL) || IsShortURL(raw.WebpageURL) || (isVertical && isShortLength)
+	if raw.Timestamp > 0 {
+		info.UploadedAt = time.Unix(raw.Timestamp, 0).UTC()
+	} else if uploadDate, err := time.Parse("20060102", raw.UploadDate); err == nil {
+		info.UploadedAt = uploadDate
+	}
+	return info, nil
+}
+
+// LiveStatusError returns ErrVideoLive for running (or still processing) live streams, ErrVideoUpcoming
+// for scheduled live streams and premieres and nil for all other videos.
+func (v *VideoInfo) LiveStatusError() error {
+	switch v.LiveStatus {
+	case LiveStatusLiveValue, LiveStatusPostLiveValue:
+		return ErrVideoLive
+	case LiveStatusUpcomingValue:
+		return ErrVideoUpcoming
+	default:
+		return nil
+	}
+}
+
+// clone returns a copy of the info that can be modified without changing the original.
+func (v *VideoInfo) clone() *VideoInfo {
+	clone := *v
+	return &clone
+}
+
+// bulkySourceFields are the fields of yt-dlp --dump-json output that are dropped from the source metadata.
+// They describe how to download the video, expire with their URLs or are large without saying anything about the video.
+var bulkySourceFields = []string{
+	"formats", "requested_formats", "requested_downloads", "requested_subtitles", "thumbnails",
+	"automatic_captions", "subtitles", "heatmap", "http_headers", "fragments", "url", "manifest_url",
+	"_format_sort_fields", "_version", "_type", "filename", "_filename",
+}
+
+// SourceMetadata returns the yt-dlp --dump-json output of a single video without the fields in bulkySourceFields,
+// e.g. to be stored with the episode.
+func SourceMetadata(output []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(output, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp video info: %w", err)
+	}
+	for _, field := range bulkySourceFields {
+		delete(fields, field)
+	}
+	return json.Marshal(fields)
+}
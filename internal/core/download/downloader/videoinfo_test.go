@@ -0,0 +1,103 @@
+package downloader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseVideoInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   VideoInfo
+	}{
+		{
+			name:   "youtube video",
+			output: `{"id":"abc","title":"A Talk","description":"About\ntalks","channel":"Conference","uploader":"conf","duration":3600.5,"thumbnail":"https://i.ytimg.com/abc.jpg","live_status":"not_live","timestamp":1700000000,"upload_date":"20231114","webpage_url":"https://www.youtube.com/watch?v=abc","chapters":[{"start_time":0,"end_time":600,"title":"Intro"},{"start_time":600,"end_time":3600.5,"title":"Talk"}]}`,
+			want: VideoInfo{
+				URL:             "https://www.youtube.com/watch?v=abc",
+				ID:              "abc",
+				Title:           "A Talk",
+				Channel:         "Conference",
+				DurationSeconds: 3600.5,
+				Thumbnail:       "https://i.ytimg.com/abc.jpg",
+				LiveStatus:      "not_live",
+				UploadedAt:      time.Unix(1700000000, 0).UTC(),
+				Description:     "About\ntalks",
+			},
+		},
+		{
+			name:   "uploader and upload date as fallback",
+			output: `{"id":"v1","title":"Stream","uploader":"streamer","duration":60,"upload_date":"20240102","original_url":"https://www.twitch.tv/videos/1"}`,
+			want: VideoInfo{
+				URL:             "https://www.twitch.tv/videos/1",
+				ID:              "v1",
+				Title:           "Stream",
+				Channel:         "streamer",
+				DurationSeconds: 60,
+				UploadedAt:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "short requested by its shorts url",
+			output: `{"id":"s1","title":"Clip","duration":200,"original_url":"https://www.youtube.com/shorts/s1","webpage_url":"https://www.youtube.com/watch?v=s1"}`,
+			want:   VideoInfo{URL: "https://www.youtube.com/watch?v=s1", ID: "s1", Title: "Clip", DurationSeconds: 200, Short: true},
+		},
+		{
+			name:   "short vertical video",
+			output: `{"id":"s2","title":"Clip","duration":45,"width":1080,"height":1920,"webpage_url":"https://www.youtube.com/watch?v=s2"}`,
+			want:   VideoInfo{URL: "https://www.youtube.com/watch?v=s2", ID: "s2", Title: "Clip", DurationSeconds: 45, Short: true},
+		},
+		{
+			name:   "long vertical video",
+			output: `{"id":"v2","title":"Vlog","duration":900,"width":1080,"height":1920,"webpage_url":"https://www.youtube.com/watch?v=v2"}`,
+			want:   VideoInfo{URL: "https://www.youtube.com/watch?v=v2", ID: "v2", Title: "Vlog", DurationSeconds: 900},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVideoInfo([]byte(tt.output))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseVideoInfo() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVideoInfo_InvalidOutput_ReturnsError(t *testing.T) {
+	if _, err := ParseVideoInfo([]byte("ERROR: not json")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
+
+func TestVideoInfo_LiveStatusError(t *testing.T) {
+	if err := (&VideoInfo{LiveStatus: LiveStatusUpcomingValue}).LiveStatusError(); !errors.Is(err, ErrVideoUpcoming) {
+		t.Errorf("expected ErrVideoUpcoming, got %v", err)
+	}
+	if err := (&VideoInfo{LiveStatus: LiveStatusPostLiveValue}).LiveStatusError(); !errors.Is(err, ErrVideoLive) {
+		t.Errorf("expected ErrVideoLive, got %v", err)
+	}
+	if err := (&VideoInfo{LiveStatus: "not_live"}).LiveStatusError(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestSourceMetadata(t *testing.T) {
+	output := `{"id":"abc","channel_id":"UC1","tags":["go"],"view_count":42,"playlist_index":3,"formats":[{"format_id":"251"}],"thumbnails":[{"url":"https://i.ytimg.com/abc.jpg"}],"http_headers":{"Accept":"*/*"}}`
+
+	got, err := SourceMetadata([]byte(output))
+	if err != nil {
+		t.Fatalf("SourceMetadata() unexpected error: %v", err)
+	}
+
+	want := `{"channel_id":"UC1","id":"abc","playlist_index":3,"tags":["go"],"view_count":42}`
+	if string(got) != want {
+		t.Errorf("SourceMetadata() = %s, want %s", got, want)
+	}
+}
@@ -4,17 +4,24 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 )
 
 const (
-	// LiveStatusKey is the yt-dlp field name for the live status of a video.
-	LiveStatusKey = "live_status"
 	// LiveStatusLiveValue is the yt-dlp live_status value for an active live stream.
 	LiveStatusLiveValue = "is_live"
+	// LiveStatusUpcomingValue is the yt-dlp live_status value for a scheduled live stream or premiere.
+	LiveStatusUpcomingValue = "is_upcoming"
+	// LiveStatusPostLiveValue is the yt-dlp live_status value for a finished live stream whose VOD is still being processed.
+	LiveStatusPostLiveValue = "post_live"
 	// VideoURLID3Key is the ID3 tag yt-dlp uses to store the original video URL.
 	VideoURLID3Key = "purl"
+
+	// ProcessWaitDelay bounds how long a cancelled yt-dlp command waits for its
+	// child processes (e.g. ffmpeg) to release the output pipes.
+	ProcessWaitDelay = 10 * time.Second
 )
 
 // AppendCookieArgs appends --cookies <path> to args when cookiesConfig is enabled
@@ -31,24 +38,22 @@ func AppendCookieArgs(args []string, cookiesConfig *config.Cookies) []string {
 	return args
 }
 
-// IsLiveFromOutput returns true if any non-empty line in the yt-dlp --print live_status
-// output equals LiveStatusLiveValue, indicating the content is currently streaming live.
-func IsLiveFromOutput(output []byte) bool {
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		if strings.TrimSpace(line) == LiveStatusLiveValue {
-			return true
-		}
-	}
-	return false
+// upcomingErrorMarkers are lower-case fragments of the errors yt-dlp reports for live streams and premieres that have not started yet.
+var upcomingErrorMarkers = []string{
+	"live event will begin",
+	"premieres in",
+	"premiere will begin",
+	"waiting for scheduled stream",
 }
 
-// FirstHTTPSLineFromOutput returns the first line starting with "https" from
-// yt-dlp --print output, or an empty string if none is found.
-func FirstHTTPSLineFromOutput(output []byte) string {
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		if strings.HasPrefix(line, "https") {
-			return line
+// IsUpcomingFromError reports whether the stderr output of a failed yt-dlp run
+// says that the video is a live stream or premiere that has not started yet.
+func IsUpcomingFromError(stderr []byte) bool {
+	lowerStderr := strings.ToLower(string(stderr))
+	for _, marker := range upcomingErrorMarkers {
+		if strings.Contains(lowerStderr, marker) {
+			return true
 		}
 	}
-	return ""
+	return false
 }
@@ -8,100 +8,22 @@ import (
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 )
 
-func TestIsLiveFromOutput(t *testing.T) {
+func TestIsUpcomingFromError(t *testing.T) {
 	tests := []struct {
 		name   string
-		output []byte
+		stderr []byte
 		want   bool
 	}{
-		{
-			name:   "single live status line",
-			output: []byte("is_live"),
-			want:   true,
-		},
-		{
-			name:   "live status among multiple lines",
-			output: []byte("none\nis_live\nnone"),
-			want:   true,
-		},
-		{
-			name:   "live status with surrounding whitespace",
-			output: []byte("  is_live  "),
-			want:   true,
-		},
-		{
-			name:   "not live",
-			output: []byte("was_live"),
-			want:   false,
-		},
-		{
-			name:   "empty output",
-			output: []byte(""),
-			want:   false,
-		},
-		{
-			name:   "output with only newlines",
-			output: []byte("\n\n\n"),
-			want:   false,
-		},
-		{
-			name:   "partial match is not live",
-			output: []byte("is_live_stream"),
-			want:   false,
-		},
+		{name: "scheduled live event", stderr: []byte("ERROR: [youtube] abc: This live event will begin in 3 hours."), want: true},
+		{name: "premiere", stderr: []byte("ERROR: [youtube] abc: Premieres in 10 hours"), want: true},
+		{name: "private video", stderr: []byte("ERROR: [youtube] abc: Private video"), want: false},
+		{name: "empty", stderr: []byte(""), want: false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := IsLiveFromOutput(tt.output); got != tt.want {
-				t.Errorf("IsLiveFromOutput() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestFirstHTTPSLineFromOutput(t *testing.T) {
-	tests := []struct {
-		name   string
-		output []byte
-		want   string
-	}{
-		{
-			name:   "single https line",
-			output: []byte("https://example.com/audio.mp3"),
-			want:   "https://example.com/audio.mp3",
-		},
-		{
-			name:   "https line among multiple lines",
-			output: []byte("some metadata\nhttps://example.com/audio.mp3\nmore data"),
-			want:   "https://example.com/audio.mp3",
-		},
-		{
-			name:   "returns first https line when multiple present",
-			output: []byte("https://first.com\nhttps://second.com"),
-			want:   "https://first.com",
-		},
-		{
-			name:   "no https line returns empty string",
-			output: []byte("http://example.com\nsome other line"),
-			want:   "",
-		},
-		{
-			name:   "empty output returns empty string",
-			output: []byte(""),
-			want:   "",
-		},
-		{
-			name:   "http prefix does not match",
-			output: []byte("http://example.com"),
-			want:   "",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := FirstHTTPSLineFromOutput(tt.output); got != tt.want {
-				t.Errorf("FirstHTTPSLineFromOutput() = %v, want %v", got, tt.want)
+			if got := IsUpcomingFromError(tt.stderr); got != tt.want {
+				t.Errorf("IsUpcomingFromError() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -1,26 +0,0 @@
-package download
-
-import (
-	"fmt"
-
-	"github.com/jo-hoe/video-to-podcast-service/internal/config"
-	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
-	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/twitch"
-	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/youtube"
-)
-
-const ErrIsVideoSupported = "this downloader is not responsible for this URL '%s'"
-
-func GetVideoDownloader(url string, cookiesConfig *config.Cookies, mediaConfig *config.Media, ytDlpConfig *config.YtDlp) (downloader.AudioDownloader, error) {
-	twitchAudioDownloader := twitch.NewTwitchAudioDownloader(cookiesConfig, mediaConfig)
-	if twitchAudioDownloader.IsVideoSupported(url) {
-		return twitchAudioDownloader, nil
-	}
-
-	youtubeAudioDownloader := youtube.NewYoutubeAudioDownloader(cookiesConfig, mediaConfig, ytDlpConfig)
-	if youtubeAudioDownloader.IsVideoSupported(url) {
-		return youtubeAudioDownloader, nil
-	}
-
-	return nil, fmt.Errorf(ErrIsVideoSupported, url)
-}
@@ -0,0 +1,161 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+)
+
+// GenericAudioDownloader downloads the audio of any URL yt-dlp supports whose host or URL pattern is configured for a site.
+type GenericAudioDownloader struct {
+	site        *config.Site
+	urlPatterns []*regexp.Regexp
+	runner      *downloader.YtDlpRunner
+	mediaConfig *config.Media
+}
+
+// NewGenericAudioDownloader creates a downloader for site. The arguments configured for the site are added to every yt-dlp call of runner.
+func NewGenericAudioDownloader(site *config.Site, runner *downloader.YtDlpRunner, mediaConfig *config.Media) *GenericAudioDownloader {
+	urlPatterns := make([]*regexp.Regexp, 0, len(site.URLPatterns))
+	for _, pattern := range site.URLPatterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			slog.Warn("ignoring invalid url pattern", "site", site.Name, "pattern", pattern, "err", err)
+			continue
+		}
+		urlPatterns = append(urlPatterns, compiled)
+	}
+	return &GenericAudioDownloader{
+		site:        site,
+		urlPatterns: urlPatterns,
+		runner:      runner.WithArgs(site.Args...),
+		mediaConfig: mediaConfig,
+	}
+}
+
+// IsVideoSupported reports whether the host of the URL is one of the site's hosts or a subdomain of them,
+// or whether the URL matches one of the site's URL patterns.
+func (g *GenericAudioDownloader) IsVideoSupported(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return false
+	}
+	for _, pattern := range g.urlPatterns {
+		if pattern.MatchString(rawURL) {
+			return true
+		}
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, siteHost := range g.site.Hosts {
+		siteHost = strings.ToLower(strings.TrimSpace(siteHost))
+		if host == siteHost || strings.HasSuffix(host, "."+siteHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeVideoURL returns the URL unchanged since URL schemes differ between sites.
+func (g *GenericAudioDownloader) NormalizeVideoURL(url string) string {
+	return url
+}
+
+func (g *GenericAudioDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	slog.Info("checking video availability", "site", g.site.Name, "url", url)
+	return g.runner.CheckAvailability(ctx, url)
+}
+
+// IsVideoList returns true since only yt-dlp can tell whether a URL of the site is a playlist.
+// URLs resolving to a single entry are listed as that entry.
+func (g *GenericAudioDownloader) IsVideoList(url string) bool {
+	return true
+}
+
+// ListIndividualVideoURLs returns individual video URLs for a given input URL.
+// For playlists, e.g. albums or channels, it returns the URLs of their entries restricted by the selection.
+// URLs yt-dlp resolves to a single entry are returned unchanged.
+func (g *GenericAudioDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	entries, err := g.runner.ListEntries(ctx, url, selection)
+	if err != nil {
+		slog.Error("error listing entries", "site", g.site.Name, "url", url, "err", err)
+		return nil, err
+	}
+
+	// the url of a single video is its media URL, not the page that was submitted
+	if len(entries) <= 1 {
+		return []string{url}, nil
+	}
+	return downloader.SelectEntries(entries, selection), nil
+}
+
+// GetVideoInfo returns the metadata of a single video reported by yt-dlp --dump-json.
+func (g *GenericAudioDownloader) GetVideoInfo(ctx context.Context, url string) (*downloader.VideoInfo, error) {
+	return g.runner.VideoInfo(ctx, url)
+}
+
+func (g *GenericAudioDownloader) Download(ctx context.Context, url string, targetPath string, progress downloader.ProgressFunc) (string, error) {
+	tempPath, err := os.MkdirTemp(g.mediaConfig.TempPath, "generic-download-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempPath); err != nil {
+			slog.Warn("error removing temp directory", "err", err)
+		}
+	}()
+
+	slog.Info("downloading", "site", g.site.Name, "url", url, "tempPath", tempPath)
+	filePaths, err := g.download(ctx, tempPath, url, progress)
+	if err != nil {
+		return "", err
+	}
+	if len(filePaths) == 0 {
+		return "", fmt.Errorf("no audio files downloaded for url %s", url)
+	}
+	filePath := filePaths[0]
+	slog.Info("done downloading file", "filePath", filePath)
+
+	return g.runner.TagAndMove(ctx, url, filePath, targetPath, progress)
+}
+
+func (g *GenericAudioDownloader) download(ctx context.Context, targetDirectory string, url string, progress downloader.ProgressFunc) ([]string, error) {
+	tempFilenameTemplate := fmt.Sprintf("%s%c%s", targetDirectory, os.PathSeparator, g.outputTemplate())
+
+	args := append([]string{"--no-playlist"}, metadataArgs(g.site.Metadata)...)
+	if err := g.runner.DownloadAudio(ctx, url, tempFilenameTemplate, progress, args...); err != nil {
+		return nil, err
+	}
+
+	return filemanagement.GetAudioFiles(targetDirectory)
+}
+
+// metadataArgs maps audio tags to yt-dlp templates. yt-dlp embeds fields prefixed with meta_ as tags of the same name.
+func metadataArgs(metadata map[string]string) []string {
+	tags := make([]string, 0, len(metadata))
+	for tag := range metadata {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	args := make([]string, 0, 2*len(tags))
+	for _, tag := range tags {
+		args = append(args, "--parse-metadata", fmt.Sprintf("%s:%%(meta_%s)s", metadata[tag], tag))
+	}
+	return args
+}
+
+func (g *GenericAudioDownloader) outputTemplate() string {
+	if g.site.OutputTemplate == "" {
+		return config.DefaultSiteOutputTemplate
+	}
+	return g.site.OutputTemplate
+}
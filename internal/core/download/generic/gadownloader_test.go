@@ -0,0 +1,79 @@
+package generic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+func TestGenericAudioDownloader_IsVideoSupported(t *testing.T) {
+	g := NewGenericAudioDownloader(&config.Site{Name: "talks", Hosts: []string{"vimeo.com", "media.ccc.de"}}, downloader.NewYtDlpRunner(nil, nil, nil, nil), nil)
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "host", url: "https://vimeo.com/76979871", want: true},
+		{name: "subdomain", url: "https://player.vimeo.com/video/76979871", want: true},
+		{name: "host with upper case and port", url: "https://MEDIA.CCC.DE:443/v/38c3-talk", want: true},
+		{name: "other host with the same suffix", url: "https://notvimeo.com/76979871", want: false},
+		{name: "host in path", url: "https://example.com/vimeo.com/76979871", want: false},
+		{name: "unsupported scheme", url: "ftp://vimeo.com/76979871", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.IsVideoSupported(tt.url); got != tt.want {
+				t.Errorf("GenericAudioDownloader.IsVideoSupported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericAudioDownloader_IsVideoSupported_URLPatterns(t *testing.T) {
+	g := NewGenericAudioDownloader(&config.Site{Name: "talks", URLPatterns: []string{`^https://example\.com/talks/`}}, downloader.NewYtDlpRunner(nil, nil, nil, nil), nil)
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "matching url", url: "https://example.com/talks/keynote", want: true},
+		{name: "same host other path", url: "https://example.com/blog/keynote", want: false},
+		{name: "unsupported scheme", url: "ftp://example.com/talks/keynote", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.IsVideoSupported(tt.url); got != tt.want {
+				t.Errorf("GenericAudioDownloader.IsVideoSupported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericAudioDownloader_SiteArgs(t *testing.T) {
+	g := NewGenericAudioDownloader(&config.Site{Name: "talks", Hosts: []string{"example.com"}, Args: []string{"--referer", "https://example.com"}}, downloader.NewYtDlpRunner(nil, nil, nil, nil), nil)
+
+	got := g.runner.BaseArgs(true)
+
+	want := []string{"--simulate", "--quiet", "--referer", "https://example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BaseArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestMetadataArgs(t *testing.T) {
+	got := metadataArgs(map[string]string{"artist": "%(uploader)s", "album": "%(playlist_title)s"})
+
+	want := []string{
+		"--parse-metadata", "%(playlist_title)s:%(meta_album)s",
+		"--parse-metadata", "%(uploader)s:%(meta_artist)s",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metadataArgs() = %v, want %v", got, want)
+	}
+}
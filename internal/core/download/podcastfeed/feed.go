@@ -0,0 +1,286 @@
+package podcastfeed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pubDateLayouts are the date formats found in RSS feeds, RFC 822 with and without a day of the week and with a
+// numeric or named time zone.
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	time.RFC3339,
+}
+
+// podcastFeed is an RSS or Atom feed reduced to the fields episodes are imported with.
+type podcastFeed struct {
+	Title    string
+	Author   string
+	Image    string
+	Episodes []*episode
+}
+
+// episode is a feed item with an audio enclosure.
+type episode struct {
+	ID              string // guid of the item, the enclosure URL if the item has none
+	Title           string
+	Description     string
+	Author          string
+	Image           string
+	AudioURL        string
+	AudioType       string
+	DurationSeconds float64
+	PublishedAt     time.Time // zero if the feed does not date the item
+}
+
+// xmlImage matches both <image><url>...</url></image> of RSS and <itunes:image href="..."/>.
+type xmlImage struct {
+	Href string `xml:"href,attr"`
+	URL  string `xml:"url"`
+}
+
+type rssEnclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// rssItem is an RSS item. encoding/xml assigns an element to the first matching field, so the namespaced
+// iTunes and content fields come first to keep their elements out of the plain RSS fields of the same name.
+type rssItem struct {
+	ItunesAuthor   string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	ItunesSummary  string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	ItunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Content        string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Title          string         `xml:"title"`
+	Description    string         `xml:"description"`
+	Author         string         `xml:"author"`
+	GUID           string         `xml:"guid"`
+	PubDate        string         `xml:"pubDate"`
+	Images         []xmlImage     `xml:"image"`
+	Enclosures     []rssEnclosure `xml:"enclosure"`
+}
+
+type rssChannel struct {
+	ItunesAuthor string     `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	Title        string     `xml:"title"`
+	Images       []xmlImage `xml:"image"`
+	Items        []rssItem  `xml:"item"`
+}
+
+type rssDocument struct {
+	Channel rssChannel `xml:"channel"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ItunesDuration string       `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ID             string       `xml:"id"`
+	Title          string       `xml:"title"`
+	Summary        string       `xml:"summary"`
+	Content        string       `xml:"content"`
+	Published      string       `xml:"published"`
+	Updated        string       `xml:"updated"`
+	Authors        []atomPerson `xml:"author"`
+	Links          []atomLink   `xml:"link"`
+}
+
+type atomDocument struct {
+	Title   string       `xml:"title"`
+	Logo    string       `xml:"logo"`
+	Icon    string       `xml:"icon"`
+	Authors []atomPerson `xml:"author"`
+	Entries []atomEntry  `xml:"entry"`
+}
+
+// parseFeed parses an RSS 2.0 or Atom feed. Items without audio or video enclosure are skipped.
+func parseFeed(data []byte) (*podcastFeed, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	switch root.XMLName.Local {
+	case "rss":
+		var document rssDocument
+		if err := xml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		return document.Channel.podcastFeed(), nil
+	case "feed":
+		var document atomDocument
+		if err := xml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+		}
+		return document.podcastFeed(), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed format <%s>, expected RSS or Atom", root.XMLName.Local)
+	}
+}
+
+func (channel *rssChannel) podcastFeed() *podcastFeed {
+	feed := &podcastFeed{
+		Title:    strings.TrimSpace(channel.Title),
+		Author:   strings.TrimSpace(channel.ItunesAuthor),
+		Image:    firstImage(channel.Images),
+		Episodes: make([]*episode, 0, len(channel.Items)),
+	}
+	for _, item := range channel.Items {
+		enclosure, found := mediaEnclosure(item.Enclosures)
+		if !found {
+			continue
+		}
+		feedEpisode := &episode{
+			ID:              strings.TrimSpace(item.GUID),
+			Title:           strings.TrimSpace(item.Title),
+			Description:     strings.TrimSpace(firstNonEmpty(item.Content, item.ItunesSummary, item.Description)),
+			Author:          strings.TrimSpace(firstNonEmpty(item.ItunesAuthor, item.Author, feed.Author, feed.Title)),
+			Image:           firstNonEmpty(firstImage(item.Images), feed.Image),
+			AudioURL:        strings.TrimSpace(enclosure.URL),
+			AudioType:       enclosure.Type,
+			DurationSeconds: parseDuration(item.ItunesDuration),
+			PublishedAt:     parseTime(item.PubDate),
+		}
+		feed.Episodes = append(feed.Episodes, feedEpisode.withDefaults())
+	}
+	return feed
+}
+
+func (document *atomDocument) podcastFeed() *podcastFeed {
+	feed := &podcastFeed{
+		Title:    strings.TrimSpace(document.Title),
+		Author:   firstAuthor(document.Authors),
+		Image:    firstNonEmpty(document.Logo, document.Icon),
+		Episodes: make([]*episode, 0, len(document.Entries)),
+	}
+	for _, entry := range document.Entries {
+		enclosures := make([]rssEnclosure, 0, len(entry.Links))
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" {
+				enclosures = append(enclosures, rssEnclosure{URL: link.Href, Type: link.Type})
+			}
+		}
+		enclosure, found := mediaEnclosure(enclosures)
+		if !found {
+			continue
+		}
+		feedEpisode := &episode{
+			ID:              strings.TrimSpace(entry.ID),
+			Title:           strings.TrimSpace(entry.Title),
+			Description:     strings.TrimSpace(firstNonEmpty(entry.Content, entry.Summary)),
+			Author:          firstNonEmpty(firstAuthor(entry.Authors), feed.Author, feed.Title),
+			Image:           feed.Image,
+			AudioURL:        strings.TrimSpace(enclosure.URL),
+			AudioType:       enclosure.Type,
+			DurationSeconds: parseDuration(entry.ItunesDuration),
+			PublishedAt:     parseTime(firstNonEmpty(entry.Published, entry.Updated)),
+		}
+		feed.Episodes = append(feed.Episodes, feedEpisode.withDefaults())
+	}
+	return feed
+}
+
+// withDefaults identifies episodes without guid by their enclosure and titles untitled episodes by their date.
+func (e *episode) withDefaults() *episode {
+	if e.ID == "" {
+		e.ID = e.AudioURL
+	}
+	if e.Title == "" && !e.PublishedAt.IsZero() {
+		e.Title = e.PublishedAt.Format(time.DateOnly)
+	}
+	return e
+}
+
+// episode returns the episode with the given ID or nil if the feed does not contain it.
+func (feed *podcastFeed) episode(id string) *episode {
+	for _, feedEpisode := range feed.Episodes {
+		if feedEpisode.ID == id {
+			return feedEpisode
+		}
+	}
+	return nil
+}
+
+// mediaEnclosure returns the first audio or video enclosure. Enclosures without type are assumed to be media.
+func mediaEnclosure(enclosures []rssEnclosure) (rssEnclosure, bool) {
+	for _, enclosure := range enclosures {
+		mediaType := strings.ToLower(enclosure.Type)
+		if strings.TrimSpace(enclosure.URL) != "" && (mediaType == "" || strings.HasPrefix(mediaType, "audio/") || strings.HasPrefix(mediaType, "video/")) {
+			return enclosure, true
+		}
+	}
+	return rssEnclosure{}, false
+}
+
+func firstImage(images []xmlImage) string {
+	for _, image := range images {
+		if url := strings.TrimSpace(firstNonEmpty(image.Href, image.URL)); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+func firstAuthor(authors []atomPerson) string {
+	for _, author := range authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseDuration parses itunes:duration values given in seconds, MM:SS or HH:MM:SS. It returns 0 if the value is invalid.
+func parseDuration(value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	seconds := 0.0
+	for _, part := range strings.Split(value, ":") {
+		number, err := strconv.ParseFloat(part, 64)
+		if err != nil || number < 0 {
+			return 0
+		}
+		seconds = seconds*60 + number
+	}
+	return seconds
+}
+
+// parseTime parses RSS and Atom dates. It returns the zero time if the value is invalid.
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range pubDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC()
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,138 @@
+package podcastfeed
+
+import (
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Engineering Talk</title>
+    <itunes:author>Engineering Team</itunes:author>
+    <itunes:image href="https://example.com/show.png"/>
+    <image><url>https://example.com/rss-show.png</url></image>
+    <item>
+      <title>Episode 2</title>
+      <description>Plain description</description>
+      <content:encoded><![CDATA[<p>Rich description</p>]]></content:encoded>
+      <guid isPermaLink="false">episode-2</guid>
+      <pubDate>Tue, 05 Mar 2024 08:30:00 +0000</pubDate>
+      <itunes:duration>1:02:03</itunes:duration>
+      <itunes:image href="https://example.com/episode-2.png"/>
+      <enclosure url="https://cdn.example.com/episode-2.mp3" length="1024" type="audio/mpeg"/>
+    </item>
+    <item>
+      <title>Episode 1</title>
+      <description>First episode</description>
+      <pubDate>Mon, 4 Mar 2024 08:30:00 GMT</pubDate>
+      <itunes:duration>754</itunes:duration>
+      <enclosure url="https://cdn.example.com/episode-1.m4a" type="audio/x-m4a"/>
+    </item>
+    <item>
+      <title>Blog post without audio</title>
+      <enclosure url="https://cdn.example.com/cover.jpg" type="image/jpeg"/>
+    </item>
+  </channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Cast</title>
+  <author><name>Atom Author</name></author>
+  <logo>https://example.com/atom.png</logo>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title>First entry</title>
+    <summary>Summary of the entry</summary>
+    <published>2024-03-01T10:00:00Z</published>
+    <link rel="alternate" href="https://example.com/entries/1"/>
+    <link rel="enclosure" href="https://cdn.example.com/entry-1.mp3" type="audio/mpeg"/>
+  </entry>
+</feed>`
+
+func TestParseFeed_RSS(t *testing.T) {
+	feed, err := parseFeed([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if feed.Title != "Engineering Talk" || feed.Author != "Engineering Team" || feed.Image != "https://example.com/show.png" {
+		t.Errorf("unexpected feed %+v", feed)
+	}
+	if len(feed.Episodes) != 2 {
+		t.Fatalf("expected 2 episodes with audio, got %d", len(feed.Episodes))
+	}
+
+	latest := feed.Episodes[0]
+	if latest.ID != "episode-2" || latest.Title != "Episode 2" || latest.Author != "Engineering Team" {
+		t.Errorf("unexpected episode %+v", latest)
+	}
+	if latest.Description != "<p>Rich description</p>" {
+		t.Errorf("expected content:encoded as description, got %q", latest.Description)
+	}
+	if latest.Image != "https://example.com/episode-2.png" {
+		t.Errorf("expected the episode image, got %q", latest.Image)
+	}
+	if latest.DurationSeconds != 3723 {
+		t.Errorf("expected a duration of 3723s, got %v", latest.DurationSeconds)
+	}
+	if !latest.PublishedAt.Equal(time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected publication time %v", latest.PublishedAt)
+	}
+
+	first := feed.Episodes[1]
+	if first.ID != "https://cdn.example.com/episode-1.m4a" {
+		t.Errorf("expected episodes without guid to be identified by their enclosure, got %q", first.ID)
+	}
+	if first.Image != "https://example.com/show.png" || first.DurationSeconds != 754 || first.PublishedAt.IsZero() {
+		t.Errorf("unexpected episode %+v", first)
+	}
+}
+
+func TestParseFeed_Atom(t *testing.T) {
+	feed, err := parseFeed([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if feed.Title != "Atom Cast" || feed.Image != "https://example.com/atom.png" || len(feed.Episodes) != 1 {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+	entry := feed.Episodes[0]
+	if entry.ID != "urn:uuid:1" || entry.AudioURL != "https://cdn.example.com/entry-1.mp3" || entry.Author != "Atom Author" || entry.Description != "Summary of the entry" {
+		t.Errorf("unexpected episode %+v", entry)
+	}
+	if !entry.PublishedAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected publication time %v", entry.PublishedAt)
+	}
+}
+
+func TestParseFeed_UnsupportedDocument(t *testing.T) {
+	if _, err := parseFeed([]byte(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("expected an error for HTML documents")
+	}
+	if _, err := parseFeed([]byte(`not xml`)); err == nil {
+		t.Error("expected an error for invalid documents")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{value: "754", want: 754},
+		{value: "12:34", want: 754},
+		{value: "01:02:03", want: 3723},
+		{value: "", want: 0},
+		{value: "about an hour", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseDuration(tt.value); got != tt.want {
+				t.Errorf("parseDuration(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
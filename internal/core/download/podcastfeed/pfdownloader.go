@@ -0,0 +1,332 @@
+package podcastfeed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/convertvideo"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/direct"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+)
+
+// episodeParameter is the fragment parameter that identifies an episode of a feed, e.g. https://example.com/feed.xml#item=<guid>.
+// Fragments are never sent to the server, so episode URLs fetch the feed they belong to.
+const episodeParameter = "item"
+
+// feedCacheDuration is how long a fetched feed is reused, so that listing a feed and checking its episodes fetches it once.
+const feedCacheDuration = time.Minute
+
+// maxFeedSize limits the size of a fetched feed.
+const maxFeedSize = 32 << 20
+
+// feedPathPattern matches the paths of typical feed URLs, e.g. /podcast.rss, /feed.xml or /show/rss.
+var feedPathPattern = regexp.MustCompile(`(?i)(\.(rss|xml|atom)|/(rss|feed|atom))/?$`)
+
+// PodcastFeedAudioDownloader mirrors the episodes of external RSS and Atom podcast feeds.
+// Feed URLs are listed into one URL per episode; episodes are added to a feed named like the mirrored feed.
+type PodcastFeedAudioDownloader struct {
+	mediaConfig *config.Media
+	client      *http.Client
+
+	cacheMutex sync.Mutex
+	cache      map[string]cachedFeed
+}
+
+type cachedFeed struct {
+	feed      *podcastFeed
+	fetchedAt time.Time
+}
+
+func NewPodcastFeedAudioDownloader(mediaConfig *config.Media) *PodcastFeedAudioDownloader {
+	return &PodcastFeedAudioDownloader{
+		mediaConfig: mediaConfig,
+		client:      http.DefaultClient,
+		cache:       make(map[string]cachedFeed),
+	}
+}
+
+// IsVideoSupported reports whether the URL looks like a feed by its path, starts with a feeds. host or is served from a configured host.
+// Links to media files are left to the direct downloader, feed hosts often serve the enclosures as well.
+func (p *PodcastFeedAudioDownloader) IsVideoSupported(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return false
+	}
+	if direct.IsMediaFilePath(parsed.Path) {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if feedPathPattern.MatchString(parsed.Path) || strings.HasPrefix(host, "feeds.") {
+		return true
+	}
+	if p.mediaConfig == nil {
+		return false
+	}
+	for _, feedHost := range p.mediaConfig.FeedMirror.Hosts {
+		feedHost = strings.ToLower(strings.TrimSpace(feedHost))
+		if host == feedHost || strings.HasSuffix(host, "."+feedHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeVideoURL returns the URL unchanged since the fragment identifies the episode.
+func (p *PodcastFeedAudioDownloader) NormalizeVideoURL(url string) string {
+	return url
+}
+
+// CheckVideoAvailability fetches the feed and, for episode URLs, checks that the feed still contains the episode.
+func (p *PodcastFeedAudioDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	slog.Info("checking feed availability", "url", url)
+	_, _, err := p.resolve(ctx, url)
+	return err
+}
+
+// IsVideoList reports whether the URL is a feed rather than one of its episodes.
+func (p *PodcastFeedAudioDownloader) IsVideoList(url string) bool {
+	_, episodeID := splitEpisodeURL(url)
+	return episodeID == ""
+}
+
+// ListIndividualVideoURLs returns one URL per episode of the feed, newest first, restricted by the selection.
+// Item ranges are not supported for feeds. Episode URLs are returned unchanged.
+func (p *PodcastFeedAudioDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	feedURL, episodeID := splitEpisodeURL(url)
+	if episodeID != "" {
+		return []string{url}, nil
+	}
+	if selection.Items != "" {
+		slog.Warn("item ranges are not supported for podcast feeds, listing all episodes", "url", url, "items", selection.Items)
+	}
+
+	feed, err := p.getFeed(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]downloader.PlaylistEntry, 0, len(feed.Episodes))
+	for _, episode := range feed.Episodes {
+		entries = append(entries, downloader.PlaylistEntry{URL: episodeURL(feedURL, episode.ID), UploadedAt: episode.PublishedAt})
+	}
+	return downloader.SelectEntries(entries, selection), nil
+}
+
+// GetVideoInfo returns the metadata of an episode as announced by the feed.
+func (p *PodcastFeedAudioDownloader) GetVideoInfo(ctx context.Context, url string) (*downloader.VideoInfo, error) {
+	feed, episode, err := p.resolve(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if episode == nil {
+		return nil, fmt.Errorf("%s is a feed, not an episode", url)
+	}
+	return &downloader.VideoInfo{
+		URL:             url,
+		ID:              episode.ID,
+		Title:           episode.Title,
+		Channel:         feed.Title,
+		DurationSeconds: episode.DurationSeconds,
+		Thumbnail:       episode.Image,
+		UploadedAt:      episode.PublishedAt,
+	}, nil
+}
+
+func (p *PodcastFeedAudioDownloader) Download(ctx context.Context, url string, targetPath string, progress downloader.ProgressFunc) (string, error) {
+	feed, episode, err := p.resolve(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	if episode == nil {
+		return "", fmt.Errorf("%w: %s is a feed, not an episode", downloader.ErrPermanentFailure, url)
+	}
+
+	tempPath, err := os.MkdirTemp(p.mediaConfig.TempPath, "feed-download-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempPath); err != nil {
+			slog.Warn("error removing temp directory", "err", err)
+		}
+	}()
+
+	slog.Info("downloading episode", "url", url, "audioURL", episode.AudioURL, "tempPath", tempPath)
+	mediaFilePath, _, err := direct.NewDirectAudioDownloader(p.mediaConfig).Fetch(ctx, tempPath, episode.AudioURL, progress)
+	if err != nil {
+		return "", err
+	}
+
+	// the directory of the audio file is the feed it is added to
+	feedPath := filepath.Join(tempPath, filemanagement.SanitizeFileName(feed.Title, "podcast"))
+	if err := os.MkdirAll(feedPath, os.ModePerm); err != nil {
+		return "", err
+	}
+	audioFormat := filemanagement.AudioFormatByName(p.mediaConfig.Audio.Format)
+	filePath := filepath.Join(feedPath, filemanagement.SanitizeFileName(episode.Title, "episode")+"_"+episodeFileID(url)+audioFormat.Extension)
+	if isInFormat(mediaFilePath, episode, audioFormat) && !p.mediaConfig.FeedMirror.ReprocessAudio {
+		err = os.Rename(mediaFilePath, filePath)
+	} else {
+		slog.Info("converting episode", "mediaFilePath", mediaFilePath, "format", audioFormat.Name)
+		err = convertvideo.ConvertVideoToAudio(mediaFilePath, filePath, &p.mediaConfig.Audio)
+	}
+	if err != nil {
+		return "", err
+	}
+	slog.Info("done downloading file", "filePath", filePath)
+
+	progress.Report(downloader.StageTagging, 100)
+	slog.Info("setting metadata", "filePath", filePath)
+	if err = setMetadata(filePath, url, episode); err != nil {
+		return "", err
+	}
+	slog.Info("set metadata", "filePath", filePath)
+
+	progress.Report(downloader.StageMoving, 100)
+	slog.Info("moving file to target folder")
+	result, err := filemanagement.MoveToTarget(filePath, targetPath)
+	if err != nil {
+		return "", err
+	}
+	slog.Info("completed moving file", "targetPath", result)
+
+	return result, nil
+}
+
+// isInFormat reports whether the downloaded enclosure is in the audio format by its extension or,
+// for URLs without extension, its announced type.
+func isInFormat(mediaFilePath string, episode *episode, audioFormat filemanagement.AudioFormat) bool {
+	if filepath.Ext(mediaFilePath) == "" {
+		return audioFormat.HasMIMEType(episode.AudioType)
+	}
+	return strings.EqualFold(filepath.Ext(mediaFilePath), audioFormat.Extension)
+}
+
+// setMetadata replaces the tags of the enclosure with the episode metadata of the feed.
+func setMetadata(fullFilePath string, episodeURL string, episode *episode) error {
+	metadata, err := downloader.GetTags(fullFilePath)
+	if err != nil {
+		return err
+	}
+
+	if episode.Title != "" {
+		metadata[downloader.Title] = episode.Title
+	}
+	if episode.Author != "" {
+		metadata[downloader.Artist] = episode.Author
+	}
+	metadata[downloader.PodcastDescriptionTag] = strings.ReplaceAll(episode.Description, "\n", "<br>")
+	metadata[downloader.ThumbnailUrlTag] = episode.Image
+	if !episode.PublishedAt.IsZero() {
+		metadata["date"] = episode.PublishedAt.Format("2006-01-02T15:04:05")
+	}
+	metadata[downloader.DateTag] = metadata["date"]
+	metadata[downloader.VideoDownloadLink] = episodeURL
+
+	return downloader.SetTags(fullFilePath, metadata)
+}
+
+// resolve returns the feed of the URL and, for episode URLs, the episode. Episodes that were removed from the feed are permanent failures.
+func (p *PodcastFeedAudioDownloader) resolve(ctx context.Context, rawURL string) (*podcastFeed, *episode, error) {
+	feedURL, episodeID := splitEpisodeURL(rawURL)
+	feed, err := p.getFeed(ctx, feedURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if episodeID == "" {
+		return feed, nil, nil
+	}
+	episode := feed.episode(episodeID)
+	if episode == nil {
+		return nil, nil, fmt.Errorf("%w: episode %s is not in feed %s anymore", downloader.ErrPermanentFailure, episodeID, feedURL)
+	}
+	return feed, episode, nil
+}
+
+// getFeed fetches and parses the feed, reusing feeds fetched within feedCacheDuration.
+func (p *PodcastFeedAudioDownloader) getFeed(ctx context.Context, feedURL string) (*podcastFeed, error) {
+	p.cacheMutex.Lock()
+	defer p.cacheMutex.Unlock()
+	if cached, ok := p.cache[feedURL]; ok && time.Since(cached.fetchedAt) < feedCacheDuration {
+		return cached.feed, nil
+	}
+
+	feed, err := p.fetchFeed(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	p.cache[feedURL] = cachedFeed{feed: feed, fetchedAt: time.Now()}
+	return feed, nil
+}
+
+func (p *PodcastFeedAudioDownloader) fetchFeed(ctx context.Context, feedURL string) (*podcastFeed, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed %s: %w", feedURL, err)
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			slog.Warn("error closing response body", "err", err)
+		}
+	}()
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%w: feed %s returned HTTP %d", downloader.ErrPermanentFailure, feedURL, response.StatusCode)
+	case response.StatusCode < 200 || response.StatusCode >= 300:
+		return nil, fmt.Errorf("feed %s returned HTTP %d", feedURL, response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed %s: %w", feedURL, err)
+	}
+	feed, err := parseFeed(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", feedURL, err)
+	}
+	if feed.Title == "" {
+		feed.Title = request.URL.Hostname()
+	}
+	return feed, nil
+}
+
+// splitEpisodeURL returns the feed URL and the episode ID of an episode URL. The ID is empty for feed URLs.
+func splitEpisodeURL(rawURL string) (feedURL string, episodeID string) {
+	feedURL, fragment, found := strings.Cut(rawURL, "#")
+	if !found {
+		return rawURL, ""
+	}
+	values, err := url.ParseQuery(fragment)
+	if err != nil {
+		return feedURL, ""
+	}
+	return feedURL, values.Get(episodeParameter)
+}
+
+// episodeURL returns the URL of an episode of the feed.
+func episodeURL(feedURL string, episodeID string) string {
+	return feedURL + "#" + url.Values{episodeParameter: {episodeID}}.Encode()
+}
+
+// episodeFileID identifies the file by its episode URL, so that episodes with the same title do not overwrite each other.
+func episodeFileID(episodeURL string) string {
+	hash := sha256.Sum256([]byte(episodeURL))
+	return hex.EncodeToString(hash[:6])
+}
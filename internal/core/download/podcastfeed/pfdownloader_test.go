@@ -0,0 +1,142 @@
+package podcastfeed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+)
+
+func newFeedServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/podcast.rss", func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssFeed))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPodcastFeedAudioDownloader_IsVideoSupported(t *testing.T) {
+	p := NewPodcastFeedAudioDownloader(&config.Media{FeedMirror: config.FeedMirror{Hosts: []string{"podcasts.example.net"}}})
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "rss file", url: "https://example.com/podcast.rss", want: true},
+		{name: "xml file", url: "https://example.com/shows/feed.xml", want: true},
+		{name: "rss path", url: "https://anchor.fm/s/abc/podcast/rss", want: true},
+		{name: "feed path", url: "https://blog.example.com/feed/", want: true},
+		{name: "feeds host", url: "https://feeds.megaphone.fm/ABC123", want: true},
+		{name: "configured host", url: "https://cdn.podcasts.example.net/show/42", want: true},
+		{name: "episode url", url: "https://example.com/podcast.rss#item=episode-2", want: true},
+		{name: "web page", url: "https://example.com/podcast", want: false},
+		{name: "audio file", url: "https://example.com/episode.mp3", want: false},
+		{name: "audio file on feeds host", url: "https://feeds.soundcloud.com/stream/123.mp3", want: false},
+		{name: "audio file on configured host", url: "https://podcasts.example.net/show/42.m4a", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.IsVideoSupported(tt.url); got != tt.want {
+				t.Errorf("PodcastFeedAudioDownloader.IsVideoSupported(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodcastFeedAudioDownloader_ListIndividualVideoURLs(t *testing.T) {
+	requests := 0
+	server := newFeedServer(t, &requests)
+	feedURL := server.URL + "/podcast.rss"
+	p := NewPodcastFeedAudioDownloader(&config.Media{})
+
+	urls, err := p.ListIndividualVideoURLs(context.Background(), feedURL, downloader.Selection{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{feedURL + "#item=episode-2", feedURL + "#item=https%3A%2F%2Fcdn.example.com%2Fepisode-1.m4a"}
+	if len(urls) != len(want) || urls[0] != want[0] || urls[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, urls)
+	}
+
+	urls, err = p.ListIndividualVideoURLs(context.Background(), feedURL, downloader.Selection{UploadedAfter: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != want[0] {
+		t.Errorf("expected only the latest episode, got %v", urls)
+	}
+
+	urls, err = p.ListIndividualVideoURLs(context.Background(), want[1], downloader.Selection{})
+	if err != nil || len(urls) != 1 || urls[0] != want[1] {
+		t.Errorf("expected episode urls to be returned unchanged, got %v (%v)", urls, err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the feed to be fetched once, got %d requests", requests)
+	}
+}
+
+func TestPodcastFeedAudioDownloader_GetVideoInfo(t *testing.T) {
+	requests := 0
+	server := newFeedServer(t, &requests)
+	p := NewPodcastFeedAudioDownloader(&config.Media{})
+
+	info, err := p.GetVideoInfo(context.Background(), server.URL+"/podcast.rss#item=episode-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Title != "Episode 2" || info.Channel != "Engineering Talk" || info.DurationSeconds != 3723 || info.Thumbnail != "https://example.com/episode-2.png" {
+		t.Errorf("unexpected video info %+v", info)
+	}
+}
+
+func TestPodcastFeedAudioDownloader_CheckVideoAvailability(t *testing.T) {
+	requests := 0
+	server := newFeedServer(t, &requests)
+	p := NewPodcastFeedAudioDownloader(&config.Media{})
+
+	if err := p.CheckVideoAvailability(context.Background(), server.URL+"/podcast.rss#item=episode-2"); err != nil {
+		t.Errorf("expected episode to be available, got %v", err)
+	}
+	err := p.CheckVideoAvailability(context.Background(), server.URL+"/podcast.rss#item=removed")
+	if !errors.Is(err, downloader.ErrPermanentFailure) {
+		t.Errorf("expected removed episodes to fail permanently, got %v", err)
+	}
+	err = p.CheckVideoAvailability(context.Background(), server.URL+"/missing.rss")
+	if !errors.Is(err, downloader.ErrPermanentFailure) {
+		t.Errorf("expected missing feeds to fail permanently, got %v", err)
+	}
+}
+
+func TestIsInFormat(t *testing.T) {
+	opus := filemanagement.AudioFormatByName(config.AudioFormatOpus)
+	tests := []struct {
+		name          string
+		mediaFilePath string
+		audioType     string
+		want          bool
+	}{
+		{name: "same extension", mediaFilePath: "/tmp/episode.OPUS", audioType: "audio/mpeg", want: true},
+		{name: "other extension", mediaFilePath: "/tmp/episode.ogg", audioType: "audio/opus", want: false},
+		{name: "announced type without extension", mediaFilePath: "/tmp/episode", audioType: "audio/opus", want: true},
+		{name: "other announced type without extension", mediaFilePath: "/tmp/episode", audioType: "audio/mpeg", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isInFormat(tt.mediaFilePath, &episode{AudioType: tt.audioType}, opus); got != tt.want {
+				t.Errorf("isInFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
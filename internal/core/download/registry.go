@@ -0,0 +1,139 @@
+package download
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/direct"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/generic"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/podcastfeed"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/twitch"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/youtube"
+)
+
+const ErrIsVideoSupported = "this downloader is not responsible for this URL '%s'"
+
+// Names of the built-in downloaders as used in the downloaders configuration, see config.BuiltInDownloaders.
+const (
+	TwitchDownloader      = "twitch"
+	YoutubeDownloader     = "youtube"
+	PodcastFeedDownloader = "podcastfeed"
+	DirectDownloader      = "direct"
+)
+
+// Matcher reports whether a downloader is responsible for a URL.
+type Matcher func(url string) bool
+
+// Registration is a downloader known to a registry.
+type Registration struct {
+	Name       string
+	Matches    Matcher
+	Downloader downloader.AudioDownloader
+}
+
+// Registry selects the downloader responsible for a URL. Registrations are asked in order, the first match wins.
+type Registry struct {
+	registrations []Registration
+}
+
+// NewRegistry registers the built-in downloaders followed by the configured yt-dlp sites, so that sites cannot take
+// over the dedicated downloaders, and applies the precedence and disabled downloaders of downloadersConfig.
+// yt-dlp is run with executor, the yt-dlp binary if executor is nil.
+func NewRegistry(executor downloader.Executor, cookiesConfig *config.Cookies, mediaConfig *config.Media, ytDlpConfig *config.YtDlp, downloadersConfig *config.Downloaders) *Registry {
+	registry := &Registry{}
+	var audioConfig *config.Audio
+	if mediaConfig != nil {
+		audioConfig = &mediaConfig.Audio
+	}
+	runner := downloader.NewYtDlpRunner(executor, cookiesConfig, ytDlpConfig, audioConfig)
+
+	twitchAudioDownloader := twitch.NewTwitchAudioDownloader(runner, mediaConfig)
+	registry.Register(TwitchDownloader, twitchAudioDownloader.IsVideoSupported, twitchAudioDownloader)
+	var sponsorBlockConfig *config.SponsorBlock
+	if ytDlpConfig != nil {
+		sponsorBlockConfig = &ytDlpConfig.SponsorBlock
+	}
+	youtubeAudioDownloader := youtube.NewYoutubeAudioDownloader(runner, mediaConfig, sponsorBlockConfig)
+	registry.Register(YoutubeDownloader, youtubeAudioDownloader.IsVideoSupported, youtubeAudioDownloader)
+	podcastFeedAudioDownloader := podcastfeed.NewPodcastFeedAudioDownloader(mediaConfig)
+	registry.Register(PodcastFeedDownloader, podcastFeedAudioDownloader.IsVideoSupported, podcastFeedAudioDownloader)
+	directAudioDownloader := direct.NewDirectAudioDownloader(mediaConfig)
+	registry.Register(DirectDownloader, directAudioDownloader.IsVideoSupported, directAudioDownloader)
+
+	if ytDlpConfig != nil {
+		for i := range ytDlpConfig.Sites {
+			genericAudioDownloader := generic.NewGenericAudioDownloader(&ytDlpConfig.Sites[i], runner, mediaConfig)
+			registry.Register(ytDlpConfig.Sites[i].Name, genericAudioDownloader.IsVideoSupported, genericAudioDownloader)
+		}
+	}
+
+	if downloadersConfig != nil {
+		registry.reorder(downloadersConfig.Order)
+		for _, name := range downloadersConfig.Disabled {
+			registry.Unregister(name)
+		}
+	}
+	return registry
+}
+
+// Register adds a downloader with the lowest precedence. Names are unique, a registration with a known name is ignored.
+func (r *Registry) Register(name string, matcher Matcher, audioDownloader downloader.AudioDownloader) {
+	if r.index(name) >= 0 {
+		slog.Warn("ignoring downloader with duplicate name", "name", name)
+		return
+	}
+	r.registrations = append(r.registrations, Registration{Name: name, Matches: matcher, Downloader: audioDownloader})
+}
+
+// Unregister removes the downloader with the given name, e.g. to disable it.
+func (r *Registry) Unregister(name string) {
+	index := r.index(name)
+	if index < 0 {
+		slog.Warn("cannot disable unknown downloader", "name", name)
+		return
+	}
+	r.registrations = slices.Delete(r.registrations, index, index+1)
+}
+
+// Names returns the names of the registered downloaders by precedence.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.registrations))
+	for _, registration := range r.registrations {
+		names = append(names, registration.Name)
+	}
+	return names
+}
+
+// GetVideoDownloader returns the downloader with the highest precedence that is responsible for the URL.
+func (r *Registry) GetVideoDownloader(url string) (downloader.AudioDownloader, error) {
+	for _, registration := range r.registrations {
+		if registration.Matches(url) {
+			return registration.Downloader, nil
+		}
+	}
+	return nil, fmt.Errorf(ErrIsVideoSupported, url)
+}
+
+// reorder moves the named downloaders to the front in the given order. Downloaders that are not named keep their relative order.
+func (r *Registry) reorder(order []string) {
+	ordered := make([]Registration, 0, len(r.registrations))
+	for _, name := range order {
+		index := r.index(name)
+		if index < 0 {
+			slog.Warn("cannot order unknown downloader", "name", name)
+			continue
+		}
+		ordered = append(ordered, r.registrations[index])
+		r.registrations = slices.Delete(r.registrations, index, index+1)
+	}
+	r.registrations = append(ordered, r.registrations...)
+}
+
+func (r *Registry) index(name string) int {
+	return slices.IndexFunc(r.registrations, func(registration Registration) bool {
+		return registration.Name == name
+	})
+}
@@ -0,0 +1,157 @@
+package download
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/direct"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/generic"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/podcastfeed"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/twitch"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/youtube"
+)
+
+func TestGetVideoDownloader_ReturnsYouTubeDownloader(t *testing.T) {
+	url := "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+
+	downloader, err := NewRegistry(nil, nil, nil, nil, nil).GetVideoDownloader(url)
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*youtube.YoutubeAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *youtube.YoutubeAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestGetVideoDownloader_ReturnsTwitchDownloader(t *testing.T) {
+	url := "https://www.twitch.tv/videos/2345678901"
+
+	downloader, err := NewRegistry(nil, nil, nil, nil, nil).GetVideoDownloader(url)
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*twitch.TwitchAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *twitch.TwitchAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestGetVideoDownloader_UnsupportedURL_ReturnsError(t *testing.T) {
+	url := "https://unsupport.com/123456789"
+
+	downloader, err := NewRegistry(nil, nil, nil, nil, nil).GetVideoDownloader(url)
+	if err == nil {
+		t.Fatalf("GetVideoDownloader() expected error for unsupported url, got nil")
+	}
+	if downloader != nil {
+		t.Fatalf("GetVideoDownloader() expected nil downloader for unsupported url, got %T", downloader)
+	}
+}
+
+func TestGetVideoDownloader_ReturnsGenericDownloaderForConfiguredSite(t *testing.T) {
+	ytDlpConfig := &config.YtDlp{Sites: []config.Site{
+		{Name: "vimeo", Hosts: []string{"vimeo.com"}},
+		// configured sites must not take over the dedicated downloaders
+		{Name: "youtube-mirror", Hosts: []string{"youtube.com"}},
+	}}
+	registry := NewRegistry(nil, nil, nil, ytDlpConfig, nil)
+
+	downloader, err := registry.GetVideoDownloader("https://player.vimeo.com/video/76979871")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*generic.GenericAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *generic.GenericAudioDownloader, got %T", downloader)
+	}
+
+	downloader, err = registry.GetVideoDownloader("https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*youtube.YoutubeAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *youtube.YoutubeAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestGetVideoDownloader_ReturnsDirectDownloaderForMediaFiles(t *testing.T) {
+	downloader, err := NewRegistry(nil, nil, nil, nil, nil).GetVideoDownloader("https://media.example.org/talks/keynote.mp4")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*direct.DirectAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *direct.DirectAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestGetVideoDownloader_ReturnsDirectDownloaderForMediaFilesOnFeedHosts(t *testing.T) {
+	downloader, err := NewRegistry(nil, nil, nil, nil, nil).GetVideoDownloader("https://feeds.soundcloud.com/stream/123.mp3")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*direct.DirectAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *direct.DirectAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestGetVideoDownloader_ReturnsPodcastFeedDownloaderForFeeds(t *testing.T) {
+	downloader, err := NewRegistry(nil, nil, nil, nil, nil).GetVideoDownloader("https://feeds.example.org/engineering-talk.xml")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*podcastfeed.PodcastFeedAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *podcastfeed.PodcastFeedAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestNewRegistry_DisabledDownloader(t *testing.T) {
+	registry := NewRegistry(nil, nil, nil, nil, &config.Downloaders{Disabled: []string{TwitchDownloader}})
+
+	if _, err := registry.GetVideoDownloader("https://www.twitch.tv/videos/2345678901"); err == nil {
+		t.Errorf("GetVideoDownloader() expected error for disabled downloader, got nil")
+	}
+	want := []string{YoutubeDownloader, PodcastFeedDownloader, DirectDownloader}
+	if got := registry.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestNewRegistry_BuiltInDownloadersAreReservedByConfig(t *testing.T) {
+	registry := NewRegistry(nil, nil, nil, nil, nil)
+
+	if got := registry.Names(); !reflect.DeepEqual(got, config.BuiltInDownloaders) {
+		t.Errorf("Names() = %v, want config.BuiltInDownloaders %v", got, config.BuiltInDownloaders)
+	}
+}
+
+func TestNewRegistry_Order(t *testing.T) {
+	ytDlpConfig := &config.YtDlp{Sites: []config.Site{{Name: "youtube-mirror", Hosts: []string{"youtube.com"}}}}
+	registry := NewRegistry(nil, nil, nil, ytDlpConfig, &config.Downloaders{Order: []string{"youtube-mirror", DirectDownloader}})
+
+	want := []string{"youtube-mirror", DirectDownloader, TwitchDownloader, YoutubeDownloader, PodcastFeedDownloader}
+	if got := registry.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	downloader, err := registry.GetVideoDownloader("https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*generic.GenericAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *generic.GenericAudioDownloader, got %T", downloader)
+	}
+}
+
+func TestNewRegistry_SiteWithURLPattern(t *testing.T) {
+	ytDlpConfig := &config.YtDlp{Sites: []config.Site{{Name: "talks", URLPatterns: []string{`^https://example\.com/talks/`}}}}
+	registry := NewRegistry(nil, nil, nil, ytDlpConfig, nil)
+
+	downloader, err := registry.GetVideoDownloader("https://example.com/talks/keynote")
+	if err != nil {
+		t.Fatalf("GetVideoDownloader() unexpected error: %v", err)
+	}
+	if _, ok := downloader.(*generic.GenericAudioDownloader); !ok {
+		t.Fatalf("GetVideoDownloader() expected *generic.GenericAudioDownloader, got %T", downloader)
+	}
+	if _, err := registry.GetVideoDownloader("https://example.com/blog/keynote"); err == nil {
+		t.Errorf("GetVideoDownloader() expected error for unmatched url, got nil")
+	}
+}
@@ -1,16 +1,14 @@
 package twitch
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
-	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
-	"time"
 
-	mp3joiner "github.com/jo-hoe/mp3-joiner"
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
@@ -20,57 +18,95 @@ const (
 	twitchVodRegex   = `https://(?:www\.)?twitch\.tv/videos/(\d+)`
 	twitchClipRegex  = `https://(?:www\.)?twitch\.tv/[A-Za-z0-9_]+/clip/([A-Za-z0-9_-]+)`
 	twitchClipsRegex = `https://clips\.twitch\.tv/([A-Za-z0-9_-]+)`
+	// video list of a channel, optionally filtered, e.g. https://www.twitch.tv/<channel>/videos?filter=archives
+	twitchChannelVideosRegex = `^https://(?:www\.|m\.)?twitch\.tv/([A-Za-z0-9_]+)/videos/?(?:\?.*)?$`
+	twitchCollectionRegex    = `^https://(?:www\.|m\.)?twitch\.tv/collections/([A-Za-z0-9_-]+)`
 )
 
 var (
-	twitchVodPattern   = regexp.MustCompile(twitchVodRegex)
-	twitchClipPattern  = regexp.MustCompile(twitchClipRegex)
-	twitchClipsPattern = regexp.MustCompile(twitchClipsRegex)
+	twitchVodPattern           = regexp.MustCompile(twitchVodRegex)
+	twitchClipPattern          = regexp.MustCompile(twitchClipRegex)
+	twitchClipsPattern         = regexp.MustCompile(twitchClipsRegex)
+	twitchChannelVideosPattern = regexp.MustCompile(twitchChannelVideosRegex)
+	twitchCollectionPattern    = regexp.MustCompile(twitchCollectionRegex)
 )
 
+// supportedVideoFilters are the values of the filter parameter of a channel's video list that list VODs.
+// Without a filter all videos of the channel are listed.
+var supportedVideoFilters = map[string]bool{"": true, "all": true, "archives": true, "highlights": true, "uploads": true}
+
 type TwitchAudioDownloader struct {
-	cookiesConfig *config.Cookies
-	mediaConfig   *config.Media
+	runner      *downloader.YtDlpRunner
+	mediaConfig *config.Media
 }
 
-func NewTwitchAudioDownloader(cookiesConfig *config.Cookies, mediaConfig *config.Media) *TwitchAudioDownloader {
+func NewTwitchAudioDownloader(runner *downloader.YtDlpRunner, mediaConfig *config.Media) *TwitchAudioDownloader {
 	return &TwitchAudioDownloader{
-		cookiesConfig: cookiesConfig,
-		mediaConfig:   mediaConfig,
+		runner:      runner,
+		mediaConfig: mediaConfig,
 	}
 }
 
 func (t *TwitchAudioDownloader) IsVideoSupported(url string) bool {
 	return twitchVodPattern.MatchString(url) ||
 		twitchClipPattern.MatchString(url) ||
-		twitchClipsPattern.MatchString(url)
+		twitchClipsPattern.MatchString(url) ||
+		isVideoList(url)
 }
 
-func (t *TwitchAudioDownloader) CheckVideoAvailability(url string) error {
-	slog.Info("checking video availability", "url", url)
-
-	args := t.buildBaseArgs(true)
-	args = append(args, "--print", downloader.LiveStatusKey, url)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
+// isVideoList reports whether the URL is the video list of a channel with a supported filter or a collection.
+func isVideoList(rawURL string) bool {
+	if twitchCollectionPattern.MatchString(rawURL) {
+		return true
+	}
+	if !twitchChannelVideosPattern.MatchString(rawURL) {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("yt-dlp availability check failed: %w", err)
+		return false
 	}
+	return supportedVideoFilters[parsed.Query().Get("filter")]
+}
 
-	if downloader.IsLiveFromOutput(output) {
-		slog.Warn("stream is currently live; treating as unavailable", "url", url)
-		return downloader.ErrVideoLive
+// NormalizeVideoURL maps VOD links to https://www.twitch.tv/videos/<id>, the URL yt-dlp stores in the audio metadata.
+// Clip links are returned without query parameters.
+func (t *TwitchAudioDownloader) NormalizeVideoURL(url string) string {
+	if match := twitchVodPattern.FindStringSubmatch(url); len(match) > 1 {
+		return "https://www.twitch.tv/videos/" + match[1]
+	}
+	if twitchClipPattern.MatchString(url) || twitchClipsPattern.MatchString(url) {
+		url, _, _ = strings.Cut(url, "?")
 	}
+	return url
+}
+
+func (t *TwitchAudioDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	return t.runner.CheckAvailability(ctx, url)
+}
 
-	return nil
+// IsVideoList reports whether the URL is the video list of a channel with a supported filter or a collection.
+func (t *TwitchAudioDownloader) IsVideoList(url string) bool {
+	return isVideoList(url)
 }
 
-func (t *TwitchAudioDownloader) ListIndividualVideoURLs(url string) ([]string, error) {
-	return []string{url}, nil
+// ListIndividualVideoURLs returns individual video URLs for a given input URL.
+// For channel video lists and collections, it returns the VOD URLs restricted by the selection.
+// For single VODs and clips, it returns a slice containing the original URL.
+func (t *TwitchAudioDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	if !isVideoList(url) {
+		return []string{url}, nil
+	}
+
+	entries, err := t.runner.ListEntries(ctx, url, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	return downloader.SelectEntries(entries, selection), nil
 }
 
-func (t *TwitchAudioDownloader) Download(url string, targetPath string) (string, error) {
+func (t *TwitchAudioDownloader) Download(ctx context.Context, url string, targetPath string, progress downloader.ProgressFunc) (string, error) {
 	tempPath, err := os.MkdirTemp(t.mediaConfig.TempPath, "twitch-download-")
 	if err != nil {
 		return "", err
@@ -82,7 +118,7 @@ func (t *TwitchAudioDownloader) Download(url string, targetPath string) (string,
 	}()
 
 	slog.Info("downloading", "url", url, "tempPath", tempPath)
-	filePaths, err := t.download(tempPath, url)
+	filePaths, err := t.download(ctx, tempPath, url, progress)
 	if err != nil {
 		return "", err
 	}
@@ -92,115 +128,20 @@ func (t *TwitchAudioDownloader) Download(url string, targetPath string) (string,
 	filePath := filePaths[0]
 	slog.Info("done downloading file", "filePath", filePath)
 
-	slog.Info("setting metadata", "filePath", filePath)
-	if err = t.setMetadata(filePath, url); err != nil {
-		return "", err
-	}
-	slog.Info("set metadata", "filePath", filePath)
-
-	slog.Info("moving file to target folder")
-	result, err := filemanagement.MoveToTarget(filePath, targetPath)
-	if err != nil {
-		return "", err
-	}
-	slog.Info("completed moving file", "targetPath", result)
-
-	return result, nil
+	return t.runner.TagAndMove(ctx, url, filePath, targetPath, progress)
 }
 
-func (t *TwitchAudioDownloader) download(targetDirectory string, url string) ([]string, error) {
+func (t *TwitchAudioDownloader) download(ctx context.Context, targetDirectory string, url string, progress downloader.ProgressFunc) ([]string, error) {
 	tempFilenameTemplate := fmt.Sprintf("%s%c%s", targetDirectory, os.PathSeparator, "%(uploader)s/%(title)s_%(id)s.%(ext)s")
 
-	args := t.buildBaseArgs(false)
-	args = append(args,
-		"--extract-audio",
-		"--audio-format", "mp3",
-		"--embed-metadata",
-		"--no-progress",
-		"--output", tempFilenameTemplate,
-		url,
-	)
-
-	cmd := exec.Command("yt-dlp", args...)
-	slog.Info("constructed yt-dlp command", "args", args)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("yt-dlp command failed: %w", err)
+	if err := t.runner.DownloadAudio(ctx, url, tempFilenameTemplate, progress); err != nil {
+		return nil, err
 	}
 
 	return filemanagement.GetAudioFiles(targetDirectory)
 }
 
-func (t *TwitchAudioDownloader) setMetadata(fullFilePath string, sourceURL string) error {
-	metadata, err := mp3joiner.GetFFmpegMetadataTag(fullFilePath)
-	if err != nil {
-		return err
-	}
-	chapters, err := mp3joiner.GetChapterMetadata(fullFilePath)
-	if err != nil {
-		return err
-	}
-
-	metadata[downloader.PodcastDescriptionTag] = strings.ReplaceAll(metadata["synopsis"], "\n", "<br>")
-	metadata[downloader.DateTag] = metadata["date"]
-	metadata[downloader.VideoDownloadLink] = metadata[downloader.VideoURLID3Key]
-
-	thumbnailURL, err := t.getThumbnailURL(sourceURL)
-	if err != nil {
-		return err
-	}
-	metadata[downloader.ThumbnailUrlTag] = thumbnailURL
-
-	if ts, tsErr := t.getTimestamp(sourceURL); tsErr == nil {
-		metadata["date"] = time.Unix(ts, 0).UTC().Format("2006-01-02T15:04:05")
-	} else {
-		slog.Warn("could not get timestamp, will fall back to date tag", "err", tsErr)
-	}
-
-	return mp3joiner.SetFFmpegMetadataTag(fullFilePath, metadata, chapters)
-}
-
-func (t *TwitchAudioDownloader) getThumbnailURL(url string) (string, error) {
-	args := t.buildBaseArgs(true)
-	args = append(args, "--print", "thumbnail", url)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		slog.Error("error getting thumbnail url", "url", url, "err", err)
-		return "", err
-	}
-
-	return downloader.FirstHTTPSLineFromOutput(output), nil
-}
-
-func (t *TwitchAudioDownloader) getTimestamp(url string) (int64, error) {
-	args := t.buildBaseArgs(true)
-	args = append(args, "--print", "timestamp", url)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, fmt.Errorf("yt-dlp timestamp fetch failed: %w", err)
-	}
-
-	ts := strings.TrimSpace(string(output))
-	if ts == "" || ts == "NA" {
-		return 0, fmt.Errorf("timestamp not available for %s", url)
-	}
-	return strconv.ParseInt(ts, 10, 64)
-}
-
-// buildBaseArgs creates base arguments for yt-dlp command.
-// When simulate is true, adds --simulate and --quiet flags for dry-run operations.
-func (t *TwitchAudioDownloader) buildBaseArgs(simulate bool) []string {
-	args := downloader.AppendCookieArgs(make([]string, 0), t.cookiesConfig)
-
-	if simulate {
-		args = append(args, "--simulate", "--quiet")
-	}
-
-	return args
+// GetVideoInfo returns the metadata of a single video reported by yt-dlp --dump-json.
+func (t *TwitchAudioDownloader) GetVideoInfo(ctx context.Context, url string) (*downloader.VideoInfo, error) {
+	return t.runner.VideoInfo(ctx, url)
 }
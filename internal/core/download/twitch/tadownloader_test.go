@@ -1,7 +1,11 @@
 package twitch
 
 import (
+	"context"
+	"io"
 	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 )
 
 func TestTwitchAudioDownloader_IsVideoSupported(t *testing.T) {
@@ -44,6 +48,36 @@ func TestTwitchAudioDownloader_IsVideoSupported(t *testing.T) {
 			args: args{url: "https://www.twitch.tv/somechannel"},
 			want: false,
 		},
+		{
+			name: "channel videos",
+			d:    &TwitchAudioDownloader{},
+			args: args{url: "https://www.twitch.tv/somechannel/videos"},
+			want: true,
+		},
+		{
+			name: "channel past broadcasts",
+			d:    &TwitchAudioDownloader{},
+			args: args{url: "https://www.twitch.tv/somechannel/videos?filter=archives&sort=time"},
+			want: true,
+		},
+		{
+			name: "channel highlights on mobile",
+			d:    &TwitchAudioDownloader{},
+			args: args{url: "https://m.twitch.tv/somechannel/videos?filter=highlights"},
+			want: true,
+		},
+		{
+			name: "channel collections list is not supported",
+			d:    &TwitchAudioDownloader{},
+			args: args{url: "https://www.twitch.tv/somechannel/videos?filter=collections"},
+			want: false,
+		},
+		{
+			name: "collection",
+			d:    &TwitchAudioDownloader{},
+			args: args{url: "https://www.twitch.tv/collections/wlDCoH0zEBZZbQ"},
+			want: true,
+		},
 		{
 			name: "youtube link is not supported",
 			d:    &TwitchAudioDownloader{},
@@ -65,3 +99,48 @@ func TestTwitchAudioDownloader_IsVideoSupported(t *testing.T) {
 		})
 	}
 }
+
+func TestTwitchAudioDownloader_NormalizeVideoURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "vod link without www", url: "https://twitch.tv/videos/2345678901?t=1h2m", want: "https://www.twitch.tv/videos/2345678901"},
+		{name: "clip link with query", url: "https://clips.twitch.tv/FunnyClip-abc?tt_medium=share", want: "https://clips.twitch.tv/FunnyClip-abc"},
+		{name: "unknown link is unchanged", url: "https://www.twitch.tv/somechannel", want: "https://www.twitch.tv/somechannel"},
+	}
+
+	d := &TwitchAudioDownloader{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.NormalizeVideoURL(tt.url); got != tt.want {
+				t.Errorf("TwitchAudioDownloader.NormalizeVideoURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTwitchAudioDownloader_ListIndividualVideoURLs(t *testing.T) {
+	executor := &downloader.MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		_, _ = io.WriteString(stdout, "1700000200 https://www.twitch.tv/videos/2\n1700000100 https://www.twitch.tv/videos/1\n")
+		return nil
+	}}
+	d := NewTwitchAudioDownloader(downloader.NewYtDlpRunner(executor, nil, nil, nil), nil)
+
+	single, err := d.ListIndividualVideoURLs(context.Background(), "https://www.twitch.tv/videos/3", downloader.Selection{})
+	if err != nil || len(single) != 1 || single[0] != "https://www.twitch.tv/videos/3" {
+		t.Errorf("ListIndividualVideoURLs() = %v, %v, want the VOD itself", single, err)
+	}
+	if len(executor.Calls()) != 0 {
+		t.Errorf("expected no yt-dlp call for a single VOD, got %v", executor.Calls())
+	}
+
+	got, err := d.ListIndividualVideoURLs(context.Background(), "https://www.twitch.tv/streamer/videos?filter=archives", downloader.Selection{Latest: 1})
+	if err != nil {
+		t.Fatalf("ListIndividualVideoURLs() unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "https://www.twitch.tv/videos/2" {
+		t.Errorf("ListIndividualVideoURLs() = %v, want the newest VOD", got)
+	}
+}
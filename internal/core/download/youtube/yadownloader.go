@@ -1,16 +1,13 @@
 package youtube
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
-	"time"
 
-	mp3joiner "github.com/jo-hoe/mp3-joiner"
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
@@ -20,32 +17,37 @@ const (
 	playlistRegex        = `https://(?:.+)?youtube.com/(?:.+)?list=([A-Za-z0-9_-]*)`
 	youtubeVideoRegex    = `https://(?:.+)?youtube.com/(?:.+)?watch\?v=([A-Za-z0-9_-]*)`
 	youtubeTinyLinkRegex = `https://youtu\.be/([A-Za-z0-9_-]*)`
-	// types taken from API description
-	// https://wiki.sponsor.ajay.app/w/Types
-	sponsorBlockCategories = "sponsor,selfpromo,interaction,intro,outro,preview,music_offtopic,filler,hook"
+	// shorts and live links of a single video, e.g. https://www.youtube.com/shorts/<id> or https://m.youtube.com/live/<id>
+	youtubeShortsOrLiveRegex = `^https://(?:www\.|m\.)?youtube\.com/(?:shorts|live)/([A-Za-z0-9_-]+)`
+	// channel pages with an optional tab, e.g. https://www.youtube.com/@handle/videos, /channel/UC..., /c/name or /user/name
+	youtubeChannelRegex = `^(https://(?:www\.|m\.)?youtube\.com/(?:@[^/?#]+|channel/UC[A-Za-z0-9_-]+|c/[^/?#]+|user/[^/?#]+))(?:/(featured|videos|shorts|streams|live))?/?(?:[?#].*)?$`
 )
 
 var (
-	playlistPattern     = regexp.MustCompile(playlistRegex)
-	youtubeVideoPattern = regexp.MustCompile(youtubeVideoRegex)
-	youtubeTinyPattern  = regexp.MustCompile(youtubeTinyLinkRegex)
+	playlistPattern            = regexp.MustCompile(playlistRegex)
+	youtubeVideoPattern        = regexp.MustCompile(youtubeVideoRegex)
+	youtubeTinyPattern         = regexp.MustCompile(youtubeTinyLinkRegex)
+	youtubeShortsOrLivePattern = regexp.MustCompile(youtubeShortsOrLiveRegex)
+	youtubeChannelPattern      = regexp.MustCompile(youtubeChannelRegex)
 )
 
 type YoutubeAudioDownloader struct {
-	cookiesConfig *config.Cookies
-	mediaConfig   *config.Media
-	ytDlpConfig   *config.YtDlp
+	runner             *downloader.YtDlpRunner
+	mediaConfig        *config.Media
+	sponsorBlockConfig *config.SponsorBlock
 }
 
-func NewYoutubeAudioDownloader(cookiesConfig *config.Cookies, mediaConfig *config.Media, ytDlpConfig *config.YtDlp) *YoutubeAudioDownloader {
+// NewYoutubeAudioDownloader creates a downloader that handles SponsorBlock segments as configured in sponsorBlockConfig.
+// Without configuration the default categories are removed.
+func NewYoutubeAudioDownloader(runner *downloader.YtDlpRunner, mediaConfig *config.Media, sponsorBlockConfig *config.SponsorBlock) *YoutubeAudioDownloader {
 	return &YoutubeAudioDownloader{
-		cookiesConfig: cookiesConfig,
-		mediaConfig:   mediaConfig,
-		ytDlpConfig:   ytDlpConfig,
+		runner:             runner,
+		mediaConfig:        mediaConfig,
+		sponsorBlockConfig: sponsorBlockConfig,
 	}
 }
 
-func (y *YoutubeAudioDownloader) Download(url string, targetPath string) (string, error) {
+func (y *YoutubeAudioDownloader) Download(ctx context.Context, url string, targetPath string, progress downloader.ProgressFunc) (string, error) {
 	// Create a unique subdirectory within the configured temp path for download processing
 	tempPath, err := os.MkdirTemp(y.mediaConfig.TempPath, "youtube-download-")
 	if err != nil {
@@ -58,7 +60,7 @@ func (y *YoutubeAudioDownloader) Download(url string, targetPath string) (string
 	}()
 
 	slog.Info("downloading", "url", url, "tempPath", tempPath)
-	tempResults, err := y.download(tempPath, url)
+	tempResults, err := y.download(ctx, tempPath, url, progress)
 	if err != nil {
 		return "", err
 	}
@@ -69,186 +71,128 @@ func (y *YoutubeAudioDownloader) Download(url string, targetPath string) (string
 	filePath := tempResults[0]
 	slog.Info("done downloading file", "filePath", filePath)
 
-	slog.Info("setting metadata", "filePath", filePath)
-	if err = y.setMetadata(filePath); err != nil {
-		return "", err
-	}
-	slog.Info("set metadata", "filePath", filePath)
-
-	slog.Info("moving file to target folder")
-	result, err := filemanagement.MoveToTarget(filePath, targetPath)
-	if err != nil {
-		return "", err
-	}
-	slog.Info("completed moving file", "targetPath", result)
-
-	return result, nil
+	return y.runner.TagAndMove(ctx, url, filePath, targetPath, progress)
 }
 
-func (y *YoutubeAudioDownloader) setMetadata(fullFilePath string) error {
-	metadata, err := mp3joiner.GetFFmpegMetadataTag(fullFilePath)
-	if err != nil {
-		return err
-	}
-	chapters, err := mp3joiner.GetChapterMetadata(fullFilePath)
-	if err != nil {
-		return err
-	}
-
-	metadata[downloader.PodcastDescriptionTag] = strings.ReplaceAll(metadata["synopsis"], "\n", "<br>")
-	metadata[downloader.DateTag] = metadata["date"]
-	metadata[downloader.VideoDownloadLink] = metadata[downloader.VideoURLID3Key]
-
-	videoURL := metadata[downloader.VideoURLID3Key]
-
-	thumbnailURL, err := y.getThumbnailURL(videoURL)
-	if err != nil {
-		return err
-	}
-	metadata[downloader.ThumbnailUrlTag] = thumbnailURL
-
-	if ts, tsErr := y.getTimestamp(videoURL); tsErr == nil {
-		metadata["date"] = time.Unix(ts, 0).UTC().Format("2006-01-02T15:04:05")
-	} else {
-		slog.Warn("could not get timestamp, will fall back to date tag", "err", tsErr)
-	}
-
-	return mp3joiner.SetFFmpegMetadataTag(fullFilePath, metadata, chapters)
-}
-
-func (y *YoutubeAudioDownloader) getThumbnailURL(videoURL string) (string, error) {
-	args := y.buildBaseArgs(true)
-	args = append(args, "--print", "thumbnail", videoURL)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		slog.Error("error getting thumbnail url", "videoURL", videoURL, "err", err)
-		return "", err
-	}
-
-	return downloader.FirstHTTPSLineFromOutput(output), nil
-}
-
-func (y *YoutubeAudioDownloader) getTimestamp(videoURL string) (int64, error) {
-	args := y.buildBaseArgs(true)
-	args = append(args, "--print", "timestamp", videoURL)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, fmt.Errorf("yt-dlp timestamp fetch failed: %w", err)
-	}
-
-	ts := strings.TrimSpace(string(output))
-	if ts == "" || ts == "NA" {
-		return 0, fmt.Errorf("timestamp not available for %s", videoURL)
-	}
-	return strconv.ParseInt(ts, 10, 64)
-}
-
-func (y *YoutubeAudioDownloader) download(targetDirectory string, url string) ([]string, error) {
+func (y *YoutubeAudioDownloader) download(ctx context.Context, targetDirectory string, url string, progress downloader.ProgressFunc) ([]string, error) {
 	// set download behavior
 	tempFilenameTemplate := fmt.Sprintf("%s%c%s", targetDirectory, os.PathSeparator, "%(channel)s/%(title)s_%(id)s.%(ext)s")
 
-	args := y.buildBaseArgs(false)
+	args := y.sponsorBlockArgs(ctx, url)
 	args = append(args,
-		"--extract-audio",
-		"--audio-format", "mp3",
-		"--embed-metadata",
-		"--no-progress",
-		"--sponsorblock-remove", sponsorBlockCategories,
 		// Abort if any fragment is unavailable (e.g. 403) so the download
 		// fails cleanly and the retry logic can re-fetch fresh stream URLs.
 		// SponsorBlock API failures are PostProcessingErrors and are unaffected by this flag.
 		"--abort-on-unavailable-fragments",
-	)
-
-	args = append(args,
 		// Prefer m4a/HLS formats over WebM to avoid mweb GVS 403 errors.
 		// web_safari provides HLS (m3u8) formats that do not require a GVS PO token.
 		"--format", "bestaudio[ext=m4a]/bestaudio/best[height<=360]",
-		"--output", tempFilenameTemplate,
-		url,
 	)
+	err := y.runner.DownloadAudio(ctx, url, tempFilenameTemplate, progress, args...)
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := exec.Command("yt-dlp", args...)
-	slog.Info("constructed yt-dlp command", "args", args)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return filemanagement.GetAudioFiles(targetDirectory)
+}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("yt-dlp command failed: %w", err)
+// sponsorBlockArgs returns the yt-dlp arguments handling the SponsorBlock segments of url.
+// The feed of a video is its channel, which is only probed if settings for single feeds are configured.
+func (y *YoutubeAudioDownloader) sponsorBlockArgs(ctx context.Context, url string) []string {
+	var settings config.SponsorBlockFeed
+	if y.sponsorBlockConfig != nil {
+		settings = y.sponsorBlockConfig.ForFeed("")
+		if len(y.sponsorBlockConfig.Feeds) > 0 {
+			if info, err := y.runner.VideoInfo(ctx, url); err != nil {
+				slog.Warn("could not determine feed, using default SponsorBlock settings", "url", url, "err", err)
+			} else {
+				settings = y.sponsorBlockConfig.ForFeed(info.Channel)
+			}
+		}
 	}
 
-	return filemanagement.GetAudioFiles(targetDirectory)
+	categories := settings.Categories
+	if len(categories) == 0 {
+		categories = config.DefaultSponsorBlockCategories
+	}
+	switch settings.Mode {
+	case config.SponsorBlockDisabled:
+		return []string{"--no-sponsorblock"}
+	case config.SponsorBlockMark:
+		// chapters are embedded with the metadata
+		return []string{"--sponsorblock-mark", strings.Join(categories, ",")}
+	default:
+		return []string{"--sponsorblock-remove", strings.Join(categories, ",")}
+	}
 }
 
 func (y *YoutubeAudioDownloader) IsVideoSupported(url string) bool {
 	return playlistPattern.MatchString(url) ||
 		youtubeVideoPattern.MatchString(url) ||
-		youtubeTinyPattern.MatchString(url)
+		youtubeTinyPattern.MatchString(url) ||
+		youtubeShortsOrLivePattern.MatchString(url) ||
+		youtubeChannelPattern.MatchString(url)
 }
 
-func (y *YoutubeAudioDownloader) CheckVideoAvailability(url string) error {
-	slog.Info("checking video availability", "url", url)
-
-	args := y.buildBaseArgs(true)
-	args = append(args, "--print", downloader.LiveStatusKey, url)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("yt-dlp availability check failed: %w", err)
+// listURL returns the URL whose entries are listed for url, or an empty string if url is a single video.
+// Channel pages are listed through their videos tab unless a shorts or streams tab is given,
+// while the live tab of a channel is its current live stream and therefore a single video.
+func listURL(url string) string {
+	if match := youtubeChannelPattern.FindStringSubmatch(url); match != nil {
+		switch tab := match[2]; tab {
+		case "live":
+			return ""
+		case "shorts", "streams":
+			return match[1] + "/" + tab
+		default:
+			return match[1] + "/videos"
+		}
 	}
-
-	if downloader.IsLiveFromOutput(output) {
-		slog.Warn("video is currently live; treating as unavailable", "url", url)
-		return downloader.ErrVideoLive
+	if playlistPattern.MatchString(url) {
+		return url
 	}
-
-	return nil
+	return ""
 }
 
-// buildBaseArgs creates base arguments for yt-dlp command.
-// When simulate is true, adds --simulate and --quiet flags for dry-run operations.
-func (y *YoutubeAudioDownloader) buildBaseArgs(simulate bool) []string {
-	args := downloader.AppendCookieArgs(make([]string, 0), y.cookiesConfig)
+func (y *YoutubeAudioDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	return y.runner.CheckAvailability(ctx, url)
+}
 
-	if simulate {
-		args = append(args, "--simulate", "--quiet")
-	} else if y.ytDlpConfig != nil && y.ytDlpConfig.Verbose {
-		args = append(args, "--verbose")
+// NormalizeVideoURL maps watch, short, shorts and live links of a single video to https://www.youtube.com/watch?v=<id>,
+// the URL yt-dlp stores in the audio metadata.
+func (y *YoutubeAudioDownloader) NormalizeVideoURL(url string) string {
+	for _, pattern := range []*regexp.Regexp{youtubeVideoPattern, youtubeTinyPattern, youtubeShortsOrLivePattern} {
+		if match := pattern.FindStringSubmatch(url); len(match) > 1 && match[1] != "" {
+			return "https://www.youtube.com/watch?v=" + match[1]
+		}
 	}
+	return url
+}
 
-	return args
+// IsVideoList reports whether the URL is a playlist or the tab of a channel listing its uploads.
+func (y *YoutubeAudioDownloader) IsVideoList(url string) bool {
+	return listURL(url) != ""
 }
 
 // ListIndividualVideoURLs returns individual video URLs for a given input URL.
-// For playlist URLs, it returns all video URLs in the playlist.
-// For single video URLs, it returns a slice containing the original URL.
-func (y *YoutubeAudioDownloader) ListIndividualVideoURLs(url string) ([]string, error) {
-	if !playlistPattern.MatchString(url) {
+// For playlist and channel URLs, it returns the video URLs in the playlist or the uploads of the channel
+// restricted by the selection. For single video URLs, it returns a slice containing the original URL.
+func (y *YoutubeAudioDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	entriesURL := listURL(url)
+	if entriesURL == "" {
 		return []string{url}, nil
 	}
 
-	args := y.buildBaseArgs(true)
-	// Use flat playlist to avoid resolving each entry and just print the URL
-	args = append(args, "--flat-playlist", "--print", "url", url)
-
-	cmd := exec.Command("yt-dlp", args...)
-	output, err := cmd.Output()
+	// Flat entries carry no exact upload time, the approximate date is enough to select recent entries.
+	entries, err := y.runner.ListEntries(ctx, entriesURL, selection, "--extractor-args", "youtubetab:approximate_date")
 	if err != nil {
-		slog.Error("error listing playlist entries", "url", url, "err", err)
 		return nil, err
 	}
 
-	entries := make([]string, 0)
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
-			entries = append(entries, line)
-		}
-	}
-	return entries, nil
+	return downloader.SelectEntries(entries, selection), nil
+}
+
+// GetVideoInfo returns the metadata of a single video reported by yt-dlp --dump-json.
+func (y *YoutubeAudioDownloader) GetVideoInfo(ctx context.Context, url string) (*downloader.VideoInfo, error) {
+	return y.runner.VideoInfo(ctx, url)
 }
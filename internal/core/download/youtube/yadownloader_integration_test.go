@@ -1,6 +1,7 @@
 package youtube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -53,8 +54,8 @@ func Test_YoutubeAudioDownloader_Download_File_Properties(t *testing.T) {
 		}
 	}()
 
-	y := NewYoutubeAudioDownloader(nil, &config.Media{TempPath: tempDir}, nil)
-	result, err := y.Download(validYoutubeVideoUrl, rootDirectory)
+	y := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(nil, nil, nil, nil), &config.Media{TempPath: tempDir}, nil)
+	result, err := y.Download(context.Background(), validYoutubeVideoUrl, rootDirectory, nil)
 	if err != nil {
 		t.Fatalf("YoutubeAudioDownloader.Download() error = %v", err)
 	}
@@ -135,10 +136,10 @@ func Test_YoutubeAudioDownloader_Download(t *testing.T) {
 		}
 	}()
 
-	y := NewYoutubeAudioDownloader(nil, &config.Media{TempPath: tempDir}, nil)
+	y := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(nil, nil, nil, nil), &config.Media{TempPath: tempDir}, nil)
 
 	// Single video download should return a single file path and file should exist
-	singleResult, err := y.Download(validYoutubeVideoUrl, rootDirectory)
+	singleResult, err := y.Download(context.Background(), validYoutubeVideoUrl, rootDirectory, nil)
 	if err != nil {
 		t.Fatalf("YoutubeAudioDownloader.Download(single) error = %v", err)
 	}
@@ -150,7 +151,7 @@ func Test_YoutubeAudioDownloader_Download(t *testing.T) {
 	}
 
 	// Playlist: list entries, download each entry individually, verify count and existence
-	entries, err := y.ListIndividualVideoURLs(validYoutubePlaylistUrl)
+	entries, err := y.ListIndividualVideoURLs(context.Background(), validYoutubePlaylistUrl, downloader.Selection{})
 	if err != nil {
 		t.Fatalf("ListIndividualVideoURLs() error = %v", err)
 	}
@@ -160,7 +161,7 @@ func Test_YoutubeAudioDownloader_Download(t *testing.T) {
 
 	results := make([]string, 0, len(entries))
 	for _, entry := range entries {
-		p, err := y.Download(entry, filepath.Join(rootDirectory, "Cat"))
+		p, err := y.Download(context.Background(), entry, filepath.Join(rootDirectory, "Cat"), nil)
 		if err != nil {
 			t.Fatalf("Download(entry) error = %v", err)
 		}
@@ -181,18 +182,18 @@ func Test_YoutubeAudioDownloader_Download(t *testing.T) {
 
 func TestYoutubeAudioDownloader_CheckVideoAvailability_UnavailableURL_ReturnsError(t *testing.T) {
 	checkPrerequisites(t)
-	d := NewYoutubeAudioDownloader(nil, nil, nil)
+	d := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(nil, nil, nil, nil), nil, nil)
 
-	if err := d.CheckVideoAvailability("https://www.youtube.com/watch?v=invalid_url"); err == nil {
+	if err := d.CheckVideoAvailability(context.Background(), "https://www.youtube.com/watch?v=invalid_url"); err == nil {
 		t.Error("expected error for unavailable video, got nil")
 	}
 }
 
 func TestYoutubeAudioDownloader_CheckVideoAvailability_ValidURL_ReturnsNil(t *testing.T) {
 	checkPrerequisites(t)
-	d := NewYoutubeAudioDownloader(nil, nil, nil)
+	d := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(nil, nil, nil, nil), nil, nil)
 
-	if err := d.CheckVideoAvailability(validYoutubeVideoUrl); err != nil {
+	if err := d.CheckVideoAvailability(context.Background(), validYoutubeVideoUrl); err != nil {
 		t.Errorf("expected nil for available video, got: %v", err)
 	}
 }
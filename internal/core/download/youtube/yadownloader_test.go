@@ -1,7 +1,18 @@
 package youtube
 
 import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 )
 
 func TestYoutubeAudioDownloader_IsVideoSupported(t *testing.T) {
@@ -44,6 +55,62 @@ func TestYoutubeAudioDownloader_IsVideoSupported(t *testing.T) {
 			args: args{
 				url: "https://www.youtube.com/shorts/Hb3rmh-_FMw",
 			},
+			want: true,
+		},
+		{
+			name: "test youtube live link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://www.youtube.com/live/jNQXAC9IVRw?si=abc",
+			},
+			want: true,
+		},
+		{
+			name: "test mobile video link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://m.youtube.com/watch?v=jNQXAC9IVRw",
+			},
+			want: true,
+		},
+		{
+			name: "test channel handle link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://www.youtube.com/@jawed",
+			},
+			want: true,
+		},
+		{
+			name: "test channel videos tab link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://m.youtube.com/@jawed/videos",
+			},
+			want: true,
+		},
+		{
+			name: "test channel id link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://www.youtube.com/channel/UC4QobU6STFB0P71PMvOGN5A",
+			},
+			want: true,
+		},
+		{
+			name: "test custom channel link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://www.youtube.com/c/jawed/",
+			},
+			want: true,
+		},
+		{
+			name: "test channel community tab link",
+			y:    &YoutubeAudioDownloader{},
+			args: args{
+				url: "https://www.youtube.com/@jawed/community",
+			},
 			want: false,
 		},
 		{
@@ -63,3 +130,217 @@ func TestYoutubeAudioDownloader_IsVideoSupported(t *testing.T) {
 		})
 	}
 }
+
+func TestYoutubeAudioDownloader_NormalizeVideoURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "video link with tracking parameters",
+			url:  "https://www.youtube.com/watch?v=jNQXAC9IVRw&pp=ygUQb25lIHNlY29uZCB2aWRlbw%3D%3D",
+			want: "https://www.youtube.com/watch?v=jNQXAC9IVRw",
+		},
+		{
+			name: "mobile video link",
+			url:  "https://m.youtube.com/watch?v=jNQXAC9IVRw&t=10s",
+			want: "https://www.youtube.com/watch?v=jNQXAC9IVRw",
+		},
+		{
+			name: "short link",
+			url:  "https://youtu.be/DucriSA8ukw?feature=shared",
+			want: "https://www.youtube.com/watch?v=DucriSA8ukw",
+		},
+		{
+			name: "shorts link",
+			url:  "https://www.youtube.com/shorts/Hb3rmh-_FMw",
+			want: "https://www.youtube.com/watch?v=Hb3rmh-_FMw",
+		},
+		{
+			name: "mobile live link",
+			url:  "https://m.youtube.com/live/jNQXAC9IVRw?si=abc",
+			want: "https://www.youtube.com/watch?v=jNQXAC9IVRw",
+		},
+		{
+			name: "unknown link is unchanged",
+			url:  "https://www.youtube.com/playlist?list=PLXqZLJI1Rpy_x_piwxi9T-UlToz3UGdM-",
+			want: "https://www.youtube.com/playlist?list=PLXqZLJI1Rpy_x_piwxi9T-UlToz3UGdM-",
+		},
+	}
+
+	y := &YoutubeAudioDownloader{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := y.NormalizeVideoURL(tt.url); got != tt.want {
+				t.Errorf("YoutubeAudioDownloader.NormalizeVideoURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "channel handle is listed through its videos tab",
+			url:  "https://www.youtube.com/@jawed",
+			want: "https://www.youtube.com/@jawed/videos",
+		},
+		{
+			name: "featured tab is listed through the videos tab",
+			url:  "https://www.youtube.com/channel/UC4QobU6STFB0P71PMvOGN5A/featured?si=abc",
+			want: "https://www.youtube.com/channel/UC4QobU6STFB0P71PMvOGN5A/videos",
+		},
+		{
+			name: "shorts tab",
+			url:  "https://m.youtube.com/c/jawed/shorts",
+			want: "https://m.youtube.com/c/jawed/shorts",
+		},
+		{
+			name: "streams tab",
+			url:  "https://www.youtube.com/user/jawed/streams/",
+			want: "https://www.youtube.com/user/jawed/streams",
+		},
+		{
+			name: "live tab is a single video",
+			url:  "https://www.youtube.com/@jawed/live",
+			want: "",
+		},
+		{
+			name: "playlist",
+			url:  "https://www.youtube.com/playlist?list=PLXqZLJI1Rpy_x_piwxi9T-UlToz3UGdM-",
+			want: "https://www.youtube.com/playlist?list=PLXqZLJI1Rpy_x_piwxi9T-UlToz3UGdM-",
+		},
+		{
+			name: "shorts video",
+			url:  "https://www.youtube.com/shorts/Hb3rmh-_FMw",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listURL(tt.url); got != tt.want {
+				t.Errorf("listURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeYtDlp plays yt-dlp for a single video: downloads copy audioFile to the output template,
+// probes report fixed metadata and playlists list two entries.
+func fakeYtDlp(t *testing.T, audioFile string) *downloader.MockExecutor {
+	t.Helper()
+	return &downloader.MockExecutor{ExecuteFunc: func(args []string, stdout io.Writer, stderr io.Writer) error {
+		switch {
+		case slices.Contains(args, "--flat-playlist"):
+			_, _ = io.WriteString(stdout, "1700000200 https://www.youtube.com/watch?v=new\n1700000100 https://www.youtube.com/watch?v=old\n")
+		case slices.Contains(args, "--dump-json"):
+			_, _ = io.WriteString(stdout, `{"id":"abc","title":"title","channel":"channel","thumbnail":"https://i.ytimg.com/vi/abc/maxresdefault.jpg","live_status":"not_live","timestamp":1700000000,"webpage_url":"https://www.youtube.com/watch?v=abc"}`)
+		case slices.Contains(args, "--output"):
+			output := args[slices.Index(args, "--output")+1]
+			output = strings.NewReplacer("%(channel)s", "channel", "%(title)s", "title", "%(id)s", "abc", "%(ext)s", "mp3").Replace(output)
+			content, err := os.ReadFile(audioFile)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(output), os.ModePerm); err != nil {
+				return err
+			}
+			_, _ = io.WriteString(stdout, "[download] 100.0% of 1MiB\n")
+			return os.WriteFile(output, content, 0644)
+		}
+		return nil
+	}}
+}
+
+func TestYoutubeAudioDownloader_ListIndividualVideoURLs_Channel(t *testing.T) {
+	executor := fakeYtDlp(t, "")
+	y := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(executor, nil, nil, nil), nil, nil)
+
+	got, err := y.ListIndividualVideoURLs(context.Background(), "https://www.youtube.com/@jawed", downloader.Selection{Latest: 1})
+	if err != nil {
+		t.Fatalf("ListIndividualVideoURLs() unexpected error: %v", err)
+	}
+
+	if len(got) != 1 || got[0] != "https://www.youtube.com/watch?v=new" {
+		t.Errorf("ListIndividualVideoURLs() = %v, want the newest video", got)
+	}
+	args := executor.Calls()[0]
+	if args[len(args)-1] != "https://www.youtube.com/@jawed/videos" {
+		t.Errorf("listed %s, want the videos tab of the channel", args[len(args)-1])
+	}
+}
+
+func TestYoutubeAudioDownloader_Download_WithFakeYtDlp(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("skipping test, ffmpeg is required to tag the audio file")
+	}
+	targetDirectory := t.TempDir()
+	executor := fakeYtDlp(t, filepath.Join("..", "..", "..", "..", "test_assets", "audio11.mp3"))
+	y := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(executor, nil, nil, nil), &config.Media{TempPath: t.TempDir()}, nil)
+	var reported []downloader.Stage
+
+	result, err := y.Download(context.Background(), "https://www.youtube.com/watch?v=abc", targetDirectory, func(stage downloader.Stage, percent float64) {
+		reported = append(reported, stage)
+	})
+	if err != nil {
+		t.Fatalf("Download() unexpected error: %v", err)
+	}
+
+	if want := filepath.Join(targetDirectory, "channel", "title_abc.mp3"); result != want {
+		t.Errorf("Download() = %s, want %s", result, want)
+	}
+	if !slices.Contains(reported, downloader.StageDownloading) || !slices.Contains(reported, downloader.StageMoving) {
+		t.Errorf("reported stages %v, want downloading and moving", reported)
+	}
+	calls := executor.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected a download and a single probe, got yt-dlp calls %v", calls)
+	}
+	if !slices.Contains(calls[0], "--sponsorblock-remove") {
+		t.Errorf("download arguments %v do not remove sponsor segments", calls[0])
+	}
+}
+
+func TestYoutubeAudioDownloader_sponsorBlockArgs(t *testing.T) {
+	sponsorBlock := &config.SponsorBlock{
+		Mode:       config.SponsorBlockRemove,
+		Categories: []string{"sponsor", "music_offtopic"},
+	}
+	perFeed := &config.SponsorBlock{
+		Mode:       config.SponsorBlockRemove,
+		Categories: []string{"sponsor", "music_offtopic"},
+		Feeds:      map[string]config.SponsorBlockFeed{"channel": {Mode: config.SponsorBlockMark, Categories: []string{"sponsor"}}},
+	}
+
+	tests := []struct {
+		name       string
+		config     *config.SponsorBlock
+		want       []string
+		wantProbes int
+	}{
+		{name: "not configured", config: nil, want: []string{"--sponsorblock-remove", strings.Join(config.DefaultSponsorBlockCategories, ",")}},
+		{name: "remove", config: sponsorBlock, want: []string{"--sponsorblock-remove", "sponsor,music_offtopic"}},
+		{name: "disabled", config: &config.SponsorBlock{Mode: config.SponsorBlockDisabled}, want: []string{"--no-sponsorblock"}},
+		{name: "feed of the channel", config: perFeed, want: []string{"--sponsorblock-mark", "sponsor"}, wantProbes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := fakeYtDlp(t, "")
+			y := NewYoutubeAudioDownloader(downloader.NewYtDlpRunner(executor, nil, nil, nil), nil, tt.config)
+
+			if got := y.sponsorBlockArgs(context.Background(), "https://www.youtube.com/watch?v=abc"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sponsorBlockArgs() = %v, want %v", got, tt.want)
+			}
+			if probes := len(executor.Calls()); probes != tt.wantProbes {
+				t.Errorf("expected %d probes, got %d", tt.wantProbes, probes)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/webhook"
+)
+
+// downloadQueuePollInterval is the fallback interval at which the queue looks for
+// queued jobs in case a wake-up signal was missed. Waiting jobs are checked at the same interval.
+const downloadQueuePollInterval = 30 * time.Second
+
+// progressPersistStep is the minimum progress increase in percent before a running job is written to the database again.
+const progressPersistStep = 1.0
+
+// ErrDownloadCancelled is the cause of a job's context when the download was cancelled by a user.
+var ErrDownloadCancelled = errors.New("download cancelled")
+
+// ErrDownloadNotFound is returned by CancelDownload if no job belongs to the given download ID.
+var ErrDownloadNotFound = errors.New("download not found")
+
+// ErrAvailabilityCheckInterrupted is recorded on jobs whose availability check was interrupted by a restart.
+var ErrAvailabilityCheckInterrupted = errors.New("interrupted during availability check")
+
+// StartDownloadQueue re-queues downloads that were interrupted by a restart and starts
+// processing queued download jobs in the background until ctx is cancelled.
+func (cs *CoreService) StartDownloadQueue(ctx context.Context) {
+	cs.requeueInterruptedJobs()
+	go cs.runDownloadQueue(ctx)
+}
+
+func (cs *CoreService) wakeDownloadQueue() {
+	select {
+	case cs.queueWakeup <- struct{}{}:
+	default:
+		// a wake-up is already pending
+	}
+}
+
+// requeueInterruptedJobs queues the jobs that were still being processed when the service stopped again.
+// Jobs whose availability check was interrupted fail instead, since the availability, live status
+// and partial download policy of their download were never decided.
+func (cs *CoreService) requeueInterruptedJobs() {
+	jobs, err := cs.databaseService.GetDownloadJobsByState(database.ActiveJobStates...)
+	if err != nil {
+		slog.Error("failed to load interrupted download jobs", "err", err)
+		return
+	}
+	for _, job := range jobs {
+		slog.Info("resuming interrupted download", "jobID", job.ID, "url", job.URL)
+		_ = cs.setJobState(job, database.JobStateQueued)
+	}
+
+	jobs, err = cs.databaseService.GetDownloadJobsByState(database.JobStateCheckingAvailability)
+	if err != nil {
+		slog.Error("failed to load interrupted availability checks", "err", err)
+		return
+	}
+	for _, job := range jobs {
+		slog.Warn("failing download interrupted during its availability check", "jobID", job.ID, "url", job.URL)
+		cs.failJob(job, ErrAvailabilityCheckInterrupted)
+	}
+}
+
+func (cs *CoreService) runDownloadQueue(ctx context.Context) {
+	for {
+		cs.checkWaitingJobs(ctx)
+		cs.dispatchQueuedJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-cs.queueWakeup:
+		case <-time.After(downloadQueuePollInterval):
+		}
+	}
+}
+
+// dispatchQueuedJobs starts queued jobs, oldest first, as long as download slots are free.
+// Jobs that do not fit are picked up once a running job finishes and wakes the queue.
+func (cs *CoreService) dispatchQueuedJobs(ctx context.Context) {
+	jobs, err := cs.databaseService.GetDownloadJobsByState(database.JobStateQueued)
+	if err != nil {
+		slog.Error("failed to load queued download jobs", "err", err)
+		return
+	}
+
+	for _, job := range jobs {
+		select {
+		case cs.downloadSlots <- struct{}{}:
+		default:
+			return
+		}
+
+		// register the job before leaving the queued state so that a cancellation in between is not lost
+		jobCtx := cs.registerJob(ctx, job.ID)
+		if !cs.transitionJob(job, database.JobStateQueued, database.JobStateDownloading) {
+			cs.unregisterJob(job.ID)
+			<-cs.downloadSlots
+			continue
+		}
+		go func(job *database.DownloadJob) {
+			defer func() {
+				cs.unregisterJob(job.ID)
+				<-cs.downloadSlots
+				cs.wakeDownloadQueue()
+			}()
+			cs.processDownloadJob(jobCtx, job)
+		}(job)
+	}
+}
+
+func (cs *CoreService) processDownloadJob(ctx context.Context, job *database.DownloadJob) {
+	downloaderInstance, err := cs.downloaders.GetVideoDownloader(job.URL)
+	if err != nil {
+		slog.Error("no downloader for queued job", "jobID", job.ID, "url", job.URL, "err", err)
+		if cs.failJob(job, err) {
+			cs.notifyJob(webhook.EventDownloadFailed, job, err)
+		}
+		return
+	}
+
+	podcastItem, err := cs.handleDownload(ctx, job, downloaderInstance)
+	if err != nil {
+		if ctx.Err() != nil {
+			cs.stopJob(ctx, job)
+			return
+		}
+		slog.Error("download job failed", "jobID", job.ID, "url", job.URL, "err", err)
+		if cs.failJob(job, err) {
+			cs.notifyJob(webhook.EventDownloadFailed, job, err)
+		}
+		return
+	}
+	if cs.completeJob(job) {
+		cs.notifyCompleted(job, podcastItem)
+	}
+}
+
+// completeJob marks the job as done, unless its state changed in the meantime, e.g. because it was cancelled
+// while its audio was tagged or moved. It reports whether the job was marked as done.
+func (cs *CoreService) completeJob(job *database.DownloadJob) bool {
+	lastError, progress := job.LastError, job.Progress
+	job.LastError = ""
+	job.Progress = 100
+	if !cs.transitionJob(job, job.State, database.JobStateDone) {
+		slog.Info("download finished after its job changed state", "jobID", job.ID, "url", job.URL)
+		job.LastError, job.Progress = lastError, progress
+		return false
+	}
+	return true
+}
+
+// failJob marks the job as failed and records the cause, unless its state changed in the meantime,
+// e.g. because it was cancelled. It reports whether the job was marked as failed.
+func (cs *CoreService) failJob(job *database.DownloadJob, cause error) bool {
+	lastError, permanentFailure := job.LastError, job.PermanentFailure
+	job.LastError = cause.Error()
+	job.PermanentFailure = errors.Is(cause, downloader.ErrPermanentFailure)
+	if !cs.transitionJob(job, job.State, database.JobStateFailed) {
+		job.LastError, job.PermanentFailure = lastError, permanentFailure
+		return false
+	}
+	return true
+}
+
+// stopJob records a job whose context ended before it completed. Jobs cancelled by a user are marked as cancelled,
+// jobs interrupted by a shutdown keep their state and are resumed on the next start.
+func (cs *CoreService) stopJob(ctx context.Context, job *database.DownloadJob) {
+	if !errors.Is(context.Cause(ctx), ErrDownloadCancelled) {
+		slog.Info("download interrupted", "jobID", job.ID, "url", job.URL)
+		return
+	}
+	slog.Info("download cancelled", "jobID", job.ID, "url", job.URL)
+	_ = cs.setJobState(job, database.JobStateCancelled)
+}
+
+// CancelDownload cancels all unfinished jobs of the given download. Running yt-dlp processes are killed
+// and queued jobs are not started anymore. It returns all jobs of the download.
+func (cs *CoreService) CancelDownload(downloadID string) ([]*database.DownloadJob, error) {
+	cs.jobsMutex.Lock()
+	defer cs.jobsMutex.Unlock()
+
+	jobs, err := cs.databaseService.GetDownloadJobsByDownloadID(downloadID)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrDownloadNotFound
+	}
+
+	for _, job := range jobs {
+		if job.State.IsFinal() {
+			continue
+		}
+		if cancel, ok := cs.jobCancels[job.ID]; ok {
+			cancel(ErrDownloadCancelled)
+		}
+		if err := cs.setJobState(job, database.JobStateCancelled); err != nil {
+			return nil, err
+		}
+	}
+	slog.Info("cancelled download", "downloadID", downloadID, "jobCount", len(jobs))
+	return jobs, nil
+}
+
+// registerJob derives a cancellable context for the job from parent so that CancelDownload can stop it.
+func (cs *CoreService) registerJob(parent context.Context, jobID string) context.Context {
+	ctx, cancel := context.WithCancelCause(parent)
+	cs.jobsMutex.Lock()
+	defer cs.jobsMutex.Unlock()
+	cs.jobCancels[jobID] = cancel
+	return ctx
+}
+
+func (cs *CoreService) unregisterJob(jobID string) {
+	cs.jobsMutex.Lock()
+	defer cs.jobsMutex.Unlock()
+	if cancel, ok := cs.jobCancels[jobID]; ok {
+		cancel(nil)
+		delete(cs.jobCancels, jobID)
+	}
+}
+
+// transitionJob moves the job to state to if it is still in state from.
+// It returns false if the job changed its state in the meantime, e.g. because it was cancelled.
+func (cs *CoreService) transitionJob(job *database.DownloadJob, from database.JobState, to database.JobState) bool {
+	cs.jobsMutex.Lock()
+	defer cs.jobsMutex.Unlock()
+
+	current, err := cs.databaseService.GetDownloadJobByID(job.ID)
+	if err != nil || current.State != from {
+		return false
+	}
+	return cs.setJobState(job, to) == nil
+}
+
+// jobProgressReporter returns a progress function that records the stage and progress reported by a downloader on the job.
+// Progress within a stage is only persisted in steps to avoid a database write per yt-dlp output line.
+// Updates are dropped once ctx is cancelled or the job changed its state, so that they do not overwrite the cancelled state.
+func (cs *CoreService) jobProgressReporter(ctx context.Context, job *database.DownloadJob) downloader.ProgressFunc {
+	return func(stage downloader.Stage, percent float64) {
+		if ctx.Err() != nil {
+			return
+		}
+		state := jobStateForStage(stage)
+		if state == job.State && percent < 100 && percent-job.Progress < progressPersistStep {
+			return
+		}
+		job.Progress = percent
+		cs.transitionJob(job, job.State, state)
+	}
+}
+
+func jobStateForStage(stage downloader.Stage) database.JobState {
+	switch stage {
+	case downloader.StageTagging:
+		return database.JobStateTagging
+	case downloader.StageMoving:
+		return database.JobStateMoving
+	default:
+		return database.JobStateDownloading
+	}
+}
+
+func (cs *CoreService) setJobState(job *database.DownloadJob, state database.JobState) error {
+	job.State = state
+	job.UpdatedAt = time.Now().UTC()
+	if err := cs.databaseService.UpdateDownloadJob(job); err != nil {
+		slog.Error("failed to update download job", "jobID", job.ID, "state", state, "err", err)
+		return err
+	}
+	return nil
+}
+
+// runWithSlot runs fn once a slot of the given pool is free and releases the slot afterwards.
+// It gives up waiting when ctx is cancelled.
+func (cs *CoreService) runWithSlot(ctx context.Context, slots chan struct{}, fn func() error) error {
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	defer func() { <-slots }()
+	return fn()
+}
+
+func (cs *CoreService) maxParallelDownloads() int {
+	if cs.mediaConfig == nil || cs.mediaConfig.MaxParallelDownloads <= 0 {
+		return 1
+	}
+	return cs.mediaConfig.MaxParallelDownloads
+}
+
+func (cs *CoreService) maxParallelAvailabilityChecks() int {
+	if cs.mediaConfig == nil || cs.mediaConfig.MaxParallelAvailabilityChecks <= 0 {
+		return cs.maxParallelDownloads()
+	}
+	return cs.mediaConfig.MaxParallelAvailabilityChecks
+}
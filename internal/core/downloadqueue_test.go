@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+func TestCancelDownload_CancelsUnfinishedJobsOnly(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, nil, nil, nil, nil, nil)
+
+	downloadID := database.NewDownloadID()
+	newJob := func(state database.JobState) *database.DownloadJob {
+		job := database.NewDownloadJob("https://www.youtube.com/watch?v=" + string(state))
+		job.DownloadID = downloadID
+		job.State = state
+		_ = db.InsertDownloadJob(job)
+		return job
+	}
+	queued := newJob(database.JobStateQueued)
+	running := newJob(database.JobStateDownloading)
+	done := newJob(database.JobStateDone)
+	runningCtx := cs.registerJob(context.Background(), running.ID)
+
+	if _, err := cs.CancelDownload(downloadID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if queued.State != database.JobStateCancelled {
+		t.Errorf("expected queued job to be cancelled, got %q", queued.State)
+	}
+	if running.State != database.JobStateCancelled {
+		t.Errorf("expected running job to be cancelled, got %q", running.State)
+	}
+	if !errors.Is(context.Cause(runningCtx), ErrDownloadCancelled) {
+		t.Errorf("expected context of running job to be cancelled, got cause %v", context.Cause(runningCtx))
+	}
+	if done.State != database.JobStateDone {
+		t.Errorf("expected finished job to stay done, got %q", done.State)
+	}
+}
+
+func TestCancelDownload_RacingWithCompletion_StaysCancelled(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, nil, nil, nil, nil, nil)
+	stored := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	stored.DownloadID = database.NewDownloadID()
+	stored.State = database.JobStateTagging
+	_ = db.InsertDownloadJob(stored)
+	// the worker holds its own copy of the job, as it does when the job is loaded from the database
+	worker := *stored
+	ctx := cs.registerJob(context.Background(), worker.ID)
+	report := cs.jobProgressReporter(ctx, &worker)
+
+	if _, err := cs.CancelDownload(stored.DownloadID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report(downloader.StageMoving, 100)
+	if cs.completeJob(&worker) {
+		t.Error("expected completion of a cancelled job to be rejected")
+	}
+	if cs.failJob(&worker, errors.New("failed")) {
+		t.Error("expected failure of a cancelled job to be rejected")
+	}
+
+	if stored.State != database.JobStateCancelled {
+		t.Errorf("expected job to stay cancelled, got %q", stored.State)
+	}
+}
+
+func TestCompleteJob_MarksRunningJobAsDone(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, nil, nil, nil, nil, nil)
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	job.State = database.JobStateMoving
+	job.LastError = "attempt 1 failed"
+	_ = db.InsertDownloadJob(job)
+
+	if !cs.completeJob(job) {
+		t.Fatal("expected job to be completed")
+	}
+	if job.State != database.JobStateDone || job.LastError != "" || job.Progress != 100 {
+		t.Errorf("expected done job without error at 100%%, got %+v", job)
+	}
+}
+
+func TestFailJob_FlagsPermanentFailures(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, nil, nil, nil, nil, nil)
+	permanent := database.NewDownloadJob("https://www.youtube.com/watch?v=private")
+	_ = db.InsertDownloadJob(permanent)
+	transient := database.NewDownloadJob("https://www.youtube.com/watch?v=throttled")
+	_ = db.InsertDownloadJob(transient)
+
+	cs.failJob(permanent, fmt.Errorf("download failed permanently: %w", downloader.ErrPermanentFailure))
+	cs.failJob(transient, errors.New("HTTP Error 429"))
+
+	if permanent.State != database.JobStateFailed || !permanent.PermanentFailure {
+		t.Errorf("expected permanently failed job, got %+v", permanent)
+	}
+	if transient.State != database.JobStateFailed || transient.PermanentFailure {
+		t.Errorf("expected failed job without permanent failure flag, got %+v", transient)
+	}
+}
+
+func TestCancelDownload_UnknownID_ReturnsErrDownloadNotFound(t *testing.T) {
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, nil, nil, nil, nil, nil)
+
+	if _, err := cs.CancelDownload("unknown"); !errors.Is(err, ErrDownloadNotFound) {
+		t.Errorf("expected ErrDownloadNotFound, got %v", err)
+	}
+}
+
+func TestRequeueInterruptedJobs_FailsInterruptedAvailabilityChecks(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, nil, nil, nil, nil, nil)
+	downloading := database.NewDownloadJob("https://www.youtube.com/watch?v=downloading")
+	downloading.State = database.JobStateDownloading
+	_ = db.InsertDownloadJob(downloading)
+	checking := database.NewDownloadJob("https://www.youtube.com/watch?v=checking")
+	checking.State = database.JobStateCheckingAvailability
+	_ = db.InsertDownloadJob(checking)
+
+	cs.requeueInterruptedJobs()
+
+	if downloading.State != database.JobStateQueued {
+		t.Errorf("expected interrupted download to be queued, got %q", downloading.State)
+	}
+	if checking.State != database.JobStateFailed {
+		t.Errorf("expected interrupted availability check to fail, got %q", checking.State)
+	}
+	if checking.LastError != ErrAvailabilityCheckInterrupted.Error() {
+		t.Errorf("expected error %q, got %q", ErrAvailabilityCheckInterrupted, checking.LastError)
+	}
+}
+
+func TestTransitionJob_CancelledJobIsNotQueued(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, nil, nil, nil, nil, nil)
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	job.State = database.JobStateCancelled
+	_ = db.InsertDownloadJob(job)
+
+	if cs.transitionJob(job, database.JobStateCheckingAvailability, database.JobStateQueued) {
+		t.Error("expected transition of a cancelled job to be rejected")
+	}
+	if job.State != database.JobStateCancelled {
+		t.Errorf("expected job to stay cancelled, got %q", job.State)
+	}
+}
+
+func TestRunWithSlot_LimitIsSharedByAllCallers(t *testing.T) {
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, &config.Media{MaxParallelDownloads: 3, MaxParallelAvailabilityChecks: 2}, nil, nil, nil, nil)
+	if cap(cs.downloadSlots) != 3 {
+		t.Errorf("expected 3 download slots, got %d", cap(cs.downloadSlots))
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = cs.runWithSlot(context.Background(), cs.availabilityCheckSlots, func() error {
+				mu.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning != 2 {
+		t.Errorf("expected at most 2 concurrent availability checks, got %d", maxRunning)
+	}
+}
+
+func TestRunWithSlot_CancelledWhileWaiting_ReturnsCause(t *testing.T) {
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, nil, nil, nil, nil, nil)
+	cs.availabilityCheckSlots <- struct{}{} // occupy the only slot
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrDownloadCancelled)
+	err := cs.runWithSlot(ctx, cs.availabilityCheckSlots, func() error {
+		t.Error("did not expect fn to run")
+		return nil
+	})
+	if !errors.Is(err, ErrDownloadCancelled) {
+		t.Errorf("expected ErrDownloadCancelled, got %v", err)
+	}
+}
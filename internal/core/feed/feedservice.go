@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 
 	"github.com/jo-hoe/video-to-podcast-service/internal/core"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/common"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
 
 	"github.com/jo-hoe/gofeedx"
 )
@@ -100,9 +102,18 @@ func (fp *FeedService) createFeedItem(baseURL *url.URL, podcastItem *database.Po
 		WithAuthor(common.ValueOrDefault(podcastItem.Author, ""), "").
 		WithCreated(podcastItem.CreatedAt).
 		WithUpdated(podcastItem.UpdatedAt).
-		WithEnclosure(link, fileinfo.Size(), "audio/mpeg").
+		WithEnclosure(link, fileinfo.Size(), filemanagement.AudioMIMEType(podcastItem.AudioFilePath)).
 		WithDurationSeconds(int(podcastItem.DurationInMilliseconds / 1000)).
 		WithPSPImageHref(podcastItem.Thumbnail)
+	if len(podcastItem.Chapters) > 0 {
+		itemBuilder = itemBuilder.WithExtensions(gofeedx.ExtensionNode{
+			Name: "podcast:chapters",
+			Attrs: map[string]string{
+				"url":  fp.coreservice.GetLinkToChapters(baseURL, fp.feedItemPath, podcastItem),
+				"type": chapters.MIMEType,
+			},
+		})
+	}
 
 	return itemBuilder.Build()
 }
@@ -3,12 +3,14 @@ package feed
 import (
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/jo-hoe/gofeedx"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
 )
 
@@ -35,7 +37,7 @@ func TestCreateFeed(t *testing.T) {
 				feedAuthor:        defaultAuthor,
 				baseURL:           &url.URL{Scheme: "http", Host: "localhost"},
 				feedAudioFilePath: filepath.Join("c", "testDir", "audio.mp3"),
-				coreService:       core.NewCoreService(&database.MockDatabase{}, filepath.Join("c"), nil, nil, nil),
+				coreService:       core.NewCoreService(&database.MockDatabase{}, filepath.Join("c"), nil, nil, nil, nil, nil, nil),
 			},
 			want: &gofeedx.Feed{
 				Title:       defaultAuthor,
@@ -53,7 +55,7 @@ func TestCreateFeed(t *testing.T) {
 				feedAuthor:        defaultAuthor,
 				baseURL:           &url.URL{Scheme: "https", Host: "podcast.example.com"},
 				feedAudioFilePath: filepath.Join("c", "testDir", "audio.mp3"),
-				coreService:       core.NewCoreService(&database.MockDatabase{}, filepath.Join("c"), nil, nil, nil),
+				coreService:       core.NewCoreService(&database.MockDatabase{}, filepath.Join("c"), nil, nil, nil, nil, nil, nil),
 			},
 			want: &gofeedx.Feed{
 				Title:       defaultAuthor,
@@ -84,7 +86,7 @@ func TestNewFeedService(t *testing.T) {
 		feedBasePort string
 		feedItemPath string
 	}
-	sharedCore := core.NewCoreService(&database.MockDatabase{}, "testDir", nil, nil, nil)
+	sharedCore := core.NewCoreService(&database.MockDatabase{}, "testDir", nil, nil, nil, nil, nil, nil)
 	tests := []struct {
 		name string
 		args args
@@ -117,3 +119,86 @@ func TestNewFeedService(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateFeedItem_WithChapters_ReferencesChapters(t *testing.T) {
+	audioSourceDirectory := t.TempDir()
+	audioFilePath := filepath.Join(audioSourceDirectory, "talks", "talk.mp3")
+	if err := os.MkdirAll(filepath.Dir(audioFilePath), os.ModePerm); err != nil {
+		t.Fatalf("could not create feed directory: %v", err)
+	}
+	if err := os.WriteFile(audioFilePath, []byte("audio"), 0644); err != nil {
+		t.Fatalf("could not create audio file: %v", err)
+	}
+	fp := NewFeedService(core.NewCoreService(&database.MockDatabase{}, audioSourceDirectory, nil, nil, nil, nil, nil, nil), "8080", "v1/feeds")
+	baseURL := &url.URL{Scheme: "http", Host: "localhost"}
+
+	tests := []struct {
+		name     string
+		chapters []chapters.Chapter
+		want     []gofeedx.ExtensionNode
+	}{
+		{name: "without chapters"},
+		{
+			name:     "with chapters",
+			chapters: []chapters.Chapter{{StartSeconds: 0, Title: "Intro"}, {StartSeconds: 60, Title: "Talk"}},
+			want: []gofeedx.ExtensionNode{{
+				Name:  "podcast:chapters",
+				Attrs: map[string]string{"url": "http://localhost/v1/feeds/talks/item-1/chapters.json", "type": chapters.MIMEType},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podcastItem := &database.PodcastItem{ID: "item-1", Title: "Talk", AudioFilePath: audioFilePath, Chapters: tt.chapters}
+
+			item, err := fp.createFeedItem(baseURL, podcastItem)
+			if err != nil {
+				t.Fatalf("createFeedItem() unexpected error: %v", err)
+			}
+
+			var got []gofeedx.ExtensionNode
+			for _, extension := range item.Extensions {
+				if extension.Name == "podcast:chapters" {
+					got = append(got, extension)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chapters extensions = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateFeedItem_EnclosureTypeFollowsAudioFormat(t *testing.T) {
+	audioSourceDirectory := t.TempDir()
+	fp := NewFeedService(core.NewCoreService(&database.MockDatabase{}, audioSourceDirectory, nil, nil, nil, nil, nil, nil), "8080", "v1/feeds")
+	baseURL := &url.URL{Scheme: "http", Host: "localhost"}
+
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{fileName: "talk.mp3", want: "audio/mpeg"},
+		{fileName: "talk.m4a", want: "audio/mp4"},
+		{fileName: "talk.opus", want: "audio/ogg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			audioFilePath := filepath.Join(audioSourceDirectory, "talks", tt.fileName)
+			if err := os.MkdirAll(filepath.Dir(audioFilePath), os.ModePerm); err != nil {
+				t.Fatalf("could not create feed directory: %v", err)
+			}
+			if err := os.WriteFile(audioFilePath, []byte("audio"), 0644); err != nil {
+				t.Fatalf("could not create audio file: %v", err)
+			}
+
+			item, err := fp.createFeedItem(baseURL, &database.PodcastItem{ID: "item-1", Title: "Talk", AudioFilePath: audioFilePath})
+			if err != nil {
+				t.Fatalf("createFeedItem() unexpected error: %v", err)
+			}
+			if item.Enclosure == nil || item.Enclosure.Type != tt.want {
+				t.Errorf("enclosure = %+v, want type %q", item.Enclosure, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package filemanagement
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+)
+
+// AudioFormat describes a format audio files are stored in.
+type AudioFormat struct {
+	// Name as configured and passed to yt-dlp's --audio-format, e.g. "opus", or the extension of discovered formats
+	Name string
+	// Extension of the files including the leading dot, e.g. ".opus"
+	Extension string
+	// MIMETypes announced for the format, the first one is used for enclosures
+	MIMETypes []string
+	// contentTypes are the types http.DetectContentType reports for files of the format
+	contentTypes []string
+}
+
+// MIMEType returns the type enclosures of the format are announced with.
+func (f AudioFormat) MIMEType() string {
+	return f.MIMETypes[0]
+}
+
+// HasMIMEType reports whether the type, e.g. of a feed enclosure, is announced for the format.
+func (f AudioFormat) HasMIMEType(mimeType string) bool {
+	mimeType, _, _ = strings.Cut(strings.ToLower(mimeType), ";")
+	return slices.Contains(f.MIMETypes, strings.TrimSpace(mimeType))
+}
+
+var audioFormats = []AudioFormat{
+	{
+		Name:         config.AudioFormatMP3,
+		Extension:    ".mp3",
+		MIMETypes:    []string{"audio/mpeg", "audio/mp3"},
+		contentTypes: []string{"audio/mpeg"},
+	},
+	{
+		Name:         config.AudioFormatM4A,
+		Extension:    ".m4a",
+		MIMETypes:    []string{"audio/mp4", "audio/x-m4a", "audio/m4a"},
+		contentTypes: []string{"audio/mp4", "video/mp4"},
+	},
+	{
+		Name:         config.AudioFormatOpus,
+		Extension:    ".opus",
+		MIMETypes:    []string{"audio/ogg", "audio/opus"},
+		contentTypes: []string{"application/ogg", "audio/ogg"},
+	},
+}
+
+// discoveredAudioFormats are only picked up from the audio directory but never chosen as output format.
+// Files of these formats written by earlier versions keep showing up in their feeds; as before, MPEG audio
+// stored under their extensions is accepted as well.
+var discoveredAudioFormats = []AudioFormat{
+	{
+		Name:         "wav",
+		Extension:    ".wav",
+		MIMETypes:    []string{"audio/wav", "audio/x-wav"},
+		contentTypes: []string{"audio/wave", "audio/mpeg"},
+	},
+	{
+		Name:         "flac",
+		Extension:    ".flac",
+		MIMETypes:    []string{"audio/flac", "audio/x-flac"},
+		contentTypes: []string{"audio/mpeg"},
+	},
+	{
+		Name:         "ogg",
+		Extension:    ".ogg",
+		MIMETypes:    []string{"audio/ogg"},
+		contentTypes: []string{"application/ogg", "audio/ogg", "audio/mpeg"},
+	},
+	{
+		Name:         "mpeg",
+		Extension:    ".mpeg",
+		MIMETypes:    []string{"audio/mpeg"},
+		contentTypes: []string{"audio/mpeg"},
+	},
+}
+
+// AudioFormatByName returns the format with the given name, falling back to MP3 for unknown names.
+func AudioFormatByName(name string) AudioFormat {
+	for _, format := range audioFormats {
+		if format.Name == name {
+			return format
+		}
+	}
+	return audioFormats[0]
+}
+
+// AudioFormatOfFile returns the format of an audio file based on its extension, including discovered-only formats.
+func AudioFormatOfFile(filePath string) (AudioFormat, bool) {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	for _, format := range slices.Concat(audioFormats, discoveredAudioFormats) {
+		if format.Extension == extension {
+			return format, true
+		}
+	}
+	return AudioFormat{}, false
+}
+
+// AudioMIMEType returns the type an audio file is served with, audio/mpeg for files of unknown format.
+func AudioMIMEType(filePath string) string {
+	if format, ok := AudioFormatOfFile(filePath); ok {
+		return format.MIMEType()
+	}
+	return audioFormats[0].MIMEType()
+}
@@ -0,0 +1,43 @@
+package filemanagement
+
+import (
+	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+)
+
+func TestAudioMIMEType(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     string
+	}{
+		{filePath: "channel/episode.mp3", want: "audio/mpeg"},
+		{filePath: "channel/episode.M4A", want: "audio/mp4"},
+		{filePath: "channel/episode.opus", want: "audio/ogg"},
+		{filePath: "channel/episode.flac", want: "audio/flac"},
+		{filePath: "channel/episode", want: "audio/mpeg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			if got := AudioMIMEType(tt.filePath); got != tt.want {
+				t.Errorf("AudioMIMEType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudioFormat_HasMIMEType(t *testing.T) {
+	opus := AudioFormatByName(config.AudioFormatOpus)
+	if !opus.HasMIMEType("Audio/Opus; codecs=opus") {
+		t.Errorf("expected opus to have MIME type audio/opus")
+	}
+	if opus.HasMIMEType("audio/mpeg") {
+		t.Errorf("expected opus not to have MIME type audio/mpeg")
+	}
+	if got := AudioFormatByName("flac").Name; got != config.AudioFormatMP3 {
+		t.Errorf("AudioFormatByName() = %q, want discovered-only format to fall back to %q", got, config.AudioFormatMP3)
+	}
+	if got := AudioFormatByName("unknown").Name; got != config.AudioFormatMP3 {
+		t.Errorf("AudioFormatByName() = %q, want fallback %q", got, config.AudioFormatMP3)
+	}
+}
@@ -5,17 +5,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
-var supportedAudioFileExtensions = map[string]bool{
-	"mp3":  true,
-	"wav":  true,
-	"flac": true,
-	"ogg":  true,
-	"mpeg": true,
-}
-
 func GetAudioFiles(directoryPath string) (result []string, err error) {
 	// take an input directory and return all audio files in it
 	result = make([]string, 0)
@@ -34,12 +27,8 @@ func GetAudioFiles(directoryPath string) (result []string, err error) {
 
 func isSupportedAudioFile(filePath string) bool {
 	// plain file extension check
-	fileExtension := strings.ToLower(filepath.Ext(filePath))
-	fileExtensionParts := strings.Split(fileExtension, ".")
-	if len(fileExtensionParts) != 2 {
-		return false
-	}
-	if !supportedAudioFileExtensions[fileExtensionParts[1]] {
+	format, ok := AudioFormatOfFile(filePath)
+	if !ok {
 		return false
 	}
 
@@ -58,18 +47,7 @@ func isSupportedAudioFile(filePath string) bool {
 	if err != nil {
 		return false
 	}
-	// check content type which looks like "audio/mp3"
-	contentType := http.DetectContentType(buffer)
-	contentTypeParts := strings.Split(contentType, "/")
-	if len(contentTypeParts) != 2 {
-		return false
-	}
-	if contentTypeParts[0] != "audio" {
-		return false
-	}
-	if !supportedAudioFileExtensions[contentTypeParts[1]] {
-		return false
-	}
-
-	return true
+	// check content type which looks like "audio/mpeg", files in an MPEG-4 container are sniffed as "video/mp4"
+	contentType, _, _ := strings.Cut(http.DetectContentType(buffer), ";")
+	return slices.Contains(format.contentTypes, contentType)
 }
@@ -1,6 +1,7 @@
 package filemanagement
 
 import (
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -39,3 +40,61 @@ func TestGetAudioFiles_NonExistingDirectoryReturnsError(t *testing.T) {
 		t.Fatalf("GetAudioFiles() expected 0 results, got %d", len(gotResult))
 	}
 }
+
+func TestGetAudioFiles_FindsM4AAndOpusFiles(t *testing.T) {
+	directory := t.TempDir()
+	files := map[string][]byte{
+		"episode.m4a":  append([]byte("\x00\x00\x00\x1cftypM4A \x00\x00\x00\x00M4A mp42isom"), make([]byte, 64)...),
+		"episode.opus": append([]byte("OggS\x00\x02"), make([]byte, 64)...),
+		"fake.opus":    []byte("not an ogg stream"),
+		"episode.txt":  []byte("OggS\x00\x02"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), content, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	gotResult, err := GetAudioFiles(directory)
+	if err != nil {
+		t.Fatalf("GetAudioFiles() unexpected error: %v", err)
+	}
+	wantResult := []string{
+		filepath.Join(directory, "episode.m4a"),
+		filepath.Join(directory, "episode.opus"),
+	}
+	if !reflect.DeepEqual(gotResult, wantResult) {
+		t.Fatalf("GetAudioFiles() = %v, want %v", gotResult, wantResult)
+	}
+}
+
+func TestGetAudioFiles_FindsPreexistingFilesOfDiscoveredFormats(t *testing.T) {
+	directory := t.TempDir()
+	mpegAudio := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), make([]byte, 64)...)
+	files := map[string][]byte{
+		"episode.flac": mpegAudio,
+		"episode.mpeg": mpegAudio,
+		"episode.ogg":  append([]byte("OggS\x00\x02"), make([]byte, 64)...),
+		"episode.wav":  append([]byte("RIFF\x00\x00\x00\x00WAVEfmt "), make([]byte, 64)...),
+		"fake.wav":     []byte("not a wave file"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), content, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	gotResult, err := GetAudioFiles(directory)
+	if err != nil {
+		t.Fatalf("GetAudioFiles() unexpected error: %v", err)
+	}
+	wantResult := []string{
+		filepath.Join(directory, "episode.flac"),
+		filepath.Join(directory, "episode.mpeg"),
+		filepath.Join(directory, "episode.ogg"),
+		filepath.Join(directory, "episode.wav"),
+	}
+	if !reflect.DeepEqual(gotResult, wantResult) {
+		t.Fatalf("GetAudioFiles() = %v, want %v", gotResult, wantResult)
+	}
+}
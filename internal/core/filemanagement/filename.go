@@ -0,0 +1,20 @@
+package filemanagement
+
+import (
+	"regexp"
+	"strings"
+)
+
+// unsafeFileNameCharacters are replaced in file and feed names derived from user input, URLs and headers.
+var unsafeFileNameCharacters = regexp.MustCompile(`[^\p{L}\p{N} ._-]+`)
+
+// SanitizeFileName returns name as a single, visible path component. Unsafe characters are replaced by underscores,
+// names that end up empty are replaced by fallback.
+func SanitizeFileName(name string, fallback string) string {
+	// leading dots would hide the file or refer to a parent directory
+	name = strings.Trim(unsafeFileNameCharacters.ReplaceAllString(name, "_"), " .")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
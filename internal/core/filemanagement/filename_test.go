@@ -0,0 +1,24 @@
+package filemanagement
+
+import "testing"
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "keeps safe names", input: "Weekly Meeting 2024-03-01", want: "Weekly Meeting 2024-03-01"},
+		{name: "replaces path separators", input: "team/meetings\\notes", want: "team_meetings_notes"},
+		{name: "removes parent directory references", input: "../talks", want: "_talks"},
+		{name: "keeps unicode letters", input: "Besprechung über Äpfel", want: "Besprechung über Äpfel"},
+		{name: "falls back for empty names", input: " .. ", want: "media"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFileName(tt.input, "media"); got != tt.want {
+				t.Errorf("SanitizeFileName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
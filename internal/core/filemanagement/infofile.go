@@ -0,0 +1,15 @@
+package filemanagement
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// InfoFileExtension is the extension of the file next to an audio file that holds the metadata of its source,
+// e.g. channel/title_id.info.json for channel/title_id.mp3.
+const InfoFileExtension = ".info.json"
+
+// InfoFilePath returns the path of the source metadata file of an audio file.
+func InfoFilePath(audioFilePath string) string {
+	return strings.TrimSuffix(audioFilePath, filepath.Ext(audioFilePath)) + InfoFileExtension
+}
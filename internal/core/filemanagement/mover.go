@@ -14,6 +14,7 @@ import (
 // MoveToTarget moves sourcePath into a subdirectory of targetRootPath.
 // The subdirectory name is taken from the immediate parent directory of sourcePath.
 // e.g. sourcePath=/tmp/abc/channel/file.mp3, targetRootPath=/podcasts → /podcasts/channel/file.mp3
+// The source metadata file of sourcePath is moved along if it exists, see InfoFilePath.
 func MoveToTarget(sourcePath, targetRootPath string) (string, error) {
 	directoryName := filepath.Base(filepath.Dir(sourcePath))
 	targetSubDirectory := filepath.Join(targetRootPath, directoryName)
@@ -25,6 +26,11 @@ func MoveToTarget(sourcePath, targetRootPath string) (string, error) {
 	if err := MoveFile(sourcePath, targetPath); err != nil {
 		return "", err
 	}
+	if doesFileExist(InfoFilePath(sourcePath)) {
+		if err := MoveFile(InfoFilePath(sourcePath), InfoFilePath(targetPath)); err != nil {
+			return "", err
+		}
+	}
 	return targetPath, nil
 }
 
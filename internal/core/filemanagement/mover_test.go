@@ -57,6 +57,9 @@ func TestMoveToTarget(t *testing.T) {
 	if err := sourceFile.Close(); err != nil {
 		t.Fatalf("could not close source file: %v", err)
 	}
+	if err := os.WriteFile(InfoFilePath(sourcePath), []byte(`{"id":"abc"}`), 0644); err != nil {
+		t.Fatalf("could not create info file: %v", err)
+	}
 
 	targetRoot, err := os.MkdirTemp(os.TempDir(), "targetDir")
 	if err != nil {
@@ -83,6 +86,9 @@ func TestMoveToTarget(t *testing.T) {
 	if _, err := os.Stat(sourcePath); !errors.Is(err, os.ErrNotExist) {
 		t.Errorf("source file still exists at %q after move", sourcePath)
 	}
+	if _, err := os.Stat(InfoFilePath(result)); err != nil {
+		t.Errorf("info file was not moved along: %v", err)
+	}
 }
 
 func TestMoveFile(t *testing.T) {
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+// contentFilter decides which of the listed videos are downloaded, see config.Filters.
+type contentFilter struct {
+	filters      config.Filters
+	includeTitle *regexp.Regexp
+	excludeTitle *regexp.Regexp
+}
+
+func newContentFilter(filters config.Filters) (*contentFilter, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+	filter := &contentFilter{filters: filters}
+	if filters.IncludeTitle != "" {
+		filter.includeTitle = regexp.MustCompile(filters.IncludeTitle)
+	}
+	if filters.ExcludeTitle != "" {
+		filter.excludeTitle = regexp.MustCompile(filters.ExcludeTitle)
+	}
+	return filter, nil
+}
+
+// skipReason returns why the video is filtered out, or an empty string if it passes all filters.
+// Duration filters do not apply to videos of unknown length, e.g. live streams.
+func (f *contentFilter) skipReason(entryURL string, info *downloader.VideoInfo) string {
+	duration := time.Duration(info.DurationSeconds * float64(time.Second)).Round(time.Second)
+	switch {
+	case f.filters.ExcludeShorts && (info.Short || downloader.IsShortURL(entryURL)):
+		return "video is a short"
+	case f.filters.MinDuration > 0 && duration > 0 && duration < f.filters.MinDuration:
+		return fmt.Sprintf("duration %s is shorter than %s", duration, f.filters.MinDuration)
+	case f.filters.MaxDuration > 0 && duration > f.filters.MaxDuration:
+		return fmt.Sprintf("duration %s is longer than %s", duration, f.filters.MaxDuration)
+	case f.includeTitle != nil && !f.includeTitle.MatchString(info.Title):
+		return fmt.Sprintf("title does not match %q", f.filters.IncludeTitle)
+	case f.excludeTitle != nil && f.excludeTitle.MatchString(info.Title):
+		return fmt.Sprintf("title matches %q", f.filters.ExcludeTitle)
+	}
+	return ""
+}
+
+// contentFilters returns the filters of the download options, falling back to the configured filters.
+func (cs *CoreService) contentFilters(options DownloadOptions) config.Filters {
+	if options.Filters != nil {
+		return *options.Filters
+	}
+	if cs.filtersConfig == nil {
+		return config.Filters{}
+	}
+	return *cs.filtersConfig
+}
+
+// filterVideos probes the metadata of the listed videos and returns the reason for every video that is filtered out,
+// keyed by its listed URL. Entries selected explicitly, videos already in the library and videos that cannot be probed
+// are not filtered; the latter are left to the availability check.
+func (cs *CoreService) filterVideos(ctx context.Context, downloaderInstance downloader.AudioDownloader, urls []string, options DownloadOptions) (map[string]string, error) {
+	filters := cs.contentFilters(options)
+	if filters.IsZero() || len(options.Entries) > 0 {
+		return nil, nil
+	}
+	filter, err := newContentFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	candidates := make([]string, 0, len(urls))
+	for _, entryURL := range urls {
+		if options.Force || !cs.isInLibrary(downloaderInstance.NormalizeVideoURL(entryURL)) {
+			candidates = append(candidates, entryURL)
+		}
+	}
+
+	skipReasons := make(map[string]string)
+	for i, entry := range cs.previewEntries(ctx, downloaderInstance, candidates) {
+		if entry.Error != "" {
+			continue
+		}
+		if reason := filter.skipReason(candidates[i], &entry.VideoInfo); reason != "" {
+			skipReasons[candidates[i]] = reason
+		}
+	}
+	if len(skipReasons) > 0 {
+		slog.Info("filtered videos", "filteredCount", len(skipReasons), "entryCount", len(urls))
+	}
+	return skipReasons, nil
+}
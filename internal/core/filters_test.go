@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+func TestContentFilter_SkipReason(t *testing.T) {
+	episode := &downloader.VideoInfo{Title: "Episode 12: Interview", DurationSeconds: 3600}
+
+	tests := []struct {
+		name     string
+		filters  config.Filters
+		entryURL string
+		info     *downloader.VideoInfo
+		skipped  bool
+	}{
+		{name: "no filters", filters: config.Filters{}, info: episode, skipped: false},
+		{name: "too short", filters: config.Filters{MinDuration: 2 * time.Hour}, info: episode, skipped: true},
+		{name: "too long", filters: config.Filters{MaxDuration: 30 * time.Minute}, info: episode, skipped: true},
+		{name: "unknown duration", filters: config.Filters{MinDuration: time.Minute}, info: &downloader.VideoInfo{Title: "Live"}, skipped: false},
+		{name: "title included", filters: config.Filters{IncludeTitle: `(?i)^episode \d+`}, info: episode, skipped: false},
+		{name: "title not included", filters: config.Filters{IncludeTitle: "Trailer"}, info: episode, skipped: true},
+		{name: "title excluded", filters: config.Filters{ExcludeTitle: "Interview"}, info: episode, skipped: true},
+		{name: "short", filters: config.Filters{ExcludeShorts: true}, info: &downloader.VideoInfo{Title: "Clip", Short: true}, skipped: true},
+		{name: "short url", filters: config.Filters{ExcludeShorts: true}, entryURL: "https://www.youtube.com/shorts/abc", info: &downloader.VideoInfo{Title: "Clip"}, skipped: true},
+		{name: "long-form video", filters: config.Filters{ExcludeShorts: true}, entryURL: "https://www.youtube.com/watch?v=abc", info: episode, skipped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newContentFilter(tt.filters)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			reason := filter.skipReason(tt.entryURL, tt.info)
+			if (reason != "") != tt.skipped {
+				t.Errorf("skipReason() = %q, want skipped %v", reason, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestFilterVideos_RequestFiltersReplaceConfiguredFilters(t *testing.T) {
+	configured := &config.Filters{ExcludeTitle: "Talk"}
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, &config.Media{}, nil, nil, configured, nil)
+	short := "https://www.youtube.com/watch?v=short"
+	long := "https://www.youtube.com/watch?v=long"
+	unknown := "https://www.youtube.com/watch?v=unknown"
+	probe := &probeDownloader{infos: map[string]*downloader.VideoInfo{
+		short: {Title: "Short Talk", DurationSeconds: 120},
+		long:  {Title: "Long Talk", DurationSeconds: 3600},
+	}}
+	urls := []string{short, long, unknown}
+
+	skipReasons, err := cs.filterVideos(context.Background(), probe, urls, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(skipReasons) != 2 || skipReasons[unknown] != "" {
+		t.Errorf("expected both probed videos to be filtered by the configured filters, got %v", skipReasons)
+	}
+
+	skipReasons, err = cs.filterVideos(context.Background(), probe, urls, DownloadOptions{Filters: &config.Filters{MinDuration: 10 * time.Minute}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(skipReasons) != 1 || skipReasons[short] == "" {
+		t.Errorf("expected only the short video to be filtered, got %v", skipReasons)
+	}
+
+	skipReasons, err = cs.filterVideos(context.Background(), probe, urls, DownloadOptions{Entries: urls})
+	if err != nil || len(skipReasons) != 0 {
+		t.Errorf("expected selected entries not to be filtered, got %v, %v", skipReasons, err)
+	}
+}
+
+func TestScheduleDownloads_FilteredVideosAreRecorded(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{}, nil, nil, &config.Filters{ExcludeShorts: true}, nil)
+	clip := "https://www.youtube.com/watch?v=clip"
+	probe := &probeDownloader{infos: map[string]*downloader.VideoInfo{
+		clip: {Title: "Clip", DurationSeconds: 30, Short: true},
+	}}
+
+	jobs, err := cs.scheduleDownloads(context.Background(), probe, clip, []string{clip}, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].State != database.JobStateFiltered || jobs[0].LastError == "" {
+		t.Fatalf("expected a filtered job with a reason, got %+v", jobs)
+	}
+	if stored, _ := db.GetDownloadJobByID(jobs[0].ID); stored == nil || stored.State != database.JobStateFiltered {
+		t.Errorf("expected the filtered job to be persisted, got %+v", stored)
+	}
+}
@@ -1,21 +1,30 @@
 package core
 
 import (
+	"context"
+	"io"
 	"net/url"
 
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 )
 
 // MockService is a test double for Service. Override fields to inject specific behaviour;
 // zero values produce safe no-op defaults.
 type MockService struct {
-	DatabaseService         database.DatabaseService
-	AudioSourceDirectory    string
-	CookieConfig            *config.Cookies
-	DownloadItemsHandlerFunc func(url string) error
-	DeletePodcastItemFunc   func(id string) error
-	GetFeedDirectoryFunc    func(audioFilePath string) (string, error)
+	DatabaseService          database.DatabaseService
+	AudioSourceDirectory     string
+	CookieConfig             *config.Cookies
+	IsURLSupportedFunc       func(url string) bool
+	DownloadItemsHandlerFunc func(url string, options DownloadOptions) ([]*database.DownloadJob, error)
+	PreviewItemsFunc         func(url string, selection downloader.Selection) ([]*PreviewEntry, error)
+	UploadItemFunc           func(fileName string, content io.Reader, options UploadOptions) (*database.PodcastItem, error)
+	CancelDownloadFunc       func(downloadID string) ([]*database.DownloadJob, error)
+	AddSubscriptionFunc      func(url string, latest int) (*database.Subscription, error)
+	DeleteSubscriptionFunc   func(id string) error
+	DeletePodcastItemFunc    func(id string) error
+	GetFeedDirectoryFunc     func(audioFilePath string) (string, error)
 }
 
 func NewMockService() *MockService {
@@ -51,6 +60,10 @@ func (m *MockService) GetLinkToAudioFile(_ *url.URL, _ string, _ string) string
 	return ""
 }
 
+func (m *MockService) GetLinkToChapters(_ *url.URL, _ string, _ *database.PodcastItem) string {
+	return ""
+}
+
 func (m *MockService) DeletePodcastItem(id string) error {
 	if m.DeletePodcastItemFunc != nil {
 		return m.DeletePodcastItemFunc(id)
@@ -58,9 +71,55 @@ func (m *MockService) DeletePodcastItem(id string) error {
 	return nil
 }
 
-func (m *MockService) DownloadItemsHandler(url string) error {
+func (m *MockService) IsURLSupported(url string) bool {
+	if m.IsURLSupportedFunc != nil {
+		return m.IsURLSupportedFunc(url)
+	}
+	return true
+}
+
+func (m *MockService) DownloadItemsHandler(_ context.Context, url string, options DownloadOptions) ([]*database.DownloadJob, error) {
 	if m.DownloadItemsHandlerFunc != nil {
-		return m.DownloadItemsHandlerFunc(url)
+		return m.DownloadItemsHandlerFunc(url, options)
+	}
+	return []*database.DownloadJob{}, nil
+}
+
+func (m *MockService) PreviewItems(_ context.Context, url string, selection downloader.Selection) ([]*PreviewEntry, error) {
+	if m.PreviewItemsFunc != nil {
+		return m.PreviewItemsFunc(url, selection)
+	}
+	return []*PreviewEntry{}, nil
+}
+
+func (m *MockService) UploadItem(fileName string, content io.Reader, options UploadOptions) (*database.PodcastItem, error) {
+	if m.UploadItemFunc != nil {
+		return m.UploadItemFunc(fileName, content, options)
+	}
+	return &database.PodcastItem{Title: fileName}, nil
+}
+
+func (m *MockService) CancelDownload(downloadID string) ([]*database.DownloadJob, error) {
+	if m.CancelDownloadFunc != nil {
+		return m.CancelDownloadFunc(downloadID)
+	}
+	return []*database.DownloadJob{}, nil
+}
+
+func (m *MockService) AddSubscription(url string, latest int) (*database.Subscription, error) {
+	if m.AddSubscriptionFunc != nil {
+		return m.AddSubscriptionFunc(url, latest)
+	}
+	return database.NewSubscription(url, latest), nil
+}
+
+func (m *MockService) GetSubscriptions() ([]*database.Subscription, error) {
+	return m.DatabaseService.GetAllSubscriptions()
+}
+
+func (m *MockService) DeleteSubscription(id string) error {
+	if m.DeleteSubscriptionFunc != nil {
+		return m.DeleteSubscriptionFunc(id)
 	}
 	return nil
 }
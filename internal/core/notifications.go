@@ -0,0 +1,44 @@
+package core
+
+import (
+	"log/slog"
+	"net/url"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/webhook"
+)
+
+// notifyJob sends a webhook event about the job. cause is recorded as the error of the event if set.
+func (cs *CoreService) notifyJob(eventType webhook.EventType, job *database.DownloadJob, cause error) {
+	event := webhook.Event{Type: eventType, Job: job}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+	cs.notifier.Notify(event)
+}
+
+// notifyCompleted sends a webhook event about the podcast item created for the job,
+// including links to its feed and audio file if the public base URL of the service is configured.
+// The source metadata of the item is left out since it can be large, it is available via the items API.
+func (cs *CoreService) notifyCompleted(job *database.DownloadJob, podcastItem *database.PodcastItem) {
+	payloadItem := *podcastItem
+	payloadItem.SourceMetadata = nil
+	event := webhook.Event{Type: webhook.EventDownloadCompleted, Job: job, PodcastItem: &payloadItem}
+	if baseURL := cs.webhookBaseURL(); baseURL != nil {
+		event.FeedURL = cs.GetLinkToFeed(baseURL, FeedsPath, podcastItem.AudioFilePath)
+		event.AudioURL = cs.GetLinkToAudioFile(baseURL, FeedsPath, podcastItem.AudioFilePath)
+	}
+	cs.notifier.Notify(event)
+}
+
+func (cs *CoreService) webhookBaseURL() *url.URL {
+	if cs.webhooksConfig == nil || cs.webhooksConfig.BaseURL == "" {
+		return nil
+	}
+	baseURL, err := url.Parse(cs.webhooksConfig.BaseURL)
+	if err != nil {
+		slog.Warn("invalid webhook base url, omitting links", "baseURL", cs.webhooksConfig.BaseURL, "err", err)
+		return nil
+	}
+	return baseURL
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/webhook"
+)
+
+func TestNotifyCompleted_IncludesFeedAndAudioLinks(t *testing.T) {
+	received := make(chan webhook.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhook.Event
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		received <- event
+	}))
+	defer server.Close()
+
+	audioSourceDirectory := filepath.Join("media")
+	webhooksConfig := &config.Webhooks{
+		BaseURL:   "https://podcasts.example.com",
+		Endpoints: []config.WebhookEndpoint{{URL: server.URL}},
+	}
+	cs := NewCoreService(database.NewMockDatabase(), audioSourceDirectory, nil, nil, nil, webhooksConfig, nil, nil)
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	podcastItem := &database.PodcastItem{
+		ID:             "item",
+		AudioFilePath:  filepath.Join(audioSourceDirectory, "Channel", "episode.mp3"),
+		SourceMetadata: json.RawMessage(`{"id":"abc"}`),
+	}
+
+	cs.notifyCompleted(job, podcastItem)
+
+	select {
+	case event := <-received:
+		if event.Type != webhook.EventDownloadCompleted {
+			t.Errorf("expected event %q, got %q", webhook.EventDownloadCompleted, event.Type)
+		}
+		if event.FeedURL != "https://podcasts.example.com/v1/feeds/Channel/rss.xml" {
+			t.Errorf("unexpected feed url %q", event.FeedURL)
+		}
+		if event.AudioURL != "https://podcasts.example.com/v1/feeds/Channel/episode.mp3" {
+			t.Errorf("unexpected audio url %q", event.AudioURL)
+		}
+		if event.PodcastItem == nil || event.PodcastItem.ID != podcastItem.ID {
+			t.Errorf("expected podcast item in payload, got %+v", event.PodcastItem)
+		}
+		if event.PodcastItem != nil && event.PodcastItem.SourceMetadata != nil {
+			t.Errorf("expected source metadata to be left out, got %s", event.PodcastItem.SourceMetadata)
+		}
+		if podcastItem.SourceMetadata == nil {
+			t.Error("expected the source metadata of the stored item to be kept")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
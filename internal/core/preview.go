@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+// DefaultPreviewLatest is the number of newest videos previewed if no selection is given,
+// so that previewing a channel does not probe its whole back catalogue.
+const DefaultPreviewLatest = 50
+
+// PreviewEntry describes a video that would be downloaded for a submitted URL.
+type PreviewEntry struct {
+	downloader.VideoInfo
+	InLibrary bool   `json:"in_library"`
+	Error     string `json:"error,omitempty"` // set if the metadata of the video could not be probed
+}
+
+// PreviewItems resolves the given URL into its individual videos and probes their metadata without downloading them.
+// The selection limits the entries of playlists and channels as for DownloadItemsHandler, a zero selection previews
+// the newest DefaultPreviewLatest entries. Entries are returned in listing order; videos whose metadata cannot be
+// probed are reported with an error.
+func (cs *CoreService) PreviewItems(ctx context.Context, url string, selection downloader.Selection) ([]*PreviewEntry, error) {
+	downloaderInstance, err := cs.downloaders.GetVideoDownloader(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrURLNotSupported, url)
+	}
+	urls, err := downloaderInstance.ListIndividualVideoURLs(ctx, url, previewSelection(selection))
+	if err != nil {
+		slog.Error("failed to list video urls", "url", url, "err", err)
+		return nil, fmt.Errorf("failed to list urls for %s: %w", url, err)
+	}
+	return cs.previewEntries(ctx, downloaderInstance, urls), nil
+}
+
+// previewSelection returns the given selection or, if it is zero, the newest DefaultPreviewLatest entries.
+func previewSelection(selection downloader.Selection) downloader.Selection {
+	if selection.IsZero() {
+		return downloader.Selection{Latest: DefaultPreviewLatest}
+	}
+	return selection
+}
+
+// previewEntries probes the given videos concurrently, bounded by the service-wide availability check limit.
+func (cs *CoreService) previewEntries(ctx context.Context, downloaderInstance downloader.AudioDownloader, urls []string) []*PreviewEntry {
+	entries := make([]*PreviewEntry, len(urls))
+	var wg sync.WaitGroup
+	for i, entryURL := range urls {
+		videoURL := downloaderInstance.NormalizeVideoURL(entryURL)
+		entry := &PreviewEntry{InLibrary: cs.isInLibrary(videoURL)}
+		entries[i] = entry
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var info *downloader.VideoInfo
+			err := cs.runWithSlot(ctx, cs.availabilityCheckSlots, func() (err error) {
+				info, err = downloaderInstance.GetVideoInfo(ctx, videoURL)
+				return err
+			})
+			if err != nil {
+				slog.Warn("failed to probe video", "url", videoURL, "err", err)
+				entry.Error = err.Error()
+			} else {
+				entry.VideoInfo = *info
+			}
+			// report the URL that is submitted for download, not the one yt-dlp resolved
+			entry.URL = videoURL
+		}()
+	}
+	wg.Wait()
+	return entries
+}
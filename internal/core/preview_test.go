@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+// probeDownloader is an AudioDownloader that reports fixed metadata per video URL.
+type probeDownloader struct {
+	downloader.AudioDownloader
+	infos map[string]*downloader.VideoInfo
+}
+
+func (d *probeDownloader) NormalizeVideoURL(url string) string {
+	return url
+}
+
+func (d *probeDownloader) GetVideoInfo(ctx context.Context, url string) (*downloader.VideoInfo, error) {
+	info, ok := d.infos[url]
+	if !ok {
+		return nil, errors.New("video unavailable")
+	}
+	return info, nil
+}
+
+func TestPreviewEntries(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{}, nil, nil, nil, nil)
+	inLibrary := "https://www.youtube.com/watch?v=library"
+	db.Items[database.PodcastItemIDForVideoURL(inLibrary)] = &database.PodcastItem{VideoURL: inLibrary}
+	available := "https://www.youtube.com/watch?v=new"
+	removed := "https://www.youtube.com/watch?v=removed"
+	probe := &probeDownloader{infos: map[string]*downloader.VideoInfo{
+		inLibrary: {Title: "Old Talk", URL: inLibrary},
+		available: {Title: "New Talk", DurationSeconds: 60, URL: "https://www.youtube.com/watch?v=new&feature=resolved"},
+	}}
+
+	entries := cs.previewEntries(context.Background(), probe, []string{inLibrary, available, removed})
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if !entries[0].InLibrary || entries[0].Title != "Old Talk" {
+		t.Errorf("unexpected entry for video in library: %+v", entries[0])
+	}
+	if entries[1].InLibrary || entries[1].Title != "New Talk" || entries[1].URL != available {
+		t.Errorf("unexpected entry for new video: %+v", entries[1])
+	}
+	if entries[2].Error == "" || entries[2].URL != removed {
+		t.Errorf("expected probe error for removed video, got %+v", entries[2])
+	}
+}
+
+func TestPreviewSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection downloader.Selection
+		want      downloader.Selection
+	}{
+		{name: "zero selection previews the newest entries", want: downloader.Selection{Latest: DefaultPreviewLatest}},
+		{name: "item ranges are kept", selection: downloader.Selection{Items: "1-5"}, want: downloader.Selection{Items: "1-5"}},
+		{name: "latest is kept", selection: downloader.Selection{Latest: 3}, want: downloader.Selection{Latest: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previewSelection(tt.selection); got != tt.want {
+				t.Errorf("expected selection %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDownloadItemsHandler_UnsupportedEntry_ReturnsError(t *testing.T) {
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, &config.Media{}, nil, nil, nil, nil)
+
+	_, err := cs.DownloadItemsHandler(context.Background(), "https://www.youtube.com/playlist?list=abc", DownloadOptions{Entries: []string{"https://example.com/video"}})
+	if !errors.Is(err, ErrURLNotSupported) {
+		t.Errorf("expected ErrURLNotSupported for an entry that is not supported by the downloader, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+)
+
+// retryPolicy returns the configured retry policy, falling back to the defaults for unset values.
+func (cs *CoreService) retryPolicy() config.Retry {
+	if cs.mediaConfig == nil {
+		return config.DefaultRetry()
+	}
+	policy := cs.mediaConfig.Retry
+	defaults := config.DefaultRetry()
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaults.InitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaults.MaxBackoff
+	}
+	return policy
+}
+
+// retryBackoff returns the delay before the retry that follows the given failed attempt (starting at 1).
+func retryBackoff(policy config.Retry, attempt int) time.Duration {
+	return backoffDelay(policy, attempt, rand.Float64())
+}
+
+// backoffDelay doubles the initial backoff with every attempt, caps it at the maximum backoff
+// and varies it by up to +/- jitter. random is expected in the range [0, 1).
+func backoffDelay(policy config.Retry, attempt int, random float64) time.Duration {
+	delay := float64(policy.InitialBackoff) * math.Pow(2, float64(max(attempt-1, 0)))
+	delay = math.Min(delay, float64(policy.MaxBackoff))
+	delay *= 1 + policy.Jitter*(2*random-1)
+	return time.Duration(math.Min(delay, float64(policy.MaxBackoff)))
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	policy := config.Retry{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute, Jitter: 0.5}
+	tests := []struct {
+		name    string
+		attempt int
+		random  float64
+		want    time.Duration
+	}{
+		{name: "first retry without jitter", attempt: 1, random: 0.5, want: 10 * time.Second},
+		{name: "doubles with every attempt", attempt: 3, random: 0.5, want: 40 * time.Second},
+		{name: "capped at max backoff", attempt: 10, random: 0.5, want: time.Minute},
+		{name: "negative jitter", attempt: 1, random: 0, want: 5 * time.Second},
+		{name: "positive jitter", attempt: 3, random: 0.75, want: 50 * time.Second},
+		{name: "jitter does not exceed max backoff", attempt: 4, random: 0.9, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoffDelay(policy, tt.attempt, tt.random); got != tt.want {
+				t.Errorf("backoffDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package core
 
 import (
+	"context"
+	"io"
 	"net/url"
 
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 )
 
 // Service is the interface that the API layer depends on.
@@ -15,6 +18,14 @@ type Service interface {
 	GetFeedDirectory(audioFilePath string) (string, error)
 	GetLinkToFeed(baseURL *url.URL, apiPath string, audioFilePath string) string
 	GetLinkToAudioFile(baseURL *url.URL, apiPath string, audioFilePath string) string
+	GetLinkToChapters(baseURL *url.URL, apiPath string, podcastItem *database.PodcastItem) string
 	DeletePodcastItem(id string) error
-	DownloadItemsHandler(url string) error
+	IsURLSupported(url string) bool
+	DownloadItemsHandler(ctx context.Context, url string, options DownloadOptions) ([]*database.DownloadJob, error)
+	PreviewItems(ctx context.Context, url string, selection downloader.Selection) ([]*PreviewEntry, error)
+	UploadItem(fileName string, content io.Reader, options UploadOptions) (*database.PodcastItem, error)
+	CancelDownload(downloadID string) ([]*database.DownloadJob, error)
+	AddSubscription(url string, latest int) (*database.Subscription, error)
+	GetSubscriptions() ([]*database.Subscription, error)
+	DeleteSubscription(id string) error
 }
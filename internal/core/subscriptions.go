@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+// subscriptionCheckInterval is the interval at which the poller looks for subscriptions that are due.
+const subscriptionCheckInterval = time.Minute
+
+// ErrSubscriptionNotFound is returned if no subscription with the given ID exists.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrSubscriptionExists is returned when subscribing to a URL that is already subscribed.
+var ErrSubscriptionExists = errors.New("subscription already exists")
+
+// ErrSubscriptionNotSupported is returned when subscribing to a URL that no downloader supports.
+var ErrSubscriptionNotSupported = errors.New("subscription url not supported")
+
+// ErrSubscriptionNotAList is returned when subscribing to a URL that is not a channel, playlist or feed, e.g. a single video.
+var ErrSubscriptionNotAList = errors.New("subscription url is not a channel, playlist or feed")
+
+// DefaultSubscriptionLatest is the number of newest videos a subscription checks if none is given,
+// so that subscribing to a channel does not download its whole back catalogue.
+const DefaultSubscriptionLatest = 10
+
+// subscriptionRetryStates are the states of jobs whose videos are queued again by the next poll.
+var subscriptionRetryStates = []database.JobState{database.JobStateFailed, database.JobStateCancelled}
+
+// subscriptionMaxFailedJobs is the number of failed jobs after which polls stop queuing a video again.
+const subscriptionMaxFailedJobs = 3
+
+// AddSubscription subscribes to a channel or playlist URL. Every poll checks the given number of newest videos
+// of the URL, DefaultSubscriptionLatest if latest is not positive. The subscription is polled right away
+// and afterwards on the configured interval.
+func (cs *CoreService) AddSubscription(url string, latest int) (*database.Subscription, error) {
+	url = strings.TrimSpace(url)
+	if latest <= 0 {
+		latest = DefaultSubscriptionLatest
+	}
+	audioDownloader, err := cs.downloaders.GetVideoDownloader(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionNotSupported, url)
+	}
+	// a single video would be polled forever without ever listing anything new
+	if !audioDownloader.IsVideoList(url) {
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionNotAList, url)
+	}
+
+	subscriptions, err := cs.databaseService.GetAllSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription.URL == url {
+			return nil, fmt.Errorf("%w: %s", ErrSubscriptionExists, url)
+		}
+	}
+
+	subscription := database.NewSubscription(url, latest)
+	if err := cs.databaseService.InsertSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to persist subscription for %s: %w", url, err)
+	}
+	slog.Info("added subscription", "subscriptionID", subscription.ID, "url", url)
+	cs.wakeSubscriptionPoller()
+	return subscription, nil
+}
+
+// GetSubscriptions returns all subscriptions, oldest first.
+func (cs *CoreService) GetSubscriptions() ([]*database.Subscription, error) {
+	return cs.databaseService.GetAllSubscriptions()
+}
+
+// DeleteSubscription stops polling the given subscription. Videos it already queued are not cancelled.
+func (cs *CoreService) DeleteSubscription(id string) error {
+	if _, err := cs.databaseService.GetSubscriptionByID(id); err != nil {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+	if err := cs.databaseService.DeleteSubscription(id); err != nil {
+		return fmt.Errorf("failed to delete subscription %s: %w", id, err)
+	}
+	slog.Info("deleted subscription", "subscriptionID", id)
+	return nil
+}
+
+// StartSubscriptionPoller polls every subscription once per pollInterval in the background until ctx is cancelled.
+func (cs *CoreService) StartSubscriptionPoller(ctx context.Context, pollInterval time.Duration) {
+	if pollInterval <= 0 {
+		pollInterval = config.DefaultSubscriptionPollInterval
+	}
+	go cs.runSubscriptionPoller(ctx, pollInterval)
+}
+
+func (cs *CoreService) wakeSubscriptionPoller() {
+	select {
+	case cs.subscriptionWakeup <- struct{}{}:
+	default:
+		// a wake-up is already pending
+	}
+}
+
+func (cs *CoreService) runSubscriptionPoller(ctx context.Context, pollInterval time.Duration) {
+	for {
+		cs.pollDueSubscriptions(ctx, pollInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-cs.subscriptionWakeup:
+		case <-time.After(subscriptionCheckInterval):
+		}
+	}
+}
+
+// pollDueSubscriptions polls the subscriptions that were not checked within the last pollInterval, one at a time.
+func (cs *CoreService) pollDueSubscriptions(ctx context.Context, pollInterval time.Duration) {
+	subscriptions, err := cs.databaseService.GetAllSubscriptions()
+	if err != nil {
+		slog.Error("failed to load subscriptions", "err", err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(subscription.LastCheckedAt) < pollInterval {
+			continue
+		}
+		cs.pollSubscription(ctx, subscription)
+	}
+}
+
+// pollSubscription queues the new videos of the subscription and records the outcome of the poll.
+func (cs *CoreService) pollSubscription(ctx context.Context, subscription *database.Subscription) {
+	audioDownloader, err := cs.downloaders.GetVideoDownloader(subscription.URL)
+	if err == nil {
+		_, err = cs.downloadNewVideos(ctx, audioDownloader, subscription)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	subscription.LastError = ""
+	if err != nil {
+		slog.Error("failed to poll subscription", "subscriptionID", subscription.ID, "url", subscription.URL, "err", err)
+		subscription.LastError = err.Error()
+	}
+	now := time.Now().UTC()
+	subscription.LastCheckedAt = now
+	subscription.UpdatedAt = now
+	if err := cs.databaseService.UpdateSubscription(subscription); err != nil {
+		// the subscription may have been deleted while it was polled
+		slog.Warn("failed to record subscription poll", "subscriptionID", subscription.ID, "err", err)
+	}
+}
+
+// downloadNewVideos schedules the downloads of the videos listed for the subscription that are neither in the library
+// nor recorded as a download job. Videos whose jobs failed or were cancelled are queued again, unless a job failed
+// permanently or the video failed subscriptionMaxFailedJobs times.
+// Unavailable videos do not fail the poll even if partial downloads are not allowed, they are retried by the next one.
+func (cs *CoreService) downloadNewVideos(ctx context.Context, audioDownloader downloader.AudioDownloader, subscription *database.Subscription) ([]*database.DownloadJob, error) {
+	url := subscription.URL
+	urls, err := audioDownloader.ListIndividualVideoURLs(ctx, url, downloader.Selection{Latest: subscription.Latest})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list urls for %s: %w", url, err)
+	}
+
+	videoURLs := make([]string, 0, len(urls))
+	for _, entryURL := range urls {
+		videoURLs = append(videoURLs, audioDownloader.NormalizeVideoURL(entryURL))
+	}
+	knownJobs, err := cs.databaseService.GetDownloadJobsByURL(videoURLs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load download jobs: %w", err)
+	}
+	knownURLs := make(map[string]bool, len(knownJobs))
+	failedJobCounts := make(map[string]int)
+	for _, job := range knownJobs {
+		if !slices.Contains(subscriptionRetryStates, job.State) || failedPermanently(job) {
+			knownURLs[job.URL] = true
+		}
+		if job.State == database.JobStateFailed {
+			failedJobCounts[job.URL]++
+		}
+	}
+	for videoURL, count := range failedJobCounts {
+		if count >= subscriptionMaxFailedJobs {
+			knownURLs[videoURL] = true
+		}
+	}
+
+	newURLs := make([]string, 0)
+	for i, entryURL := range urls {
+		videoURL := videoURLs[i]
+		if knownURLs[videoURL] || cs.isInLibrary(videoURL) {
+			continue
+		}
+		// keep the listed URL, e.g. a Shorts link, for the content filters
+		newURLs = append(newURLs, entryURL)
+	}
+	if len(newURLs) == 0 {
+		slog.Debug("no new videos", "url", url, "entryCount", len(urls))
+		return []*database.DownloadJob{}, nil
+	}
+
+	slog.Info("found new videos", "url", url, "newCount", len(newURLs), "entryCount", len(urls))
+	return cs.scheduleDownloads(ctx, audioDownloader, url, newURLs, DownloadOptions{AcceptPartial: true})
+}
+
+// failedPermanently reports whether the job failed with an error that retrying cannot fix, e.g. a private video.
+func failedPermanently(job *database.DownloadJob) bool {
+	return job.State == database.JobStateFailed && job.PermanentFailure
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+// playlistDownloader is an AudioDownloader that lists a fixed set of available videos.
+type playlistDownloader struct {
+	downloader.AudioDownloader
+	urls []string
+}
+
+func (d *playlistDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	return d.urls, nil
+}
+
+func (d *playlistDownloader) NormalizeVideoURL(url string) string {
+	return url
+}
+
+func (d *playlistDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	return nil
+}
+
+func TestDownloadNewVideos_QueuesUnknownAndFailedVideos(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{}, nil, nil, nil, nil)
+	inLibrary := "https://www.youtube.com/watch?v=library"
+	db.Items[database.PodcastItemIDForVideoURL(inLibrary)] = &database.PodcastItem{VideoURL: inLibrary}
+	filteredBefore := database.NewDownloadJob("https://www.youtube.com/watch?v=filtered")
+	filteredBefore.State = database.JobStateFiltered
+	_ = db.InsertDownloadJob(filteredBefore)
+	failedBefore := database.NewDownloadJob("https://www.youtube.com/watch?v=failed")
+	failedBefore.State = database.JobStateFailed
+	_ = db.InsertDownloadJob(failedBefore)
+	newVideo := "https://www.youtube.com/watch?v=new"
+	subscription := database.NewSubscription("https://www.youtube.com/playlist?list=abc", 0)
+
+	jobs, err := cs.downloadNewVideos(context.Background(), &playlistDownloader{urls: []string{inLibrary, filteredBefore.URL, failedBefore.URL, newVideo}}, subscription)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].URL != failedBefore.URL || jobs[1].URL != newVideo {
+		t.Fatalf("expected jobs for %q and %q, got %+v", failedBefore.URL, newVideo, jobs)
+	}
+	for _, job := range jobs {
+		if job.State != database.JobStateQueued {
+			t.Errorf("expected state %q, got %q", database.JobStateQueued, job.State)
+		}
+	}
+
+	jobs, err = cs.downloadNewVideos(context.Background(), &playlistDownloader{urls: []string{failedBefore.URL, newVideo}}, subscription)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("expected no jobs on the second poll, got %d", len(jobs))
+	}
+}
+
+func TestDownloadNewVideos_SkipsPermanentlyAndRepeatedlyFailedVideos(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{}, nil, nil, nil, nil)
+	privateVideo := database.NewDownloadJob("https://www.youtube.com/watch?v=private")
+	privateVideo.State = database.JobStateFailed
+	privateVideo.LastError = "ERROR: Private video"
+	privateVideo.PermanentFailure = true
+	_ = db.InsertDownloadJob(privateVideo)
+	flakyVideo := "https://www.youtube.com/watch?v=flaky"
+	for range subscriptionMaxFailedJobs {
+		job := database.NewDownloadJob(flakyVideo)
+		job.State = database.JobStateFailed
+		_ = db.InsertDownloadJob(job)
+	}
+	subscription := database.NewSubscription("https://www.youtube.com/playlist?list=abc", 0)
+
+	jobs, err := cs.downloadNewVideos(context.Background(), &playlistDownloader{urls: []string{privateVideo.URL, flakyVideo}}, subscription)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("expected no jobs, got %+v", jobs)
+	}
+}
+
+// partiallyAvailableDownloader is a playlistDownloader whose videos are unavailable unless listed as available.
+type partiallyAvailableDownloader struct {
+	playlistDownloader
+	available map[string]bool
+	selection downloader.Selection
+}
+
+func (d *partiallyAvailableDownloader) ListIndividualVideoURLs(ctx context.Context, url string, selection downloader.Selection) ([]string, error) {
+	d.selection = selection
+	return d.urls, nil
+}
+
+func (d *partiallyAvailableDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	if !d.available[url] {
+		return errors.New("video unavailable")
+	}
+	return nil
+}
+
+func TestDownloadNewVideos_IgnoresPartialDownloadPolicy(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{AllowPartialDownloads: false}, nil, nil, nil, nil)
+	available := "https://www.youtube.com/watch?v=available"
+	unavailable := "https://www.youtube.com/watch?v=unavailable"
+	audioDownloader := &partiallyAvailableDownloader{
+		playlistDownloader: playlistDownloader{urls: []string{available, unavailable}},
+		available:          map[string]bool{available: true},
+	}
+
+	jobs, err := cs.downloadNewVideos(context.Background(), audioDownloader, database.NewSubscription("https://www.youtube.com/playlist?list=abc", 3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if audioDownloader.selection.Latest != 3 {
+		t.Errorf("expected the newest 3 videos to be listed, got selection %+v", audioDownloader.selection)
+	}
+	states := map[string]database.JobState{}
+	for _, job := range jobs {
+		states[job.URL] = job.State
+	}
+	if states[available] != database.JobStateQueued || states[unavailable] != database.JobStateFailed {
+		t.Errorf("expected the available video to be queued and the other to fail, got %v", states)
+	}
+}
+
+func TestAddSubscription(t *testing.T) {
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, &config.Media{}, nil, nil, nil, nil)
+	playlistURL := "https://www.youtube.com/playlist?list=abc"
+
+	subscription, err := cs.AddSubscription(playlistURL, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if subscription.URL != playlistURL {
+		t.Errorf("expected url %q, got %q", playlistURL, subscription.URL)
+	}
+	if subscription.Latest != DefaultSubscriptionLatest {
+		t.Errorf("expected latest %d, got %d", DefaultSubscriptionLatest, subscription.Latest)
+	}
+	if _, err := cs.AddSubscription(playlistURL, 0); !errors.Is(err, ErrSubscriptionExists) {
+		t.Errorf("expected ErrSubscriptionExists, got %v", err)
+	}
+	if _, err := cs.AddSubscription("https://example.com/channel", 0); !errors.Is(err, ErrSubscriptionNotSupported) {
+		t.Errorf("expected ErrSubscriptionNotSupported, got %v", err)
+	}
+	if _, err := cs.AddSubscription("https://www.youtube.com/watch?v=abc", 0); !errors.Is(err, ErrSubscriptionNotAList) {
+		t.Errorf("expected ErrSubscriptionNotAList, got %v", err)
+	}
+}
+
+func TestPollDueSubscriptions_SkipsRecentlyCheckedSubscriptions(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{}, nil, nil, nil, nil)
+	subscription := database.NewSubscription("https://www.youtube.com/playlist?list=abc", 0)
+	checkedAt := time.Now().UTC().Add(-time.Minute)
+	subscription.LastCheckedAt = checkedAt
+	_ = db.InsertSubscription(subscription)
+
+	cs.pollDueSubscriptions(context.Background(), time.Hour)
+
+	if !subscription.LastCheckedAt.Equal(checkedAt) {
+		t.Errorf("expected subscription not to be polled, last checked at %v", subscription.LastCheckedAt)
+	}
+}
+
+func TestDeleteSubscription_UnknownID_ReturnsErrSubscriptionNotFound(t *testing.T) {
+	cs := NewCoreService(database.NewMockDatabase(), "", nil, &config.Media{}, nil, nil, nil, nil)
+
+	if err := cs.DeleteSubscription("unknown"); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
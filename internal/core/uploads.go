@@ -0,0 +1,205 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/convertvideo"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
+)
+
+// DefaultUploadFeed is the feed uploaded files are added to if no feed is given.
+const DefaultUploadFeed = "uploads"
+
+// UploadLinkPrefix prefixes the video link stored for uploaded files. Uploads have no source URL,
+// the link identifies them by their feed and content so that uploading the same file to the same feed again
+// replaces the item, while uploading it to another feed adds a separate item.
+const UploadLinkPrefix = "upload:"
+
+// ErrUnsupportedUpload is returned for uploaded files that are neither audio nor video.
+var ErrUnsupportedUpload = errors.New("unsupported file type")
+
+// ErrUploadConversion is returned for uploaded files that could not be converted to audio or tagged,
+// e.g. because they are damaged.
+var ErrUploadConversion = errors.New("could not convert file")
+
+// uploadExtensions are the file extensions of accepted uploads; all but the configured audio format are converted to it.
+var uploadExtensions = map[string]bool{
+	".mp3":  true,
+	".m4a":  true,
+	".aac":  true,
+	".wav":  true,
+	".flac": true,
+	".ogg":  true,
+	".opus": true,
+	".mp4":  true,
+	".m4v":  true,
+	".mov":  true,
+	".mkv":  true,
+	".webm": true,
+	".avi":  true,
+}
+
+// UploadOptions describes where an uploaded file is added and how it is titled.
+type UploadOptions struct {
+	// Feed is the feed the file is added to, DefaultUploadFeed if empty.
+	Feed string
+	// Title of the item. Defaults to the title tag of the file or its file name.
+	Title string
+	// Description of the item. Defaults to the description or comment tag of the file.
+	Description string
+}
+
+// IsUploadSupported reports whether files with the given name are accepted as uploads.
+func IsUploadSupported(fileName string) bool {
+	return uploadExtensions[strings.ToLower(filepath.Ext(fileName))]
+}
+
+// UploadItem adds an uploaded audio or video file to a feed. Video files are converted to audio,
+// the result is tagged like a downloaded video, moved into the feed's directory and stored as podcast item.
+func (cs *CoreService) UploadItem(fileName string, content io.Reader, options UploadOptions) (*database.PodcastItem, error) {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if !uploadExtensions[extension] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedUpload, fileName)
+	}
+	feed := filemanagement.SanitizeFileName(options.Feed, DefaultUploadFeed)
+
+	tempPath, err := os.MkdirTemp(cs.tempPath(), "upload-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tempPath); err != nil {
+			slog.Warn("error removing temp directory", "err", err)
+		}
+	}()
+
+	uploadedFilePath := filepath.Join(tempPath, "upload"+extension)
+	contentHash, err := saveUpload(uploadedFilePath, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save upload %s: %w", fileName, err)
+	}
+
+	// the directory of the audio file is the feed it is added to
+	feedPath := filepath.Join(tempPath, feed)
+	if err := os.MkdirAll(feedPath, os.ModePerm); err != nil {
+		return nil, err
+	}
+	fileTitle := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	audioConfig := cs.audioConfig()
+	audioFormat := filemanagement.AudioFormatByName(audioConfig.Format)
+	audioFilePath := filepath.Join(feedPath, filemanagement.SanitizeFileName(fileTitle, "upload")+"_"+contentHash+audioFormat.Extension)
+	if extension == audioFormat.Extension {
+		err = os.Rename(uploadedFilePath, audioFilePath)
+	} else {
+		slog.Info("converting upload to audio", "fileName", fileName, "format", audioFormat.Name)
+		err = convertvideo.ConvertVideoToAudio(uploadedFilePath, audioFilePath, audioConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to convert upload %s: %w", ErrUploadConversion, fileName, err)
+	}
+
+	if err := setUploadMetadata(audioFilePath, fileTitle, uploadLink(feed, contentHash), options); err != nil {
+		return nil, fmt.Errorf("%w: failed to set metadata of upload %s: %w", ErrUploadConversion, fileName, err)
+	}
+
+	targetPath, err := filemanagement.MoveToTarget(audioFilePath, cs.audioSourceDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move upload %s: %w", fileName, err)
+	}
+
+	podcastItem, err := database.NewPodcastItem(targetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded audio %s: %w", targetPath, err)
+	}
+	if err := cs.databaseService.InsertReplacePodcastItem(podcastItem); err != nil {
+		return nil, fmt.Errorf("failed to store uploaded audio %s: %w", targetPath, err)
+	}
+	slog.Info("added upload", "fileName", fileName, "feed", feed, "podcastItemID", podcastItem.ID)
+	return podcastItem, nil
+}
+
+// uploadLink returns the video link of an upload with the given content hash in feed.
+func uploadLink(feed string, contentHash string) string {
+	return UploadLinkPrefix + feed + "/" + contentHash
+}
+
+// saveUpload writes content to filePath and returns a short hash of it.
+func saveUpload(filePath string, content io.Reader) (string, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("error closing uploaded file", "err", err)
+		}
+	}()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hasher), content); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)[:6]), nil
+}
+
+// setUploadMetadata writes the tags podcast items are created from. Tags of the file are kept unless options override them.
+func setUploadMetadata(audioFilePath string, fileTitle string, link string, options UploadOptions) error {
+	metadata, err := downloader.GetTags(audioFilePath)
+	if err != nil {
+		return err
+	}
+
+	if options.Title != "" {
+		metadata[downloader.Title] = options.Title
+	} else if metadata[downloader.Title] == "" {
+		metadata[downloader.Title] = fileTitle
+	}
+	if metadata[downloader.Artist] == "" {
+		metadata[downloader.Artist] = filemanagement.SanitizeFileName(options.Feed, DefaultUploadFeed)
+	}
+	description := options.Description
+	if description == "" {
+		description = metadata["description"]
+	}
+	if description == "" {
+		description = metadata["comment"]
+	}
+	metadata[downloader.PodcastDescriptionTag] = strings.ReplaceAll(description, "\n", "<br>")
+	// uploads have no thumbnail
+	metadata[downloader.ThumbnailUrlTag] = ""
+	if metadata["date"] == "" {
+		metadata["date"] = time.Now().UTC().Format("2006-01-02T15:04:05")
+	}
+	metadata[downloader.DateTag] = metadata["date"]
+	metadata[downloader.VideoDownloadLink] = link
+
+	return downloader.SetTags(audioFilePath, metadata)
+}
+
+// audioConfig returns the format uploads are stored in, mp3 with the encoder defaults if none is configured.
+func (cs *CoreService) audioConfig() *config.Audio {
+	if cs.mediaConfig == nil {
+		return &config.Audio{Format: config.AudioFormatMP3}
+	}
+	return &cs.mediaConfig.Audio
+}
+
+// tempPath returns the directory for intermediate files, the system default if none is configured.
+func (cs *CoreService) tempPath() string {
+	if cs.mediaConfig == nil {
+		return ""
+	}
+	return cs.mediaConfig.TempPath
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+)
+
+func TestIsUploadSupported(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     bool
+	}{
+		{fileName: "meeting.mp4", want: true},
+		{fileName: "Meeting.MKV", want: true},
+		{fileName: "recording.m4a", want: true},
+		{fileName: "episode.mp3", want: true},
+		{fileName: "notes.txt", want: false},
+		{fileName: "archive", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if got := IsUploadSupported(tt.fileName); got != tt.want {
+				t.Errorf("IsUploadSupported(%q) = %v, want %v", tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadItem_UnsupportedFile_ReturnsError(t *testing.T) {
+	audioDirectory := t.TempDir()
+	cs := NewCoreService(database.NewMockDatabase(), audioDirectory, nil, &config.Media{TempPath: t.TempDir()}, nil, nil, nil, nil)
+
+	_, err := cs.UploadItem("notes.txt", strings.NewReader("text"), UploadOptions{Feed: "meetings"})
+	if !errors.Is(err, ErrUnsupportedUpload) {
+		t.Fatalf("expected ErrUnsupportedUpload, got %v", err)
+	}
+	if entries, _ := os.ReadDir(audioDirectory); len(entries) != 0 {
+		t.Errorf("expected no files in the audio directory, got %d", len(entries))
+	}
+}
+
+func TestUploadItem_SameContentInTwoFeeds_AddsTwoItems(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("skipping test, ffmpeg is required to tag the audio file")
+	}
+	content, err := os.ReadFile(filepath.Join("..", "..", "test_assets", "audio11.mp3"))
+	if err != nil {
+		t.Fatalf("failed to read test audio: %v", err)
+	}
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, t.TempDir(), nil, &config.Media{TempPath: t.TempDir()}, nil, nil, nil, nil)
+
+	first, err := cs.UploadItem("episode.mp3", bytes.NewReader(content), UploadOptions{Feed: "first"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := cs.UploadItem("episode.mp3", bytes.NewReader(content), UploadOptions{Feed: "second"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.ID == second.ID {
+		t.Fatalf("expected separate items per feed, got id %q twice", first.ID)
+	}
+	for _, item := range []*database.PodcastItem{first, second} {
+		if stored, _ := db.GetPodcastItemByID(item.ID); stored == nil {
+			t.Errorf("expected item %q to be stored", item.ID)
+		}
+		if _, err := os.Stat(item.AudioFilePath); err != nil {
+			t.Errorf("expected audio file %q: %v", item.AudioFilePath, err)
+		}
+	}
+}
+
+func TestUploadLink_DependsOnFeedAndContent(t *testing.T) {
+	link := uploadLink("first", "abc")
+	if link == uploadLink("second", "abc") {
+		t.Errorf("expected the same content in another feed to get another link, got %q", link)
+	}
+	if link == uploadLink("first", "def") {
+		t.Errorf("expected other content in the same feed to get another link, got %q", link)
+	}
+	if database.PodcastItemIDForVideoURL(link) != database.PodcastItemIDForVideoURL(uploadLink("first", "abc")) {
+		t.Error("expected uploading the same content to the same feed to keep the item id")
+	}
+}
+
+func TestSaveUpload_HashesContent(t *testing.T) {
+	directory := t.TempDir()
+	first, err := saveUpload(filepath.Join(directory, "first.mp3"), strings.NewReader("audio"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := saveUpload(filepath.Join(directory, "second.mp3"), strings.NewReader("audio"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := saveUpload(filepath.Join(directory, "other.mp3"), strings.NewReader("other audio"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected equal content to have the same hash, got %q and %q", first, second)
+	}
+	if first == other {
+		t.Errorf("expected different content to have different hashes, got %q", first)
+	}
+	if content, err := os.ReadFile(filepath.Join(directory, "first.mp3")); err != nil || string(content) != "audio" {
+		t.Errorf("expected content to be written, got %q (%v)", content, err)
+	}
+}
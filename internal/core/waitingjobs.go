@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/webhook"
+)
+
+// ErrLiveWaitExceeded is recorded on waiting jobs whose stream did not end within the configured maximum wait.
+var ErrLiveWaitExceeded = errors.New("live stream or premiere did not become available in time")
+
+// isLiveOrUpcoming reports whether an availability check failed only because the video is still streaming
+// or has not started yet, i.e. whether its recording can be downloaded later.
+func isLiveOrUpcoming(err error) bool {
+	return errors.Is(err, downloader.ErrVideoLive) || errors.Is(err, downloader.ErrVideoUpcoming)
+}
+
+// checkWaitingJobs starts an availability check for every waiting job whose next check is due.
+// The next check is scheduled before the check runs, so a job is never checked twice at the same time.
+func (cs *CoreService) checkWaitingJobs(ctx context.Context) {
+	jobs, err := cs.databaseService.GetDownloadJobsByState(database.JobStateWaiting)
+	if err != nil {
+		slog.Error("failed to load waiting download jobs", "err", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, job := range jobs {
+		if job.NextCheckAt.After(now) {
+			continue
+		}
+
+		// register the job before rescheduling it so that a cancellation in between is not lost
+		jobCtx := cs.registerJob(ctx, job.ID)
+		job.NextCheckAt = now.Add(cs.livePollInterval())
+		if !cs.transitionJob(job, database.JobStateWaiting, database.JobStateWaiting) {
+			cs.unregisterJob(job.ID)
+			continue
+		}
+		go func(job *database.DownloadJob) {
+			defer cs.unregisterJob(job.ID)
+			audioDownloader, err := cs.downloaders.GetVideoDownloader(job.URL)
+			if err != nil {
+				slog.Error("no downloader for waiting job", "jobID", job.ID, "url", job.URL, "err", err)
+				if cs.failJob(job, err) {
+					cs.notifyJob(webhook.EventDownloadUnavailable, job, err)
+				}
+				return
+			}
+			cs.checkWaitingJob(jobCtx, job, audioDownloader)
+		}(job)
+	}
+}
+
+// checkWaitingJob queues the job once its recording is available. Jobs that are still live or upcoming
+// stay waiting for their next check unless they waited longer than the maximum wait, jobs whose video became
+// unavailable fail.
+func (cs *CoreService) checkWaitingJob(ctx context.Context, job *database.DownloadJob, audioDownloader downloader.AudioDownloader) {
+	err := cs.runWithSlot(ctx, cs.availabilityCheckSlots, func() error {
+		return audioDownloader.CheckVideoAvailability(ctx, job.URL)
+	})
+	switch {
+	case ctx.Err() != nil:
+		cs.stopJob(ctx, job)
+	case isLiveOrUpcoming(err) && time.Since(job.CreatedAt) >= cs.liveMaxWait():
+		slog.Warn("giving up on waiting video", "jobID", job.ID, "url", job.URL, "waitingSince", job.CreatedAt, "err", err)
+		err = fmt.Errorf("%w after %s: %w", ErrLiveWaitExceeded, cs.liveMaxWait(), err)
+		if cs.failJob(job, err) {
+			cs.notifyJob(webhook.EventDownloadUnavailable, job, err)
+		}
+	case isLiveOrUpcoming(err):
+		slog.Info("video is still live or upcoming", "jobID", job.ID, "url", job.URL, "nextCheckAt", job.NextCheckAt)
+	case err != nil:
+		slog.Error("waiting video is not available anymore", "jobID", job.ID, "url", job.URL, "err", err)
+		if cs.failJob(job, err) {
+			cs.notifyJob(webhook.EventDownloadUnavailable, job, err)
+		}
+	default:
+		slog.Info("recording is available, queueing download", "jobID", job.ID, "url", job.URL)
+		job.NextCheckAt = time.Time{}
+		if cs.transitionJob(job, database.JobStateWaiting, database.JobStateQueued) {
+			cs.wakeDownloadQueue()
+		}
+	}
+}
+
+func (cs *CoreService) livePollInterval() time.Duration {
+	if cs.mediaConfig == nil || cs.mediaConfig.LivePollInterval <= 0 {
+		return config.DefaultLivePollInterval
+	}
+	return cs.mediaConfig.LivePollInterval
+}
+
+func (cs *CoreService) liveMaxWait() time.Duration {
+	if cs.mediaConfig == nil || cs.mediaConfig.LiveMaxWait <= 0 {
+		return config.DefaultLiveMaxWait
+	}
+	return cs.mediaConfig.LiveMaxWait
+}
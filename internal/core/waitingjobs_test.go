@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
+)
+
+// availabilityDownloader is an AudioDownloader whose availability check returns a fixed error.
+type availabilityDownloader struct {
+	downloader.AudioDownloader
+	err error
+}
+
+func (d *availabilityDownloader) CheckVideoAvailability(ctx context.Context, url string) error {
+	return d.err
+}
+
+func TestCheckWaitingJob(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkErr  error
+		wantState database.JobState
+	}{
+		{name: "recording available", checkErr: nil, wantState: database.JobStateQueued},
+		{name: "still live", checkErr: downloader.ErrVideoLive, wantState: database.JobStateWaiting},
+		{name: "still upcoming", checkErr: downloader.ErrVideoUpcoming, wantState: database.JobStateWaiting},
+		{name: "video removed", checkErr: errors.New("video unavailable"), wantState: database.JobStateFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewMockDatabase()
+			cs := NewCoreService(db, "", nil, &config.Media{LivePollInterval: time.Minute}, nil, nil, nil, nil)
+			job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+			job.State = database.JobStateWaiting
+			_ = db.InsertDownloadJob(job)
+
+			cs.checkWaitingJob(context.Background(), job, &availabilityDownloader{err: tt.checkErr})
+
+			if job.State != tt.wantState {
+				t.Errorf("expected state %q, got %q", tt.wantState, job.State)
+			}
+		})
+	}
+}
+
+func TestCheckWaitingJob_FailsAfterMaxWait(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{LivePollInterval: time.Minute, LiveMaxWait: time.Hour}, nil, nil, nil, nil)
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	job.State = database.JobStateWaiting
+	job.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+	_ = db.InsertDownloadJob(job)
+
+	cs.checkWaitingJob(context.Background(), job, &availabilityDownloader{err: downloader.ErrVideoUpcoming})
+
+	if job.State != database.JobStateFailed {
+		t.Errorf("expected state %q, got %q", database.JobStateFailed, job.State)
+	}
+	if !strings.Contains(job.LastError, ErrLiveWaitExceeded.Error()) {
+		t.Errorf("expected the exceeded wait as error, got %q", job.LastError)
+	}
+}
+
+func TestCheckWaitingJobs_OnlyDueJobsAreRescheduled(t *testing.T) {
+	db := database.NewMockDatabase()
+	cs := NewCoreService(db, "", nil, &config.Media{LivePollInterval: time.Hour}, nil, nil, nil, nil)
+	later := time.Now().UTC().Add(30 * time.Minute)
+	notDue := database.NewDownloadJob("https://www.youtube.com/watch?v=later")
+	notDue.State = database.JobStateWaiting
+	notDue.NextCheckAt = later
+	_ = db.InsertDownloadJob(notDue)
+	cancelled := database.NewDownloadJob("https://www.youtube.com/watch?v=cancelled")
+	cancelled.State = database.JobStateCancelled
+	_ = db.InsertDownloadJob(cancelled)
+
+	cs.checkWaitingJobs(context.Background())
+
+	if !notDue.NextCheckAt.Equal(later) {
+		t.Errorf("expected job that is not due to keep its next check at %v, got %v", later, notDue.NextCheckAt)
+	}
+	if cancelled.State != database.JobStateCancelled {
+		t.Errorf("expected cancelled job to stay cancelled, got %q", cancelled.State)
+	}
+}
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256=".
+	SignatureHeader = "X-Webhook-Signature"
+	// EventHeader carries the event type of the payload.
+	EventHeader = "X-Webhook-Event"
+
+	maxDeliveryAttempts = 3
+	retryDelay          = 2 * time.Second
+)
+
+// EventType names a download lifecycle event. The names are listed in config.WebhookEvents as well.
+type EventType string
+
+const (
+	EventDownloadAccepted    EventType = "download.accepted"    // the video was queued or is waiting for its recording
+	EventDownloadCompleted   EventType = "download.completed"   // the video was added to the library
+	EventDownloadUnavailable EventType = "download.unavailable" // the video was skipped because it cannot be downloaded
+	EventDownloadFailed      EventType = "download.failed"      // the download gave up after all retries
+)
+
+// Event is the JSON payload sent to the webhook endpoints.
+type Event struct {
+	Type        EventType             `json:"type"`
+	Timestamp   time.Time             `json:"timestamp"`
+	Job         *database.DownloadJob `json:"job"`
+	PodcastItem *database.PodcastItem `json:"podcast_item,omitempty"`
+	FeedURL     string                `json:"feed_url,omitempty"`
+	AudioURL    string                `json:"audio_url,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// Notifier delivers events to the configured webhook endpoints.
+type Notifier struct {
+	endpoints []config.WebhookEndpoint
+	client    *http.Client
+	ctx       context.Context // ends pending deliveries once the notifier is closed
+	cancel    context.CancelFunc
+}
+
+// NewNotifier creates a notifier for the given configuration. A nil configuration yields a notifier without endpoints.
+func NewNotifier(webhooksConfig *config.Webhooks) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	notifier := &Notifier{
+		client: &http.Client{Timeout: config.DefaultWebhookTimeout},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if webhooksConfig == nil {
+		return notifier
+	}
+	notifier.endpoints = webhooksConfig.Endpoints
+	if webhooksConfig.Timeout > 0 {
+		notifier.client.Timeout = webhooksConfig.Timeout
+	}
+	return notifier
+}
+
+// Notify sends the event to all endpoints subscribed to its type in the background.
+// The payload is encoded right away, so the caller may keep modifying the job afterwards.
+func (n *Notifier) Notify(event Event) {
+	if len(n.endpoints) == 0 || n.ctx.Err() != nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to encode webhook event", "event", event.Type, "err", err)
+		return
+	}
+	for _, endpoint := range n.endpoints {
+		if !subscribes(endpoint, event.Type) {
+			continue
+		}
+		go n.deliver(n.ctx, endpoint, event.Type, body)
+	}
+}
+
+// Close stops pending deliveries and their retries, e.g. when the service shuts down. Later events are dropped.
+func (n *Notifier) Close() {
+	n.cancel()
+}
+
+// deliver posts the payload to the endpoint and retries a few times if the endpoint is not reachable
+// or does not answer with a 2xx status. It gives up as soon as ctx is cancelled.
+func (n *Notifier) deliver(ctx context.Context, endpoint config.WebhookEndpoint, eventType EventType, body []byte) {
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		err := n.send(ctx, endpoint, eventType, body)
+		if err == nil {
+			return
+		}
+		slog.Warn("failed to deliver webhook", "url", endpoint.URL, "event", eventType, "attempt", attempt, "err", err)
+		if attempt < maxDeliveryAttempts {
+			select {
+			case <-ctx.Done():
+				slog.Warn("stopping webhook delivery", "url", endpoint.URL, "event", eventType, "attempt", attempt)
+				return
+			case <-time.After(retryDelay * time.Duration(attempt)):
+			}
+		}
+	}
+	slog.Error("giving up on webhook delivery", "url", endpoint.URL, "event", eventType, "attempts", maxDeliveryAttempts)
+}
+
+func (n *Notifier) send(ctx context.Context, endpoint config.WebhookEndpoint, eventType EventType, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of body for the given secret.
+// Receivers verify a payload by computing the same value and comparing it in constant time.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscribes(endpoint config.WebhookEndpoint, eventType EventType) bool {
+	return len(endpoint.Events) == 0 || slices.Contains(endpoint.Events, string(eventType))
+}
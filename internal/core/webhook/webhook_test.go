@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T) (*httptest.Server, chan receivedRequest) {
+	received := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{header: r.Header.Clone(), body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestNotify_SendsSignedPayload(t *testing.T) {
+	server, received := newReceiver(t)
+	notifier := NewNotifier(&config.Webhooks{Endpoints: []config.WebhookEndpoint{{URL: server.URL, Secret: "secret"}}})
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+
+	notifier.Notify(Event{Type: EventDownloadFailed, Job: job, Error: "download failed"})
+
+	select {
+	case request := <-received:
+		if got := request.header.Get(SignatureHeader); got != Sign("secret", request.body) {
+			t.Errorf("unexpected signature %q", got)
+		}
+		if got := request.header.Get(EventHeader); got != string(EventDownloadFailed) {
+			t.Errorf("expected event header %q, got %q", EventDownloadFailed, got)
+		}
+		var event Event
+		if err := json.Unmarshal(request.body, &event); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if event.Job == nil || event.Job.ID != job.ID || event.Error != "download failed" || event.Timestamp.IsZero() {
+			t.Errorf("unexpected payload: %s", request.body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestNotify_OnlySendsSubscribedEvents(t *testing.T) {
+	server, received := newReceiver(t)
+	notifier := NewNotifier(&config.Webhooks{Endpoints: []config.WebhookEndpoint{{URL: server.URL, Events: []string{string(EventDownloadCompleted)}}}})
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+
+	notifier.Notify(Event{Type: EventDownloadAccepted, Job: job})
+	notifier.Notify(Event{Type: EventDownloadCompleted, Job: job})
+
+	select {
+	case request := <-received:
+		if got := request.header.Get(EventHeader); got != string(EventDownloadCompleted) {
+			t.Errorf("expected only %q to be delivered, got %q", EventDownloadCompleted, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestDeliver_StopsRetryingWhenCancelled(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	notifier := NewNotifier(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	notifier.deliver(ctx, config.WebhookEndpoint{URL: server.URL, Secret: "secret"}, EventDownloadFailed, []byte("{}"))
+
+	if elapsed := time.Since(start); elapsed >= retryDelay {
+		t.Errorf("expected delivery to stop without waiting for a retry, took %s", elapsed)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("expected no request after cancellation, got %d", got)
+	}
+}
+
+func TestNotify_AfterClose_DropsEvents(t *testing.T) {
+	server, received := newReceiver(t)
+	notifier := NewNotifier(&config.Webhooks{Endpoints: []config.WebhookEndpoint{{URL: server.URL, Secret: "secret"}}})
+
+	notifier.Close()
+	notifier.Notify(Event{Type: EventDownloadFailed, Job: database.NewDownloadJob("https://www.youtube.com/watch?v=abc")})
+
+	select {
+	case <-received:
+		t.Error("did not expect a delivery after close")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEventTypes_MatchConfig(t *testing.T) {
+	eventTypes := []string{string(EventDownloadAccepted), string(EventDownloadCompleted), string(EventDownloadUnavailable), string(EventDownloadFailed)}
+	if !reflect.DeepEqual(eventTypes, config.WebhookEvents) {
+		t.Errorf("event types %v, want config.WebhookEvents %v", eventTypes, config.WebhookEvents)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// reference value computed with: printf '{}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13"
+	if got := Sign("secret", []byte("{}")); got != want {
+		t.Errorf("expected signature %q, got %q", want, got)
+	}
+}
@@ -9,20 +9,31 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/jo-hoe/gofeedx"
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/feed"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/filemanagement"
 	"github.com/jo-hoe/video-to-podcast-service/internal/server/requestutil"
 	"github.com/labstack/echo/v4"
 )
 
 const (
-	apiVersion   = "v1/"
-	addItemPaths = apiVersion + "addItems"
-
-	FeedsPath = apiVersion + "feeds"
+	apiVersion        = "v1/"
+	addItemPaths      = apiVersion + "addItems"
+	jobsPath          = apiVersion + "jobs"
+	downloadsPath     = apiVersion + "downloads"
+	subscriptionsPath = apiVersion + "subscriptions"
+	previewPath       = apiVersion + "preview"
+	uploadsPath       = apiVersion + "uploads"
+	itemsPath         = apiVersion + "items"
+
+	FeedsPath = core.FeedsPath
 )
 
 type APIService struct {
@@ -34,8 +45,84 @@ type DownloadItem struct {
 	URL string `json:"url" validate:"required"`
 }
 
+type PreviewRequest struct {
+	URL string `json:"url" validate:"required"`
+	ItemSelection
+}
+
+type SubscriptionRequest struct {
+	URL    string `json:"url" validate:"required"` // channel or playlist URL
+	Latest int    `json:"latest" validate:"gte=0"` // number of newest videos checked on every poll, core.DefaultSubscriptionLatest if 0
+}
+
 type DownloadItems struct {
-	URLS []string `json:"urls" validate:"required"`
+	URLS  []string `json:"urls" validate:"required"`
+	Force bool     `json:"force"` // download videos again even if they are already in the library
+	ItemSelection
+	// content filters replacing the configured ones, see config.Filters
+	Filters *ContentFilters `json:"filters,omitempty"`
+}
+
+// DownloadItemsError is the response body of a failed addItems request. It lists the jobs created before the
+// request failed, so that they can be tracked or cancelled.
+type DownloadItemsError struct {
+	Message string                  `json:"message"`
+	URL     string                  `json:"url"` // URL that failed
+	Jobs    []*database.DownloadJob `json:"jobs"`
+}
+
+// ItemSelection selects entries of playlists and channels, see downloader.Selection
+type ItemSelection struct {
+	Items         string `json:"items,omitempty"`          // 1-based index ranges, e.g. "1-10,15"
+	Latest        int    `json:"latest,omitempty"`         // only the newest N entries
+	UploadedAfter string `json:"uploaded_after,omitempty"` // only entries uploaded after this date (YYYY-MM-DD)
+}
+
+type ContentFilters struct {
+	MinDuration   string `json:"min_duration,omitempty"` // Go duration, e.g. "5m"
+	MaxDuration   string `json:"max_duration,omitempty"` // Go duration, e.g. "3h"
+	IncludeTitle  string `json:"include_title,omitempty"`
+	ExcludeTitle  string `json:"exclude_title,omitempty"`
+	ExcludeShorts bool   `json:"exclude_shorts,omitempty"`
+}
+
+// config returns the validated filters of the request.
+func (filters *ContentFilters) config() (*config.Filters, error) {
+	result := &config.Filters{
+		IncludeTitle:  filters.IncludeTitle,
+		ExcludeTitle:  filters.ExcludeTitle,
+		ExcludeShorts: filters.ExcludeShorts,
+	}
+	for _, duration := range []struct {
+		value  string
+		target *time.Duration
+	}{{filters.MinDuration, &result.MinDuration}, {filters.MaxDuration, &result.MaxDuration}} {
+		if duration.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q, expected e.g. 5m or 1h30m", duration.value)
+		}
+		*duration.target = parsed
+	}
+	return result, result.Validate()
+}
+
+// selection returns the validated playlist selection of the request.
+func (items *ItemSelection) selection() (downloader.Selection, error) {
+	selection := downloader.Selection{
+		Items:  strings.TrimSpace(items.Items),
+		Latest: items.Latest,
+	}
+	if items.UploadedAfter != "" {
+		uploadedAfter, err := time.Parse(time.DateOnly, items.UploadedAfter)
+		if err != nil {
+			return selection, fmt.Errorf("invalid upload date %q, expected YYYY-MM-DD", items.UploadedAfter)
+		}
+		selection.UploadedAfter = uploadedAfter
+	}
+	return selection, selection.Validate()
 }
 
 func NewAPIService(coreservice core.Service, defaultPort string) *APIService {
@@ -48,9 +135,19 @@ func NewAPIService(coreservice core.Service, defaultPort string) *APIService {
 func (service *APIService) SetAPIRoutes(e *echo.Echo) {
 	// API routes
 	e.POST(addItemPaths, service.addItemsHandler)
+	e.POST(previewPath, service.previewHandler)
+	e.POST(uploadsPath, service.uploadHandler)
+	e.GET(jobsPath, service.jobsHandler)
+	e.GET(fmt.Sprintf("%s%s", jobsPath, "/:jobID"), service.jobHandler)
+	e.DELETE(fmt.Sprintf("%s%s", downloadsPath, "/:downloadID"), service.cancelDownloadHandler)
+	e.POST(subscriptionsPath, service.addSubscriptionHandler)
+	e.GET(subscriptionsPath, service.subscriptionsHandler)
+	e.DELETE(fmt.Sprintf("%s%s", subscriptionsPath, "/:subscriptionID"), service.deleteSubscriptionHandler)
+	e.GET(fmt.Sprintf("%s%s", itemsPath, "/:podcastItemID"), service.itemHandler)
 	e.GET(FeedsPath, service.feedsHandler)
 	e.GET(fmt.Sprintf("%s%s", FeedsPath, "/:feedTitle/rss.xml"), service.feedHandler)
 	e.GET(fmt.Sprintf("%s%s", FeedsPath, "/:feedTitle/:audioFileName"), service.audioFileHandler)
+	e.GET(fmt.Sprintf("%s/:feedTitle/:podcastItemID/%s", FeedsPath, core.ChaptersFileName), service.chaptersHandler)
 	e.DELETE(fmt.Sprintf("%s%s", FeedsPath, "/:feedTitle/:podcastItemID"), service.deleteFeedItem)
 
 	// Health endpoint for Kubernetes probes
@@ -91,6 +188,11 @@ func (service *APIService) validateItemPathComponents(podcastItemID string, feed
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid podcast item")
 	}
 
+	return service.validateItemFeed(podcastItem, feedTitle)
+}
+
+// validateItemFeed checks that the podcast item belongs to the feed with the given title.
+func (service *APIService) validateItemFeed(podcastItem *database.PodcastItem, feedTitle string) *echo.HTTPError {
 	// validate feedTitle
 	if feedTitle == "" {
 		slog.Warn("feedTitle is required for validation")
@@ -99,7 +201,7 @@ func (service *APIService) validateItemPathComponents(podcastItemID string, feed
 
 	feedDirectory, err := service.coreService.GetFeedDirectory(podcastItem.AudioFilePath)
 	if err != nil {
-		slog.Warn("feed item not found (feed directory error)", "podcastItemID", podcastItemID)
+		slog.Warn("feed item not found (feed directory error)", "podcastItemID", podcastItem.ID)
 		return echo.NewHTTPError(http.StatusNotFound, "feed item not found")
 	}
 
@@ -107,7 +209,7 @@ func (service *APIService) validateItemPathComponents(podcastItemID string, feed
 	normFeedTitle, _ := url.PathUnescape(feedTitle)
 	normFeedDirectory, _ := url.PathUnescape(feedDirectory)
 	if !equalPath(normFeedTitle, normFeedDirectory) {
-		slog.Warn("feed item not found (feed title mismatch)", "podcastItemID", podcastItemID)
+		slog.Warn("feed item not found (feed title mismatch)", "podcastItemID", podcastItem.ID)
 		return echo.NewHTTPError(http.StatusNotFound, "feed item not found")
 	}
 
@@ -140,19 +242,227 @@ func (service *APIService) addItemsHandler(ctx echo.Context) (err error) {
 		slog.Error("failed to validate download items", "err", err)
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request data")
 	}
+	selection, err := downloadItems.selection()
+	if err != nil {
+		slog.Error("invalid playlist selection", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var filters *config.Filters
+	if downloadItems.Filters != nil {
+		if filters, err = downloadItems.Filters.config(); err != nil {
+			slog.Error("invalid content filters", "err", err)
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
 
+	// reject the request before any job is created if one of the URLs is not supported
 	for _, url := range downloadItems.URLS {
-		err = service.coreService.DownloadItemsHandler(url)
+		if !service.coreService.IsURLSupported(url) {
+			slog.Error("unsupported url", "url", url)
+			return echo.NewHTTPError(http.StatusBadRequest, "unsupported URL: "+url)
+		}
+	}
+
+	jobs := make([]*database.DownloadJob, 0)
+	for _, url := range downloadItems.URLS {
+		options := core.DownloadOptions{Force: downloadItems.Force, Selection: selection, Filters: filters}
+		urlJobs, err := service.coreService.DownloadItemsHandler(ctx.Request().Context(), url, options)
+		jobs = append(jobs, urlJobs...)
 		if err != nil {
 			slog.Error("failed to handle download", "url", url, "err", err)
-			if errors.Is(err, downloader.ErrVideoLive) {
-				return echo.NewHTTPError(http.StatusConflict, "video is currently live")
-			}
+			code, message := downloadErrorResponse(err)
+			return echo.NewHTTPError(code, &DownloadItemsError{Message: message, URL: url, Jobs: jobs})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, jobs)
+}
+
+// downloadErrorResponse maps an error of the core download handler to a status code and message.
+func downloadErrorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, core.ErrDownloadCancelled):
+		return http.StatusConflict, "download was cancelled"
+	case errors.Is(err, core.ErrURLNotSupported):
+		return http.StatusBadRequest, "unsupported URL"
+	case errors.Is(err, core.ErrNoDownloadableVideos):
+		return http.StatusUnprocessableEntity, err.Error()
+	default:
+		return http.StatusInternalServerError, "failed to handle download"
+	}
+}
+
+func (service *APIService) previewHandler(ctx echo.Context) (err error) {
+	request := new(PreviewRequest)
+	if err = ctx.Bind(request); err != nil {
+		slog.Error("failed to bind preview request", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if err = ctx.Validate(request); err != nil {
+		slog.Error("failed to validate preview request", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request data")
+	}
+	selection, err := request.selection()
+	if err != nil {
+		slog.Error("invalid playlist selection", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	entries, err := service.coreService.PreviewItems(ctx.Request().Context(), request.URL, selection)
+	if err != nil {
+		slog.Error("failed to preview url", "url", request.URL, "err", err)
+		if errors.Is(err, core.ErrURLNotSupported) {
 			return echo.NewHTTPError(http.StatusBadRequest, "unsupported URL")
 		}
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
 	}
+	return ctx.JSON(http.StatusOK, entries)
+}
 
-	return ctx.NoContent(http.StatusOK)
+// uploadHandler adds an audio or video file sent as multipart form field "file" to a feed.
+// The optional form fields "feed", "title" and "description" are passed as core.UploadOptions.
+func (service *APIService) uploadHandler(ctx echo.Context) (err error) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		slog.Error("failed to read uploaded file", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	if !core.IsUploadSupported(fileHeader.Filename) {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported file type")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("failed to open uploaded file", "fileName", fileHeader.Filename, "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid file")
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("error closing uploaded file", "err", err)
+		}
+	}()
+
+	options := core.UploadOptions{
+		Feed:        ctx.FormValue("feed"),
+		Title:       ctx.FormValue("title"),
+		Description: ctx.FormValue("description"),
+	}
+	podcastItem, err := service.coreService.UploadItem(fileHeader.Filename, file, options)
+	if err != nil {
+		slog.Error("failed to add upload", "fileName", fileHeader.Filename, "err", err)
+		switch {
+		case errors.Is(err, core.ErrUnsupportedUpload):
+			return echo.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported file type")
+		case errors.Is(err, core.ErrUploadConversion):
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "could not convert file")
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to store file")
+		}
+	}
+	return ctx.JSON(http.StatusCreated, podcastItem)
+}
+
+func (service *APIService) jobsHandler(ctx echo.Context) (err error) {
+	jobs, err := service.coreService.GetDatabaseService().GetAllDownloadJobs()
+	if err != nil {
+		slog.Error("failed to retrieve download jobs", "err", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve download jobs")
+	}
+	return ctx.JSON(http.StatusOK, jobs)
+}
+
+func (service *APIService) jobHandler(ctx echo.Context) (err error) {
+	jobID := ctx.Param("jobID")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "jobID is required")
+	}
+	job, err := service.coreService.GetDatabaseService().GetDownloadJobByID(jobID)
+	if err != nil || job == nil {
+		slog.Warn("download job not found", "jobID", jobID, "err", err)
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+	return ctx.JSON(http.StatusOK, job)
+}
+
+// itemHandler returns a podcast item including the metadata reported by its source.
+func (service *APIService) itemHandler(ctx echo.Context) (err error) {
+	podcastItemID := ctx.Param("podcastItemID")
+	if podcastItemID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "podcastItemID is required")
+	}
+	item, err := service.coreService.GetDatabaseService().GetPodcastItemByID(podcastItemID)
+	if err != nil || item == nil {
+		slog.Warn("podcast item not found", "podcastItemID", podcastItemID, "err", err)
+		return echo.NewHTTPError(http.StatusNotFound, "item not found")
+	}
+	return ctx.JSON(http.StatusOK, item)
+}
+
+func (service *APIService) cancelDownloadHandler(ctx echo.Context) (err error) {
+	downloadID := ctx.Param("downloadID")
+	if downloadID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "downloadID is required")
+	}
+	jobs, err := service.coreService.CancelDownload(downloadID)
+	if err != nil {
+		if errors.Is(err, core.ErrDownloadNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "download not found")
+		}
+		slog.Error("failed to cancel download", "downloadID", downloadID, "err", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to cancel download")
+	}
+	return ctx.JSON(http.StatusOK, jobs)
+}
+
+func (service *APIService) addSubscriptionHandler(ctx echo.Context) (err error) {
+	request := new(SubscriptionRequest)
+	if err = ctx.Bind(request); err != nil {
+		slog.Error("failed to bind subscription", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if err = ctx.Validate(request); err != nil {
+		slog.Error("failed to validate subscription", "err", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request data")
+	}
+
+	subscription, err := service.coreService.AddSubscription(request.URL, request.Latest)
+	if err != nil {
+		slog.Error("failed to add subscription", "url", request.URL, "err", err)
+		if errors.Is(err, core.ErrSubscriptionExists) {
+			return echo.NewHTTPError(http.StatusConflict, "already subscribed")
+		}
+		if errors.Is(err, core.ErrSubscriptionNotSupported) {
+			return echo.NewHTTPError(http.StatusBadRequest, "unsupported URL")
+		}
+		if errors.Is(err, core.ErrSubscriptionNotAList) {
+			return echo.NewHTTPError(http.StatusBadRequest, "URL is not a channel, playlist or feed")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to add subscription")
+	}
+	return ctx.JSON(http.StatusCreated, subscription)
+}
+
+func (service *APIService) subscriptionsHandler(ctx echo.Context) (err error) {
+	subscriptions, err := service.coreService.GetSubscriptions()
+	if err != nil {
+		slog.Error("failed to retrieve subscriptions", "err", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve subscriptions")
+	}
+	return ctx.JSON(http.StatusOK, subscriptions)
+}
+
+func (service *APIService) deleteSubscriptionHandler(ctx echo.Context) (err error) {
+	subscriptionID := ctx.Param("subscriptionID")
+	if subscriptionID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "subscriptionID is required")
+	}
+	if err := service.coreService.DeleteSubscription(subscriptionID); err != nil {
+		if errors.Is(err, core.ErrSubscriptionNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "subscription not found")
+		}
+		slog.Error("failed to delete subscription", "subscriptionID", subscriptionID, "err", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete subscription")
+	}
+	return ctx.NoContent(http.StatusNoContent)
 }
 
 func (service *APIService) feedHandler(ctx echo.Context) (err error) {
@@ -177,6 +487,33 @@ func (service *APIService) feedHandler(ctx echo.Context) (err error) {
 	return err
 }
 
+// chaptersHandler serves the Podcasting 2.0 chapters document of a podcast item referenced from its feed.
+func (service *APIService) chaptersHandler(ctx echo.Context) (err error) {
+	podcastItemID := ctx.Param("podcastItemID")
+	feedTitle := ctx.Param("feedTitle")
+	if podcastItemID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "podcastItemID is required")
+	}
+
+	podcastItem, err := service.coreService.GetDatabaseService().GetPodcastItemByID(podcastItemID)
+	if errors.Is(err, database.ErrPodcastItemNotFound) || (err == nil && podcastItem == nil) {
+		return echo.NewHTTPError(http.StatusNotFound, "item not found")
+	}
+	if err != nil {
+		slog.Error("failed to get podcast item", "podcastItemID", podcastItemID, "err", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get podcast item")
+	}
+	if validationError := service.validateItemFeed(podcastItem, feedTitle); validationError != nil {
+		return validationError
+	}
+	if len(podcastItem.Chapters) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "item has no chapters")
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentType, chapters.MIMEType)
+	return ctx.JSON(http.StatusOK, chapters.NewDocument(podcastItem.Chapters))
+}
+
 func (service *APIService) audioFileHandler(ctx echo.Context) (err error) {
 	decodedFeedTitle, err := service.getPathAttributeValue(ctx, "feedTitle")
 	if err != nil {
@@ -210,6 +547,8 @@ func (service *APIService) audioFileHandler(ctx echo.Context) (err error) {
 		return echo.NewHTTPError(http.StatusNotFound, "audio file not found")
 	}
 
+	// the type cannot be derived from the extension of every audio format, e.g. opus
+	ctx.Response().Header().Set(echo.HeaderContentType, filemanagement.AudioMIMEType(expectedPath))
 	return ctx.File(expectedPath)
 }
 
@@ -262,4 +601,4 @@ func (service *APIService) probeHandler(ctx echo.Context) (err error) {
 
 func (service *APIService) getFeedService() *feed.FeedService {
 	return feed.NewFeedService(service.coreService, service.defaultPort, FeedsPath)
-}
\ No newline at end of file
+}
@@ -1,12 +1,22 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/chapters"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 	"github.com/labstack/echo/v4"
 )
@@ -176,7 +186,9 @@ func TestAddItemsHandler_DownloadSuccess_Returns200(t *testing.T) {
 	e := echo.New()
 	e.Validator = newRequestValidator()
 	mock := newMockService()
-	mock.DownloadItemsHandlerFunc = func(_ string) error { return nil }
+	mock.DownloadItemsHandlerFunc = func(url string, _ core.DownloadOptions) ([]*database.DownloadJob, error) {
+		return []*database.DownloadJob{database.NewDownloadJob(url)}, nil
+	}
 	svc := newTestAPIService(mock)
 	ctx, rec := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=abc"]}`)
 
@@ -186,68 +198,741 @@ func TestAddItemsHandler_DownloadSuccess_Returns200(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", rec.Code)
 	}
+	if !strings.Contains(rec.Body.String(), `"state":"queued"`) {
+		t.Errorf("expected accepted jobs in response body, got %s", rec.Body.String())
+	}
 }
 
-func TestAddItemsHandler_VideoIsLive_Returns409(t *testing.T) {
+func TestAddItemsHandler_ForceFlag_IsPassedToCore(t *testing.T) {
 	e := echo.New()
 	e.Validator = newRequestValidator()
 	mock := newMockService()
-	mock.DownloadItemsHandlerFunc = func(_ string) error {
-		return downloader.ErrVideoLive
+	var received core.DownloadOptions
+	mock.DownloadItemsHandlerFunc = func(_ string, options core.DownloadOptions) ([]*database.DownloadJob, error) {
+		received = options
+		return []*database.DownloadJob{}, nil
 	}
 	svc := newTestAPIService(mock)
-	ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=live123"]}`)
+	ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=abc"],"force":true}`)
 
-	err := svc.addItemsHandler(ctx)
-	if err == nil {
-		t.Fatal("expected error, got nil")
+	if err := svc.addItemsHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !received.Force {
+		t.Error("expected force flag to be passed to the core service")
+	}
+}
+
+func TestAddItemsHandler_Selection_IsPassedToCore(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	var received core.DownloadOptions
+	mock.DownloadItemsHandlerFunc = func(_ string, options core.DownloadOptions) ([]*database.DownloadJob, error) {
+		received = options
+		return []*database.DownloadJob{}, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/playlist?list=abc"],"items":"1-10","latest":5,"uploaded_after":"2024-03-01"}`)
+
+	if err := svc.addItemsHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := downloader.Selection{Items: "1-10", Latest: 5, UploadedAfter: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	if received.Selection != want {
+		t.Errorf("expected selection %+v, got %+v", want, received.Selection)
+	}
+}
+
+func TestAddItemsHandler_InvalidSelection_Returns400(t *testing.T) {
+	bodies := []string{
+		`{"urls":["https://www.youtube.com/playlist?list=abc"],"items":"1;2"}`,
+		`{"urls":["https://www.youtube.com/playlist?list=abc"],"latest":-1}`,
+		`{"urls":["https://www.youtube.com/playlist?list=abc"],"uploaded_after":"01.03.2024"}`,
+	}
+	for _, body := range bodies {
+		e := echo.New()
+		e.Validator = newRequestValidator()
+		mock := newMockService()
+		mock.DownloadItemsHandlerFunc = func(_ string, _ core.DownloadOptions) ([]*database.DownloadJob, error) {
+			t.Error("expected the core service not to be called")
+			return nil, nil
+		}
+		svc := newTestAPIService(mock)
+		ctx, _ := addItemsRequest(e, body)
+
+		err := svc.addItemsHandler(ctx)
+		he, ok := err.(*echo.HTTPError)
+		if !ok {
+			t.Fatalf("expected *echo.HTTPError for %s, got %T", body, err)
+		}
+		if he.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", body, he.Code)
+		}
+	}
+}
+
+func TestAddItemsHandler_Filters_ArePassedToCore(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	var received core.DownloadOptions
+	mock.DownloadItemsHandlerFunc = func(_ string, options core.DownloadOptions) ([]*database.DownloadJob, error) {
+		received = options
+		return []*database.DownloadJob{}, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/@channel"],"filters":{"min_duration":"10m","exclude_title":"(?i)trailer","exclude_shorts":true}}`)
+
+	if err := svc.addItemsHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := config.Filters{MinDuration: 10 * time.Minute, ExcludeTitle: "(?i)trailer", ExcludeShorts: true}
+	if received.Filters == nil || *received.Filters != want {
+		t.Errorf("expected filters %+v, got %+v", want, received.Filters)
 	}
+}
+
+func TestAddItemsHandler_InvalidFilters_Returns400(t *testing.T) {
+	bodies := []string{
+		`{"urls":["https://www.youtube.com/@channel"],"filters":{"min_duration":"ten minutes"}}`,
+		`{"urls":["https://www.youtube.com/@channel"],"filters":{"min_duration":"1h","max_duration":"10m"}}`,
+		`{"urls":["https://www.youtube.com/@channel"],"filters":{"include_title":"(unclosed"}}`,
+	}
+	for _, body := range bodies {
+		e := echo.New()
+		e.Validator = newRequestValidator()
+		svc := newTestAPIService(newMockService())
+		ctx, _ := addItemsRequest(e, body)
+
+		err := svc.addItemsHandler(ctx)
+		he, ok := err.(*echo.HTTPError)
+		if !ok {
+			t.Fatalf("expected *echo.HTTPError for %s, got %T", body, err)
+		}
+		if he.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", body, he.Code)
+		}
+	}
+}
+
+func TestAddItemsHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "unsupported url", err: fmt.Errorf("%w: https://unsupported.example.com/video", core.ErrURLNotSupported), wantCode: http.StatusBadRequest},
+		{name: "download cancelled", err: fmt.Errorf("%w: https://www.youtube.com/watch?v=abc", core.ErrDownloadCancelled), wantCode: http.StatusConflict},
+		{name: "no downloadable videos", err: fmt.Errorf("%w: no available videos", core.ErrNoDownloadableVideos), wantCode: http.StatusUnprocessableEntity},
+		{name: "database error", err: errors.New("failed to persist download job"), wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = newRequestValidator()
+			mock := newMockService()
+			mock.DownloadItemsHandlerFunc = func(_ string, _ core.DownloadOptions) ([]*database.DownloadJob, error) {
+				return nil, tt.err
+			}
+			svc := newTestAPIService(mock)
+			ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=abc"]}`)
+
+			err := svc.addItemsHandler(ctx)
+			he, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("expected *echo.HTTPError, got %T", err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, he.Code)
+			}
+		})
+	}
+}
+
+func TestAddItemsHandler_UnsupportedURL_CreatesNoJobs(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	mock.IsURLSupportedFunc = func(url string) bool {
+		return url != "https://unsupported.example.com/video"
+	}
+	mock.DownloadItemsHandlerFunc = func(_ string, _ core.DownloadOptions) ([]*database.DownloadJob, error) {
+		t.Error("expected the core service not to be called")
+		return nil, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=abc","https://unsupported.example.com/video"]}`)
+
+	err := svc.addItemsHandler(ctx)
 	he, ok := err.(*echo.HTTPError)
 	if !ok {
 		t.Fatalf("expected *echo.HTTPError, got %T", err)
 	}
-	if he.Code != http.StatusConflict {
-		t.Errorf("expected 409, got %d", he.Code)
+	if he.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", he.Code)
 	}
 }
 
-func TestAddItemsHandler_VideoIsLiveWrapped_Returns409(t *testing.T) {
+func TestAddItemsHandler_LaterFailure_ReturnsCreatedJobs(t *testing.T) {
 	e := echo.New()
 	e.Validator = newRequestValidator()
 	mock := newMockService()
-	mock.DownloadItemsHandlerFunc = func(_ string) error {
-		return errors.Join(downloader.ErrVideoLive, errors.New("extra context"))
+	first := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	failed := database.NewDownloadJob("https://www.youtube.com/watch?v=def")
+	failed.State = database.JobStateFailed
+	mock.DownloadItemsHandlerFunc = func(url string, _ core.DownloadOptions) ([]*database.DownloadJob, error) {
+		if url == first.URL {
+			return []*database.DownloadJob{first}, nil
+		}
+		return []*database.DownloadJob{failed}, fmt.Errorf("%w: no available videos for %s", core.ErrNoDownloadableVideos, url)
 	}
 	svc := newTestAPIService(mock)
-	ctx, _ := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=live456"]}`)
+	ctx, rec := addItemsRequest(e, `{"urls":["https://www.youtube.com/watch?v=abc","https://www.youtube.com/watch?v=def"]}`)
 
 	err := svc.addItemsHandler(ctx)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
+	e.HTTPErrorHandler(err, ctx)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+	var body DownloadItemsError
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response body %s: %v", rec.Body.String(), err)
+	}
+	if body.URL != failed.URL || len(body.Jobs) != 2 || body.Jobs[0].ID != first.ID || body.Jobs[1].ID != failed.ID {
+		t.Errorf("expected the jobs of both URLs in the error response, got %s", rec.Body.String())
+	}
+}
+
+// --- uploadHandler ---
+
+func uploadRequest(t *testing.T, e *echo.Echo, fileName string, fields map[string]string) (echo.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatalf("failed to write field: %v", err)
+		}
+	}
+	if fileName != "" {
+		part, err := writer.CreateFormFile("file", fileName)
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		if _, err := part.Write([]byte("media")); err != nil {
+			t.Fatalf("failed to write form file: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close multipart writer: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/"+uploadsPath, body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestUploadHandler_Success_Returns201(t *testing.T) {
+	mock := newMockService()
+	var gotFileName, gotContent string
+	var gotOptions core.UploadOptions
+	mock.UploadItemFunc = func(fileName string, content io.Reader, options core.UploadOptions) (*database.PodcastItem, error) {
+		data, _ := io.ReadAll(content)
+		gotFileName, gotContent, gotOptions = fileName, string(data), options
+		return &database.PodcastItem{ID: "item-id", Title: options.Title}, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, rec := uploadRequest(t, echo.New(), "meeting.mp4", map[string]string{"feed": "meetings", "title": "Weekly", "description": "notes"})
+
+	if err := svc.uploadHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d", rec.Code)
+	}
+	if gotFileName != "meeting.mp4" || gotContent != "media" {
+		t.Errorf("expected uploaded file to be passed to core, got %q with content %q", gotFileName, gotContent)
+	}
+	want := core.UploadOptions{Feed: "meetings", Title: "Weekly", Description: "notes"}
+	if gotOptions != want {
+		t.Errorf("expected options %+v, got %+v", want, gotOptions)
+	}
+	if !strings.Contains(rec.Body.String(), `"id":"item-id"`) {
+		t.Errorf("expected podcast item in response body, got %s", rec.Body.String())
+	}
+}
+
+func TestUploadHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileName  string
+		uploadErr error
+		wantCode  int
+	}{
+		{name: "missing file", fileName: "", wantCode: http.StatusBadRequest},
+		{name: "unsupported file type", fileName: "notes.txt", wantCode: http.StatusUnsupportedMediaType},
+		{name: "conversion failure", fileName: "meeting.mkv", uploadErr: fmt.Errorf("%w: ffmpeg failed", core.ErrUploadConversion), wantCode: http.StatusUnprocessableEntity},
+		{name: "storage failure", fileName: "meeting.mkv", uploadErr: errors.New("database is locked"), wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockService()
+			mock.UploadItemFunc = func(string, io.Reader, core.UploadOptions) (*database.PodcastItem, error) {
+				return nil, tt.uploadErr
+			}
+			svc := newTestAPIService(mock)
+			ctx, _ := uploadRequest(t, echo.New(), tt.fileName, nil)
+
+			err := svc.uploadHandler(ctx)
+			he, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("expected *echo.HTTPError, got %T", err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, he.Code)
+			}
+		})
+	}
+}
+
+// --- jobsHandler / jobHandler ---
+
+func TestJobsHandler_ReturnsAllJobs(t *testing.T) {
+	db := database.NewMockDatabase()
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	db.Jobs[job.ID] = job
+	svc := newTestAPIService(newMockService(withDB(db)))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/"+jobsPath, nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	if err := svc.jobsHandler(ctx); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), job.ID) {
+		t.Errorf("expected job %s in response body, got %s", job.ID, rec.Body.String())
+	}
+}
+
+func TestJobHandler_KnownID_ReturnsJob(t *testing.T) {
+	db := database.NewMockDatabase()
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	job.State = database.JobStateDownloading
+	job.Progress = 42.5
+	db.Jobs[job.ID] = job
+	svc := newTestAPIService(newMockService(withDB(db)))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/"+jobsPath+"/"+job.ID, nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("jobID")
+	ctx.SetParamValues(job.ID)
+
+	if err := svc.jobHandler(ctx); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"progress":42.5`) {
+		t.Errorf("expected progress in response body, got %s", rec.Body.String())
+	}
+}
+
+func TestJobHandler_UnknownID_Returns404(t *testing.T) {
+	svc := newTestAPIService(newMockService(withDB(database.NewMockDatabase())))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/"+jobsPath+"/unknown", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("jobID")
+	ctx.SetParamValues("unknown")
+
+	err := svc.jobHandler(ctx)
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		t.Fatalf("expected *echo.HTTPError, got %T", err)
+	}
+	if he.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", he.Code)
+	}
+}
+
+// --- itemHandler ---
+
+func itemRequest(e *echo.Echo, podcastItemID string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/"+itemsPath+"/"+podcastItemID, nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("podcastItemID")
+	ctx.SetParamValues(podcastItemID)
+	return ctx, rec
+}
+
+func TestItemHandler_KnownID_ReturnsSourceMetadata(t *testing.T) {
+	db := database.NewMockDatabase()
+	db.GetPodcastItemByIDFunc = func(id string) (*database.PodcastItem, error) {
+		return &database.PodcastItem{ID: id, Title: "Talk", SourceMetadata: json.RawMessage(`{"channel_id":"UC1"}`)}, nil
+	}
+	svc := newTestAPIService(newMockService(withDB(db)))
+
+	ctx, rec := itemRequest(echo.New(), "item-1")
+	if err := svc.itemHandler(ctx); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"source_metadata":{"channel_id":"UC1"}`) {
+		t.Errorf("expected source metadata in response body, got %s", rec.Body.String())
+	}
+}
+
+func TestItemHandler_UnknownID_Returns404(t *testing.T) {
+	db := database.NewMockDatabase()
+	db.GetPodcastItemByIDFunc = func(id string) (*database.PodcastItem, error) {
+		return nil, fmt.Errorf("podcast item with id %s not found", id)
+	}
+	svc := newTestAPIService(newMockService(withDB(db)))
+
+	ctx, _ := itemRequest(echo.New(), "unknown")
+	err := svc.itemHandler(ctx)
 	he, ok := err.(*echo.HTTPError)
 	if !ok {
 		t.Fatalf("expected *echo.HTTPError, got %T", err)
 	}
-	if he.Code != http.StatusConflict {
-		t.Errorf("expected 409 for wrapped ErrVideoLive, got %d", he.Code)
+	if he.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", he.Code)
 	}
 }
 
-func TestAddItemsHandler_GenericError_Returns400(t *testing.T) {
+// --- chaptersHandler ---
+
+func chaptersRequest(e *echo.Echo, feedTitle string, podcastItemID string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/"+FeedsPath+"/"+feedTitle+"/"+podcastItemID+"/"+core.ChaptersFileName, nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("feedTitle", "podcastItemID")
+	ctx.SetParamValues(feedTitle, podcastItemID)
+	return ctx, rec
+}
+
+func newChaptersTestService(itemChapters []chapters.Chapter) *APIService {
+	db := database.NewMockDatabase()
+	db.GetPodcastItemByIDFunc = func(id string) (*database.PodcastItem, error) {
+		return &database.PodcastItem{ID: id, AudioFilePath: "/media/talks/talk.mp3", Chapters: itemChapters}, nil
+	}
+	svc := newMockService(withDB(db))
+	svc.GetFeedDirectoryFunc = func(audioFilePath string) (string, error) { return "talks", nil }
+	return newTestAPIService(svc)
+}
+
+func TestChaptersHandler_ItemWithChapters_ReturnsDocument(t *testing.T) {
+	svc := newChaptersTestService([]chapters.Chapter{{StartSeconds: 0, Title: "Intro"}, {StartSeconds: 60, Title: "Talk"}})
+
+	ctx, rec := chaptersRequest(echo.New(), "talks", "item-1")
+	if err := svc.chaptersHandler(ctx); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get(echo.HeaderContentType); got != chapters.MIMEType {
+		t.Errorf("expected content type %s, got %s", chapters.MIMEType, got)
+	}
+	want := `{"version":"1.2.0","chapters":[{"startTime":0,"title":"Intro"},{"startTime":60,"title":"Talk"}]}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("expected body %s, got %s", want, got)
+	}
+}
+
+func TestChaptersHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		chapters  []chapters.Chapter
+		feedTitle string
+		wantCode  int
+	}{
+		{name: "item without chapters", feedTitle: "talks", wantCode: http.StatusNotFound},
+		{name: "item of another feed", chapters: []chapters.Chapter{{Title: "Intro"}}, feedTitle: "music", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newChaptersTestService(tt.chapters)
+
+			ctx, _ := chaptersRequest(echo.New(), tt.feedTitle, "item-1")
+			err := svc.chaptersHandler(ctx)
+			he, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("expected *echo.HTTPError, got %T", err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, he.Code)
+			}
+		})
+	}
+}
+
+func TestChaptersHandler_ItemLookupErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "unknown item", err: fmt.Errorf("podcast item with id item-1: %w", database.ErrPodcastItemNotFound), wantCode: http.StatusNotFound},
+		{name: "database error", err: errors.New("database is locked"), wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewMockDatabase()
+			db.GetPodcastItemByIDFunc = func(id string) (*database.PodcastItem, error) { return nil, tt.err }
+			svc := newTestAPIService(newMockService(withDB(db)))
+
+			ctx, _ := chaptersRequest(echo.New(), "talks", "item-1")
+			err := svc.chaptersHandler(ctx)
+			he, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("expected *echo.HTTPError, got %T", err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, he.Code)
+			}
+		})
+	}
+}
+
+// --- cancelDownloadHandler ---
+
+func cancelDownloadRequest(e *echo.Echo, downloadID string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodDelete, "/"+downloadsPath+"/"+downloadID, nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("downloadID")
+	ctx.SetParamValues(downloadID)
+	return ctx, rec
+}
+
+func TestCancelDownloadHandler_KnownID_Returns200(t *testing.T) {
+	mock := newMockService()
+	mock.CancelDownloadFunc = func(downloadID string) ([]*database.DownloadJob, error) {
+		job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+		job.DownloadID = downloadID
+		job.State = database.JobStateCancelled
+		return []*database.DownloadJob{job}, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, rec := cancelDownloadRequest(echo.New(), "download-1")
+
+	if err := svc.cancelDownloadHandler(ctx); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"state":"cancelled"`) {
+		t.Errorf("expected cancelled job in response body, got %s", rec.Body.String())
+	}
+}
+
+func TestCancelDownloadHandler_UnknownID_Returns404(t *testing.T) {
+	mock := newMockService()
+	mock.CancelDownloadFunc = func(_ string) ([]*database.DownloadJob, error) {
+		return nil, core.ErrDownloadNotFound
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := cancelDownloadRequest(echo.New(), "unknown")
+
+	err := svc.cancelDownloadHandler(ctx)
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		t.Fatalf("expected *echo.HTTPError, got %T", err)
+	}
+	if he.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", he.Code)
+	}
+}
+
+// --- subscriptions ---
+
+func addSubscriptionRequest(e *echo.Echo, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/"+subscriptionsPath, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestAddSubscriptionHandler_Success_Returns201(t *testing.T) {
 	e := echo.New()
 	e.Validator = newRequestValidator()
+	svc := newTestAPIService(newMockService())
+	ctx, rec := addSubscriptionRequest(e, `{"url":"https://www.youtube.com/playlist?list=abc"}`)
+
+	if err := svc.addSubscriptionHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"url":"https://www.youtube.com/playlist?list=abc"`) {
+		t.Errorf("expected subscription in response body, got %s", rec.Body.String())
+	}
+}
+
+func TestAddSubscriptionHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{name: "missing url", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "already subscribed", body: `{"url":"https://www.youtube.com/playlist?list=abc"}`, err: core.ErrSubscriptionExists, wantCode: http.StatusConflict},
+		{name: "unsupported url", body: `{"url":"https://example.com"}`, err: core.ErrSubscriptionNotSupported, wantCode: http.StatusBadRequest},
+		{name: "single video", body: `{"url":"https://www.youtube.com/watch?v=abc"}`, err: core.ErrSubscriptionNotAList, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = newRequestValidator()
+			mock := newMockService()
+			mock.AddSubscriptionFunc = func(_ string, _ int) (*database.Subscription, error) {
+				return nil, tt.err
+			}
+			svc := newTestAPIService(mock)
+			ctx, _ := addSubscriptionRequest(e, tt.body)
+
+			err := svc.addSubscriptionHandler(ctx)
+			he, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("expected *echo.HTTPError, got %T", err)
+			}
+			if he.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, he.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteSubscriptionHandler_UnknownID_Returns404(t *testing.T) {
 	mock := newMockService()
-	mock.DownloadItemsHandlerFunc = func(_ string) error {
-		return errors.New("unsupported url")
+	mock.DeleteSubscriptionFunc = func(_ string) error {
+		return core.ErrSubscriptionNotFound
 	}
 	svc := newTestAPIService(mock)
-	ctx, _ := addItemsRequest(e, `{"urls":["https://unsupported.example.com/video"]}`)
+	req := httptest.NewRequest(http.MethodDelete, "/"+subscriptionsPath+"/unknown", nil)
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	ctx.SetParamNames("subscriptionID")
+	ctx.SetParamValues("unknown")
 
-	err := svc.addItemsHandler(ctx)
-	if err == nil {
-		t.Fatal("expected error, got nil")
+	err := svc.deleteSubscriptionHandler(ctx)
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		t.Fatalf("expected *echo.HTTPError, got %T", err)
 	}
+	if he.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", he.Code)
+	}
+}
+
+// --- previewHandler ---
+
+func previewRequest(e *echo.Echo, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/"+previewPath, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestPreviewHandler_ReturnsEntries(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	mock.PreviewItemsFunc = func(url string, _ downloader.Selection) ([]*core.PreviewEntry, error) {
+		entry := &core.PreviewEntry{InLibrary: true}
+		entry.URL = url
+		entry.Title = "A Talk"
+		return []*core.PreviewEntry{entry}, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, rec := previewRequest(e, `{"url":"https://www.youtube.com/watch?v=abc"}`)
+
+	if err := svc.previewHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `"title":"A Talk"`) || !strings.Contains(body, `"in_library":true`) {
+		t.Errorf("expected preview entry in response body, got %s", body)
+	}
+}
+
+func TestPreviewHandler_Selection_IsPassedToCore(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	var received downloader.Selection
+	mock.PreviewItemsFunc = func(_ string, selection downloader.Selection) ([]*core.PreviewEntry, error) {
+		received = selection
+		return []*core.PreviewEntry{}, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := previewRequest(e, `{"url":"https://www.youtube.com/playlist?list=abc","items":"1-10","latest":5,"uploaded_after":"2024-03-01"}`)
+
+	if err := svc.previewHandler(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := downloader.Selection{Items: "1-10", Latest: 5, UploadedAfter: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	if received != want {
+		t.Errorf("expected selection %+v, got %+v", want, received)
+	}
+}
+
+func TestPreviewHandler_InvalidSelection_Returns400(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	mock.PreviewItemsFunc = func(_ string, _ downloader.Selection) ([]*core.PreviewEntry, error) {
+		t.Error("expected the core service not to be called")
+		return nil, nil
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := previewRequest(e, `{"url":"https://www.youtube.com/playlist?list=abc","latest":-1}`)
+
+	err := svc.previewHandler(ctx)
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		t.Fatalf("expected *echo.HTTPError, got %T", err)
+	}
+	if he.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", he.Code)
+	}
+}
+
+func TestPreviewHandler_UnsupportedURL_Returns400(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	mock.PreviewItemsFunc = func(url string, _ downloader.Selection) ([]*core.PreviewEntry, error) {
+		return nil, fmt.Errorf("%w: %s", core.ErrURLNotSupported, url)
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := previewRequest(e, `{"url":"https://example.com"}`)
+
+	err := svc.previewHandler(ctx)
 	he, ok := err.(*echo.HTTPError)
 	if !ok {
 		t.Fatalf("expected *echo.HTTPError, got %T", err)
@@ -256,3 +941,26 @@ func TestAddItemsHandler_GenericError_Returns400(t *testing.T) {
 		t.Errorf("expected 400, got %d", he.Code)
 	}
 }
+
+func TestPreviewHandler_ListingFailure_Returns502WithCause(t *testing.T) {
+	e := echo.New()
+	e.Validator = newRequestValidator()
+	mock := newMockService()
+	mock.PreviewItemsFunc = func(url string, _ downloader.Selection) ([]*core.PreviewEntry, error) {
+		return nil, fmt.Errorf("failed to list urls for %s: %w", url, errors.New("HTTP Error 429"))
+	}
+	svc := newTestAPIService(mock)
+	ctx, _ := previewRequest(e, `{"url":"https://www.youtube.com/playlist?list=abc"}`)
+
+	err := svc.previewHandler(ctx)
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		t.Fatalf("expected *echo.HTTPError, got %T", err)
+	}
+	if he.Code != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", he.Code)
+	}
+	if message, _ := he.Message.(string); !strings.Contains(message, "HTTP Error 429") {
+		t.Errorf("expected the cause in the message, got %v", he.Message)
+	}
+}
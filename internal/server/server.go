@@ -1,10 +1,15 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/jo-hoe/video-to-podcast-service/internal/config"
@@ -20,6 +25,9 @@ import (
 
 var defaultResourcePath string
 
+// shutdownTimeout bounds how long running requests may take to finish once the service is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
 func StartServer(databaseService database.DatabaseService, cfg *config.Config) {
 	defaultResourcePath = cfg.Persistence.Media.MediaPath
 
@@ -55,7 +63,15 @@ func StartServer(databaseService database.DatabaseService, cfg *config.Config) {
 
 	e.Validator = &genericValidator{Validator: validator.New()}
 
-	coreService := core.NewCoreService(databaseService, defaultResourcePath, &cfg.Persistence.Cookies, &cfg.Persistence.Media, &cfg.YtDlp)
+	// stop background work and the server on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	coreService := core.NewCoreService(databaseService, defaultResourcePath, &cfg.Persistence.Cookies, &cfg.Persistence.Media, &cfg.YtDlp, &cfg.Webhooks, &cfg.Filters, &cfg.Downloaders)
+	// Resume downloads accepted before the last shutdown and process new ones in the background
+	coreService.StartDownloadQueue(ctx)
+	// Queue new videos of subscribed channels and playlists
+	coreService.StartSubscriptionPoller(ctx, cfg.Subscriptions.PollInterval)
 
 	defaultPortStr := strconv.Itoa(cfg.Port)
 	apiService := api.NewAPIService(coreService, defaultPortStr)
@@ -69,7 +85,20 @@ func StartServer(databaseService database.DatabaseService, cfg *config.Config) {
 	slog.Info("starting server")
 	slog.Info(fmt.Sprintf("UI available at http://localhost:%s/%s", port, ui.MainPageName))
 	slog.Info(fmt.Sprintf("Explore all feeds via API at http://localhost:%s/%s ", port, api.FeedsPath))
-	e.Logger.Fatal(e.Start(fmt.Sprintf(":%s", port)))
+	go func() {
+		if err := e.Start(fmt.Sprintf(":%s", port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	slog.Info("shutting down server")
+	coreService.Close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shut down server", "err", err)
+	}
 }
 
 type genericValidator struct {
@@ -2,8 +2,8 @@ package ui
 
 import (
 	"embed"
+	"html/template"
 	"io"
-	"text/template"
 
 	"github.com/labstack/echo/v4"
 )
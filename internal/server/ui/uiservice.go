@@ -1,18 +1,23 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"html"
+	"html/template"
 	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"sort"
-	"text/template"
 
 	"github.com/jo-hoe/video-to-podcast-service/internal/core"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
-	"github.com/jo-hoe/video-to-podcast-service/internal/server/requestutil"
+	"github.com/jo-hoe/video-to-podcast-service/internal/core/download/downloader"
 	"github.com/jo-hoe/video-to-podcast-service/internal/server/api"
+	"github.com/jo-hoe/video-to-podcast-service/internal/server/requestutil"
 	"github.com/labstack/echo/v4"
 )
 
@@ -23,8 +28,22 @@ type UIService struct {
 }
 
 type PodcastItemList struct {
-	PodcastItems []*database.PodcastItem
-	BaseURL      *url.URL
+	PodcastItems    []*database.PodcastItem
+	ActiveDownloads []*ActiveDownload
+	Subscriptions   []*database.Subscription
+	BaseURL         *url.URL
+}
+
+// Preview lists the videos of a submitted URL so that entries can be deselected before downloading.
+type Preview struct {
+	URL     string
+	Entries []*core.PreviewEntry
+}
+
+// ActiveDownload groups the unfinished jobs of one submitted URL.
+type ActiveDownload struct {
+	ID   string
+	Jobs []*database.DownloadJob
 }
 
 func NewUIService(coreservice *core.CoreService) *UIService {
@@ -37,6 +56,7 @@ func (service *UIService) SetUIRoutes(e *echo.Echo) {
 	// Create template with helper functions
 	funcMap := template.FuncMap{
 		"formatDuration":       formatDuration,
+		"formatSeconds":        formatSeconds,
 		"getFeedLink":          service.getFeedLink,
 		"getFeedTitleFromPath": getFeedTitleFromPath,
 	}
@@ -48,7 +68,13 @@ func (service *UIService) SetUIRoutes(e *echo.Echo) {
 	e.GET("/", service.rootRedirectHandler) // Redirect root to index.html
 	e.GET(MainPageName, service.indexHandler)
 	e.POST("/htmx/addItem", service.htmxAddItemHandler)
+	e.POST("/htmx/preview", service.htmxPreviewHandler)
+	e.POST("/htmx/uploads", service.htmxUploadHandler)
 	e.GET("/htmx/items", service.htmxItemsHandler)
+	e.GET("/htmx/downloads", service.htmxDownloadsHandler)
+	e.DELETE("/htmx/downloads/:downloadID", service.htmxCancelDownloadHandler)
+	e.POST("/htmx/subscriptions", service.htmxAddSubscriptionHandler)
+	e.DELETE("/htmx/subscriptions/:subscriptionID", service.htmxDeleteSubscriptionHandler)
 	e.GET("/icon.svg", service.iconHandler)
 }
 
@@ -75,11 +101,41 @@ func (service *UIService) buildItemList(ctx echo.Context) (*PodcastItemList, err
 		podcastItems = podcastItems[:128]
 	}
 	return &PodcastItemList{
-		PodcastItems: podcastItems,
-		BaseURL:      requestutil.BaseURL(ctx),
+		PodcastItems:    podcastItems,
+		ActiveDownloads: service.buildActiveDownloads(),
+		Subscriptions:   service.buildSubscriptions(),
+		BaseURL:         requestutil.BaseURL(ctx),
 	}, nil
 }
 
+// buildActiveDownloads groups all unfinished jobs by their download, oldest first.
+func (service *UIService) buildActiveDownloads() []*ActiveDownload {
+	jobs, err := service.coreservice.GetDatabaseService().GetDownloadJobsByState(database.UnfinishedJobStates...)
+	if err != nil {
+		return []*ActiveDownload{}
+	}
+	downloads := make([]*ActiveDownload, 0)
+	downloadsByID := make(map[string]*ActiveDownload)
+	for _, job := range jobs {
+		activeDownload, ok := downloadsByID[job.DownloadID]
+		if !ok {
+			activeDownload = &ActiveDownload{ID: job.DownloadID}
+			downloadsByID[job.DownloadID] = activeDownload
+			downloads = append(downloads, activeDownload)
+		}
+		activeDownload.Jobs = append(activeDownload.Jobs, job)
+	}
+	return downloads
+}
+
+func (service *UIService) buildSubscriptions() []*database.Subscription {
+	subscriptions, err := service.coreservice.GetSubscriptions()
+	if err != nil {
+		return []*database.Subscription{}
+	}
+	return subscriptions
+}
+
 func (service *UIService) indexHandler(ctx echo.Context) (err error) {
 	data, err := service.buildItemList(ctx)
 	if err != nil {
@@ -100,16 +156,133 @@ func (service *UIService) htmxItemsHandler(ctx echo.Context) error {
 // New handler for HTMX single URL form
 func (service *UIService) htmxAddItemHandler(ctx echo.Context) error {
 	type SingleUrl struct {
-		URL string `json:"url" form:"url" validate:"required"`
+		URL     string   `json:"url" form:"url" validate:"required"`
+		Force   bool     `json:"force" form:"force"`
+		Entries []string `json:"entries" form:"entries"` // entries selected in the preview
+		Preview bool     `json:"preview" form:"preview"` // set if the form was submitted from the preview
 	}
 	var req SingleUrl
 	if err := ctx.Bind(&req); err != nil || req.URL == "" {
 		return ctx.HTML(http.StatusBadRequest, "<span style='color:red'>Invalid or missing URL.</span>")
 	}
-	if err := service.coreservice.DownloadItemsHandler(req.URL); err != nil {
-		return ctx.HTML(http.StatusUnprocessableEntity, "<span style='color:red'>Could not process URL: "+err.Error()+"</span>")
+	if req.Preview && len(req.Entries) == 0 {
+		return ctx.HTML(http.StatusBadRequest, "<span style='color:red'>No entries selected.</span>")
 	}
-	return ctx.HTML(http.StatusOK, "<span style='color:green'>Submitted successfully!</span>")
+	jobs, err := service.coreservice.DownloadItemsHandler(ctx.Request().Context(), req.URL, core.DownloadOptions{Force: req.Force, Entries: req.Entries})
+	if err != nil {
+		return ctx.HTML(http.StatusUnprocessableEntity, "<span style='color:red'>Could not process URL: "+html.EscapeString(err.Error())+"</span>")
+	}
+	alreadyPresent, filtered := 0, 0
+	for _, job := range jobs {
+		switch job.State {
+		case database.JobStateAlreadyPresent:
+			alreadyPresent++
+		case database.JobStateFiltered:
+			filtered++
+		}
+	}
+	if alreadyPresent == len(jobs) {
+		return ctx.HTML(http.StatusOK, "<span style='color:orange'>Already present in the library. Tick 'Force re-download' to download again.</span>")
+	}
+	if filtered > 0 && alreadyPresent+filtered == len(jobs) {
+		return ctx.HTML(http.StatusOK, fmt.Sprintf("<span style='color:orange'>Nothing to download: %d video(s) skipped by the content filters, %d already present.</span>", filtered, alreadyPresent))
+	}
+	return ctx.HTML(http.StatusOK, fmt.Sprintf("<span style='color:green'>Submitted successfully! %d download(s) queued, %d already present, %d filtered.</span>", len(jobs)-alreadyPresent-filtered, alreadyPresent, filtered))
+}
+
+// htmxPreviewHandler renders the videos of the submitted URL with a checkbox per entry.
+func (service *UIService) htmxPreviewHandler(ctx echo.Context) error {
+	type PreviewURL struct {
+		URL string `json:"url" form:"url" validate:"required"`
+	}
+	var req PreviewURL
+	if err := ctx.Bind(&req); err != nil || req.URL == "" {
+		return ctx.HTML(http.StatusBadRequest, "<span style='color:red'>Invalid or missing URL.</span>")
+	}
+	entries, err := service.coreservice.PreviewItems(ctx.Request().Context(), req.URL, downloader.Selection{})
+	if err != nil {
+		return ctx.HTML(http.StatusUnprocessableEntity, "<span style='color:red'>Could not preview URL: "+html.EscapeString(err.Error())+"</span>")
+	}
+	return ctx.Render(http.StatusOK, "preview", &Preview{URL: req.URL, Entries: entries})
+}
+
+// htmxUploadHandler adds the files dropped onto the upload zone to the selected feed.
+func (service *UIService) htmxUploadHandler(ctx echo.Context) error {
+	form, err := ctx.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		return ctx.HTML(http.StatusBadRequest, "<span style='color:red'>No file selected.</span>")
+	}
+	options := core.UploadOptions{Feed: ctx.FormValue("feed")}
+	for _, fileHeader := range form.File["file"] {
+		if !core.IsUploadSupported(fileHeader.Filename) {
+			return ctx.HTML(http.StatusUnsupportedMediaType, "<span style='color:red'>Unsupported file type: "+html.EscapeString(fileHeader.Filename)+"</span>")
+		}
+	}
+	for _, fileHeader := range form.File["file"] {
+		if err := service.uploadFile(fileHeader, options); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, core.ErrUploadConversion) {
+				code = http.StatusUnprocessableEntity
+			}
+			return ctx.HTML(code, "<span style='color:red'>Could not add "+html.EscapeString(fileHeader.Filename)+": "+html.EscapeString(err.Error())+"</span>")
+		}
+	}
+	return ctx.HTML(http.StatusOK, fmt.Sprintf("<span style='color:green'>Added %d file(s).</span>", len(form.File["file"])))
+}
+
+func (service *UIService) uploadFile(fileHeader *multipart.FileHeader, options core.UploadOptions) error {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("error closing uploaded file", "err", err)
+		}
+	}()
+	_, err = service.coreservice.UploadItem(fileHeader.Filename, file, options)
+	return err
+}
+
+// htmxDownloadsHandler renders only the active downloads fragment for polling-based auto-refresh.
+func (service *UIService) htmxDownloadsHandler(ctx echo.Context) error {
+	return ctx.Render(http.StatusOK, "downloads", service.buildActiveDownloads())
+}
+
+// htmxCancelDownloadHandler cancels all jobs of a download and renders the remaining active downloads.
+func (service *UIService) htmxCancelDownloadHandler(ctx echo.Context) error {
+	downloadID := ctx.Param("downloadID")
+	if _, err := service.coreservice.CancelDownload(downloadID); err != nil {
+		return ctx.HTML(http.StatusNotFound, "<span style='color:red'>Could not cancel download: "+html.EscapeString(err.Error())+"</span>")
+	}
+	return ctx.Render(http.StatusOK, "downloads", service.buildActiveDownloads())
+}
+
+// htmxAddSubscriptionHandler subscribes to a channel or playlist and renders the updated subscription list.
+func (service *UIService) htmxAddSubscriptionHandler(ctx echo.Context) error {
+	type SubscriptionURL struct {
+		URL string `json:"url" form:"url" validate:"required"`
+	}
+	var req SubscriptionURL
+	if err := ctx.Bind(&req); err != nil || req.URL == "" {
+		return ctx.HTML(http.StatusBadRequest, "<span style='color:red'>Invalid or missing URL.</span>")
+	}
+	if _, err := service.coreservice.AddSubscription(req.URL, 0); err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, core.ErrSubscriptionNotSupported) || errors.Is(err, core.ErrSubscriptionNotAList) {
+			status = http.StatusBadRequest
+		}
+		return ctx.HTML(status, "<span style='color:red'>Could not subscribe: "+html.EscapeString(err.Error())+"</span>")
+	}
+	return ctx.Render(http.StatusOK, "subscriptions", service.buildSubscriptions())
+}
+
+// htmxDeleteSubscriptionHandler removes a subscription and renders the remaining subscriptions.
+func (service *UIService) htmxDeleteSubscriptionHandler(ctx echo.Context) error {
+	if err := service.coreservice.DeleteSubscription(ctx.Param("subscriptionID")); err != nil {
+		return ctx.HTML(http.StatusNotFound, "<span style='color:red'>Could not unsubscribe: "+html.EscapeString(err.Error())+"</span>")
+	}
+	return ctx.Render(http.StatusOK, "subscriptions", service.buildSubscriptions())
 }
 
 // Icon handler to serve the embedded favicon
@@ -142,6 +315,11 @@ func formatDuration(milliseconds int64) string {
 	return fmt.Sprintf("%d:%02d", minutes, seconds)
 }
 
+// Helper function to format a duration in seconds as reported by yt-dlp
+func formatSeconds(seconds float64) string {
+	return formatDuration(int64(seconds * 1000))
+}
+
 // Helper function to generate feed link for a podcast item
 func (service *UIService) getFeedLink(baseURL *url.URL, filePath string) string {
 	return service.coreservice.GetLinkToFeed(baseURL, api.FeedsPath, filePath)
@@ -1,10 +1,14 @@
 package ui
 
 import (
+	"bytes"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/jo-hoe/video-to-podcast-service/internal/config"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core"
 	"github.com/jo-hoe/video-to-podcast-service/internal/core/database"
 	"github.com/labstack/echo/v4"
@@ -14,7 +18,7 @@ import (
 func TestRootRedirectHandler_NoError(t *testing.T) {
 	e := echo.New()
 	mockDB := database.NewMockDatabase()
-	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
 	uiService := NewUIService(coreService)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -28,7 +32,7 @@ func TestRootRedirectHandler_NoError(t *testing.T) {
 func TestRootRedirectHandler_StatusMovedPermanently(t *testing.T) {
 	e := echo.New()
 	mockDB := database.NewMockDatabase()
-	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
 	uiService := NewUIService(coreService)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -42,7 +46,7 @@ func TestRootRedirectHandler_StatusMovedPermanently(t *testing.T) {
 func TestRootRedirectHandler_LocationHeaderIndex(t *testing.T) {
 	e := echo.New()
 	mockDB := database.NewMockDatabase()
-	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
 	uiService := NewUIService(coreService)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -56,7 +60,7 @@ func TestRootRedirectHandler_LocationHeaderIndex(t *testing.T) {
 func TestRootRedirectIntegration_StatusMovedPermanently(t *testing.T) {
 	e := echo.New()
 	mockDB := database.NewMockDatabase()
-	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
 
 	uiService := NewUIService(coreService)
 	uiService.SetUIRoutes(e)
@@ -72,7 +76,7 @@ func TestRootRedirectIntegration_StatusMovedPermanently(t *testing.T) {
 func TestRootRedirectIntegration_LocationHeaderIndex(t *testing.T) {
 	e := echo.New()
 	mockDB := database.NewMockDatabase()
-	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
 
 	uiService := NewUIService(coreService)
 	uiService.SetUIRoutes(e)
@@ -84,3 +88,219 @@ func TestRootRedirectIntegration_LocationHeaderIndex(t *testing.T) {
 
 	assert.Equal(t, "/index.html", rec.Header().Get("Location"))
 }
+
+func TestDownloadsIntegration_CancelRemovesActiveDownload(t *testing.T) {
+	e := echo.New()
+	mockDB := database.NewMockDatabase()
+	job := database.NewDownloadJob("https://www.youtube.com/watch?v=abc")
+	job.Progress = 12.5
+	_ = mockDB.InsertDownloadJob(job)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/htmx/downloads", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "download-"+job.DownloadID)
+
+	req = httptest.NewRequest(http.MethodDelete, "/htmx/downloads/"+job.DownloadID, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "download-"+job.DownloadID)
+	assert.Equal(t, database.JobStateCancelled, job.State)
+}
+
+func TestSubscriptionsIntegration_SubscribeAndUnsubscribe(t *testing.T) {
+	e := echo.New()
+	mockDB := database.NewMockDatabase()
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, &config.Media{}, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	req := httptest.NewRequest(http.MethodPost, "/htmx/subscriptions", strings.NewReader(`{"url":"https://www.youtube.com/playlist?list=abc"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, mockDB.Subscriptions, 1)
+	var subscriptionID string
+	for id := range mockDB.Subscriptions {
+		subscriptionID = id
+	}
+	assert.Contains(t, rec.Body.String(), "subscription-"+subscriptionID)
+
+	req = httptest.NewRequest(http.MethodDelete, "/htmx/subscriptions/"+subscriptionID, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "subscription-"+subscriptionID)
+	assert.Empty(t, mockDB.Subscriptions)
+}
+
+func TestPreviewTemplate_PreselectsNewEntriesOnly(t *testing.T) {
+	e := echo.New()
+	uiService := NewUIService(core.NewCoreService(database.NewMockDatabase(), "/tmp/test", nil, nil, nil, nil, nil, nil))
+	uiService.SetUIRoutes(e)
+
+	newEntry := &core.PreviewEntry{}
+	newEntry.URL = "https://www.youtube.com/watch?v=new"
+	newEntry.Title = "New Talk"
+	newEntry.DurationSeconds = 90
+	presentEntry := &core.PreviewEntry{InLibrary: true}
+	presentEntry.URL = "https://www.youtube.com/watch?v=old"
+	preview := &Preview{URL: "https://www.youtube.com/playlist?list=abc", Entries: []*core.PreviewEntry{newEntry, presentEntry}}
+
+	var body strings.Builder
+	err := e.Renderer.Render(&body, "preview", preview, e.NewContext(httptest.NewRequest(http.MethodPost, "/htmx/preview", nil), httptest.NewRecorder()))
+
+	assert.NoError(t, err)
+	assert.Contains(t, body.String(), `value="https://www.youtube.com/watch?v=new" checked`)
+	assert.NotContains(t, body.String(), `value="https://www.youtube.com/watch?v=old" checked`)
+	assert.Contains(t, body.String(), "1:30")
+}
+
+func TestUploadsIntegration_RejectsUnsupportedFiles(t *testing.T) {
+	e := echo.New()
+	audioDirectory := t.TempDir()
+	coreService := core.NewCoreService(database.NewMockDatabase(), audioDirectory, nil, &config.Media{TempPath: t.TempDir()}, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	assert.NoError(t, err)
+	_, err = part.Write([]byte("text"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/htmx/uploads", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.Contains(t, rec.Body.String(), "notes.txt")
+}
+
+func TestItemsIntegration_EscapesSubmittedURLInErrors(t *testing.T) {
+	e := echo.New()
+	mockDB := database.NewMockDatabase()
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, &config.Media{}, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	for _, path := range []string{"/htmx/addItem", "/htmx/preview"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"url":"https://example.invalid/<script>alert(1)</script>"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, path)
+		assert.NotContains(t, rec.Body.String(), "<script>", path)
+		assert.Contains(t, rec.Body.String(), "&lt;script&gt;", path)
+	}
+}
+
+func TestSubscriptionsIntegration_EscapesErrors(t *testing.T) {
+	e := echo.New()
+	mockDB := database.NewMockDatabase()
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, &config.Media{}, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	req := httptest.NewRequest(http.MethodDelete, "/htmx/subscriptions/%3Cscript%3E", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "<script>")
+	assert.Contains(t, rec.Body.String(), "&lt;script&gt;")
+}
+
+func TestSubscriptionsTemplate_EscapesStoredFields(t *testing.T) {
+	e := echo.New()
+	uiService := NewUIService(core.NewCoreService(database.NewMockDatabase(), "/tmp/test", nil, nil, nil, nil, nil, nil))
+	uiService.SetUIRoutes(e)
+
+	subscription := database.NewSubscription(`https://www.youtube.com/playlist?list=PL"><script>alert(1)</script>`, 0)
+	subscription.LastError = "<img src=x onerror=alert(1)>"
+
+	var body strings.Builder
+	err := e.Renderer.Render(&body, "subscriptions", []*database.Subscription{subscription}, e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder()))
+
+	assert.NoError(t, err)
+	assert.NotContains(t, body.String(), "<script>")
+	assert.NotContains(t, body.String(), "<img src=x")
+	assert.Contains(t, body.String(), "&lt;script&gt;")
+}
+
+func TestIndexIntegration_RendersPage(t *testing.T) {
+	e := echo.New()
+	mockDB := database.NewMockDatabase()
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/"+MainPageName, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "renderUpdatedTimes")
+}
+
+func TestPreviewTemplate_EscapesVideoMetadata(t *testing.T) {
+	e := echo.New()
+	uiService := NewUIService(core.NewCoreService(database.NewMockDatabase(), "/tmp/test", nil, nil, nil, nil, nil, nil))
+	uiService.SetUIRoutes(e)
+
+	entry := &core.PreviewEntry{Error: "<b>probe failed</b>"}
+	entry.URL = "https://www.youtube.com/watch?v=abc"
+	entry.Title = "<img src=x onerror=alert(1)>"
+	entry.Channel = "<script>alert(1)</script>"
+	entry.Thumbnail = "javascript:alert(1)"
+	preview := &Preview{URL: `https://www.youtube.com/watch?v=abc"><script>alert(1)</script>`, Entries: []*core.PreviewEntry{entry}}
+
+	var body strings.Builder
+	err := e.Renderer.Render(&body, "preview", preview, e.NewContext(httptest.NewRequest(http.MethodPost, "/htmx/preview", nil), httptest.NewRecorder()))
+
+	assert.NoError(t, err)
+	assert.NotContains(t, body.String(), "<img src=x")
+	assert.NotContains(t, body.String(), "<script>")
+	assert.NotContains(t, body.String(), "<b>")
+	assert.NotContains(t, body.String(), `src="javascript:`)
+	assert.Contains(t, body.String(), "&lt;img src=x onerror=alert(1)&gt;")
+}
+
+func TestDownloadsIntegration_EscapesJobURL(t *testing.T) {
+	e := echo.New()
+	mockDB := database.NewMockDatabase()
+	job := database.NewDownloadJob(`https://www.youtube.com/watch?v=abc"><script>alert(1)</script>`)
+	_ = mockDB.InsertDownloadJob(job)
+	coreService := core.NewCoreService(mockDB, "/tmp/test", nil, nil, nil, nil, nil, nil)
+
+	uiService := NewUIService(coreService)
+	uiService.SetUIRoutes(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/htmx/downloads", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "<script>")
+	assert.Contains(t, rec.Body.String(), "&lt;script&gt;")
+}